@@ -0,0 +1,318 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AltService 是 Alt-Svc 响应头通告的一个替代服务 (RFC 7838)
+type AltService struct {
+	Protocol string    // ALPN 协议标识, 例如 "h2", "h3"
+	Host     string    // 替代主机, 为空表示与源主机相同
+	Port     int       // 替代端口
+	Expires  time.Time // 由 ma 参数计算的过期时间, 未提供时为 24 小时后
+	Persist  bool      // persist=1, 网络变化后仍然有效
+}
+
+// Addr 返回替代服务的 "host:port", 主机为空时使用 originHost
+func (s AltService) Addr(originHost string) string {
+	host := s.Host
+	if host == "" {
+		host = originHost
+	}
+	return net.JoinHostPort(host, strconv.Itoa(s.Port))
+}
+
+// AltSvcOptions Alt-Svc 处理配置
+type AltSvcOptions struct {
+	// Route 为 true 时将后续请求路由到通告的替代端点, 连接失败时自动回退到源站; 为 false 时只记录
+	Route bool
+	// Protocols 可路由的协议, 默认 h2 与 http/1.1. h3 需要 QUIC, 标准库 Transport 不支持, 只记录不路由
+	Protocols []string
+	// FailureTTL 替代端点连接失败后停用的时长, 默认 5 分钟
+	FailureTTL time.Duration
+}
+
+const (
+	defaultAltSvcMaxAge     = 24 * time.Hour
+	defaultAltSvcFailureTTL = 5 * time.Minute
+	maxAltSvcOrigins        = 1024 // 缓存的源站数量上限
+)
+
+// WithAltSvc 启用 Alt-Svc 处理: 按源站缓存 HTTPS 响应通告的替代端点及其有效期,
+// 并可选地将后续请求发往替代端点. 路由时 Host 头, TLS SNI 与证书校验仍使用源站主机;
+// 配置了 HTTP 代理或请求已设置 ConnectTo 时不路由
+func WithAltSvc(opts AltSvcOptions) Option {
+	return func(c *Client) {
+		if len(opts.Protocols) == 0 {
+			opts.Protocols = []string{"h2", "http/1.1"}
+		}
+		if opts.FailureTTL <= 0 {
+			opts.FailureTTL = defaultAltSvcFailureTTL
+		}
+		c.altSvc = &altSvcCache{
+			opts:    opts,
+			clock:   func() time.Time { return c.clock.Now() },
+			origins: make(map[string][]AltService),
+			broken:  make(map[string]time.Time),
+		}
+	}
+}
+
+// AltServices 返回 rawURL 所属源站当前有效的替代服务, 未启用 WithAltSvc 或没有记录时返回 nil
+func (c *Client) AltServices(rawURL string) []AltService {
+	if c.altSvc == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	return c.altSvc.get(altSvcOrigin(u))
+}
+
+// ParseAltSvc 解析 header 中的 Alt-Svc 值, now 用于计算过期时间.
+// clear 为 true 表示源站要求清除全部替代服务; 格式错误的项被跳过
+func ParseAltSvc(header http.Header, now time.Time) (services []AltService, clear bool) {
+	for _, value := range header.Values("Alt-Svc") {
+		if strings.TrimSpace(value) == "clear" {
+			return nil, true
+		}
+		for _, entry := range splitQuoted(value, ',') {
+			if s, ok := parseAltSvcEntry(entry, now); ok {
+				services = append(services, s)
+			}
+		}
+	}
+	return services, false
+}
+
+// parseAltSvcEntry 解析 `h2="alt.example.com:443"; ma=3600; persist=1` 形式的一项
+func parseAltSvcEntry(entry string, now time.Time) (AltService, bool) {
+	params := splitQuoted(entry, ';')
+	proto, authority, ok := strings.Cut(params[0], "=")
+	if !ok {
+		return AltService{}, false
+	}
+	proto, err := url.PathUnescape(strings.TrimSpace(proto)) // 协议标识按百分号编码, 例如 http%2F1.1
+	if err != nil || proto == "" {
+		return AltService{}, false
+	}
+	host, portStr, err := net.SplitHostPort(unquoteHeaderValue(strings.TrimSpace(authority)))
+	if err != nil {
+		return AltService{}, false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return AltService{}, false
+	}
+
+	s := AltService{Protocol: proto, Host: host, Port: port, Expires: now.Add(defaultAltSvcMaxAge)}
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		value = unquoteHeaderValue(strings.TrimSpace(value))
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "ma":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil && secs >= 0 {
+				s.Expires = now.Add(time.Duration(secs) * time.Second)
+			}
+		case "persist":
+			s.Persist = value == "1"
+		}
+	}
+	return s, true
+}
+
+// altSvcOrigin 返回 URL 的源站键 "scheme://host:port"
+func altSvcOrigin(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return u.Scheme + "://" + net.JoinHostPort(strings.ToLower(u.Hostname()), port)
+}
+
+// altSvcCache 按源站缓存替代服务, 并记录连接失败的替代端点
+type altSvcCache struct {
+	opts  AltSvcOptions
+	clock func() time.Time
+
+	mu      sync.Mutex
+	origins map[string][]AltService // 源站 -> 替代服务
+	broken  map[string]time.Time    // 源站 + 替代地址 -> 停用截止时间
+}
+
+// update 用响应通告的替代服务替换源站的现有记录 (RFC 7838 3.)
+func (a *altSvcCache) update(origin string, header http.Header) {
+	if len(header.Values("Alt-Svc")) == 0 {
+		return
+	}
+	services, clear := ParseAltSvc(header, a.clock())
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if clear || len(services) == 0 {
+		delete(a.origins, origin)
+		return
+	}
+	if _, ok := a.origins[origin]; !ok && len(a.origins) >= maxAltSvcOrigins {
+		a.pruneLocked()
+		if len(a.origins) >= maxAltSvcOrigins {
+			return
+		}
+	}
+	a.origins[origin] = services
+}
+
+// pruneLocked 删除已过期的记录
+func (a *altSvcCache) pruneLocked() {
+	now := a.clock()
+	for origin, services := range a.origins {
+		services = slices.DeleteFunc(services, func(s AltService) bool { return !now.Before(s.Expires) })
+		if len(services) == 0 {
+			delete(a.origins, origin)
+		} else {
+			a.origins[origin] = services
+		}
+	}
+	for key, until := range a.broken {
+		if !now.Before(until) {
+			delete(a.broken, key)
+		}
+	}
+}
+
+// get 返回源站未过期的替代服务
+func (a *altSvcCache) get(origin string) []AltService {
+	now := a.clock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var services []AltService
+	for _, s := range a.origins[origin] {
+		if now.Before(s.Expires) {
+			services = append(services, s)
+		}
+	}
+	return services
+}
+
+// route 返回请求应连接的替代地址, 没有可用的替代端点时返回空
+func (a *altSvcCache) route(origin, host, port string) string {
+	now := a.clock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, s := range a.origins[origin] {
+		if !now.Before(s.Expires) || !slices.Contains(a.opts.Protocols, s.Protocol) {
+			continue
+		}
+		addr := s.Addr(host)
+		if addr == net.JoinHostPort(host, port) {
+			continue // 与源站相同, 无需切换
+		}
+		if until, ok := a.broken[origin+" "+addr]; ok && now.Before(until) {
+			continue
+		}
+		return addr
+	}
+	return ""
+}
+
+// markBroken 在替代端点连接失败后将其停用 FailureTTL
+func (a *altSvcCache) markBroken(origin, addr string) {
+	a.mu.Lock()
+	a.broken[origin+" "+addr] = a.clock().Add(a.opts.FailureTTL)
+	a.mu.Unlock()
+}
+
+// altSvcRoundTripper 是一个内部中间件, 记录响应通告的替代服务, 并在启用路由时经由 ConnectTo 的连接覆盖发往替代端点
+func (c *Client) altSvcRoundTripper(next http.RoundTripper) http.RoundTripper {
+	cache := c.altSvc
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Scheme != "https" {
+			return next.RoundTrip(req)
+		}
+		origin := altSvcOrigin(req.URL)
+
+		var alt string
+		if cache.opts.Route && c.altSvcRoutable(req) {
+			port := req.URL.Port()
+			if port == "" {
+				port = "443"
+			}
+			alt = cache.route(origin, strings.ToLower(req.URL.Hostname()), port)
+		}
+		if alt == "" {
+			resp, err := next.RoundTrip(req)
+			if resp != nil {
+				cache.update(origin, resp.Header)
+			}
+			return resp, err
+		}
+
+		altReq := req.WithContext(context.WithValue(req.Context(), connectOverrideKey{}, connectOverride{addr: alt}))
+		resp, err := next.RoundTrip(altReq)
+		if err == nil {
+			resp.Request = req
+			cache.update(origin, resp.Header)
+			return resp, nil
+		}
+		if !isAltSvcConnectFailure(err) || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		// 替代端点不可达: 停用后回退到源站, 请求尚未发出, 任何方法都可以重发
+		cache.markBroken(origin, alt)
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return nil, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err = next.RoundTrip(req)
+		if resp != nil {
+			cache.update(origin, resp.Header)
+		}
+		return resp, err
+	})
+}
+
+// altSvcRoutable 判断请求能否路由到替代端点: 未设置连接覆盖且不经过 HTTP 代理
+func (c *Client) altSvcRoutable(req *http.Request) bool {
+	if _, ok := requestConnectOverride(req); ok {
+		return false
+	}
+	if p := c.settings().proxy; p != nil {
+		if proxyURL, _ := p(req); proxyURL != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// isAltSvcConnectFailure 判断错误是否发生在连接建立阶段 (请求尚未发出)
+func isAltSvcConnectFailure(err error) bool {
+	if isConnectError(err) || errors.Is(err, ErrDialTimeout) {
+		return true
+	}
+	switch Classify(err) {
+	case ErrorClassDNS, ErrorClassConnRefused, ErrorClassTLS:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package httpc
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseAltSvc(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	header := http.Header{}
+	header.Add("Alt-Svc", `h3=":443"; ma=86400, h2="alt.example.com:8443"; ma=60; persist=1`)
+	header.Add("Alt-Svc", `http%2F1.1="[2001:db8::1]:80", h2=alt.example.com, h2=":99999"`)
+
+	services, clear := ParseAltSvc(header, now)
+	want := []AltService{
+		{Protocol: "h3", Port: 443, Expires: now.Add(24 * time.Hour)},
+		{Protocol: "h2", Host: "alt.example.com", Port: 8443, Expires: now.Add(time.Minute), Persist: true},
+		{Protocol: "http/1.1", Host: "2001:db8::1", Port: 80, Expires: now.Add(defaultAltSvcMaxAge)},
+	}
+	if clear || !reflect.DeepEqual(services, want) {
+		t.Fatalf("ParseAltSvc() = %+v, %v, want %+v, false", services, clear, want)
+	}
+	if got := want[2].Addr("example.com"); got != "[2001:db8::1]:80" {
+		t.Fatalf("Addr() = %q, want %q", got, "[2001:db8::1]:80")
+	}
+	if got := want[0].Addr("example.com"); got != "example.com:443" {
+		t.Fatalf("Addr() = %q, want %q", got, "example.com:443")
+	}
+
+	if _, clear := ParseAltSvc(http.Header{"Alt-Svc": {"clear"}}, now); !clear {
+		t.Fatalf("ParseAltSvc(clear) clear = false, want true")
+	}
+}
+
+func TestAltSvcRouting(t *testing.T) {
+	alt := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("alt " + r.Host))
+	}))
+	_, altPort, _ := net.SplitHostPort(alt.Listener.Addr().String())
+
+	origin := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", `h2=":`+altPort+`"; ma=60, h3=":443"`)
+		w.Write([]byte("origin"))
+	}))
+	defer origin.Close()
+
+	client := New(
+		WithTLSConfig(origin.Client().Transport.(*http.Transport).TLSClientConfig),
+		WithAltSvc(AltSvcOptions{Route: true}),
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+	)
+	get := func() string {
+		t.Helper()
+		body, err := client.GET(origin.URL).Text()
+		if err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+		return body
+	}
+
+	if got := get(); got != "origin" {
+		t.Fatalf("first body = %q, want %q", got, "origin")
+	}
+	if services := client.AltServices(origin.URL); len(services) != 2 || strconv.Itoa(services[0].Port) != altPort {
+		t.Fatalf("AltServices() = %+v, want h2 on port %s and h3", services, altPort)
+	}
+
+	originHost := origin.Listener.Addr().String()
+	if got := get(); got != "alt "+originHost {
+		t.Fatalf("routed body = %q, want %q", got, "alt "+originHost)
+	}
+
+	// 替代端点下线后回退到源站, 并在 FailureTTL 内不再尝试
+	alt.Close()
+	if got := get(); got != "origin" {
+		t.Fatalf("fallback body = %q, want %q", got, "origin")
+	}
+	u, _ := url.Parse(origin.URL)
+	if got := client.altSvc.route(altSvcOrigin(u), u.Hostname(), u.Port()); got != "" {
+		t.Fatalf("route() after failure = %q, want empty", got)
+	}
+}
+
+func TestAltSvcRecordOnly(t *testing.T) {
+	origin := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", `h2="127.0.0.1:1"`)
+	}))
+	defer origin.Close()
+
+	client := New(WithTLSConfig(origin.Client().Transport.(*http.Transport).TLSClientConfig), WithAltSvc(AltSvcOptions{}))
+	for range 2 {
+		if _, err := client.GET(origin.URL).Text(); err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+	}
+	if services := client.AltServices(origin.URL); len(services) != 1 || services[0].Port != 1 {
+		t.Fatalf("AltServices() = %+v, want the advertised h2 endpoint", services)
+	}
+	if got := New().AltServices(origin.URL); got != nil {
+		t.Fatalf("AltServices() without WithAltSvc = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,84 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Attempt 描述一次请求尝试的结果
+type Attempt struct {
+	StatusCode int           // 响应状态码, 出错时为 0
+	Err        error         // 本次尝试的错误
+	Duration   time.Duration // 发送请求到收到响应头 (或出错) 的耗时
+	Backoff    time.Duration // 本次尝试之后实际的重试等待 (等待中取消时为已等待的时长), 最后一次尝试为 0
+}
+
+// AttemptLog 记录一次请求的全部尝试, 成功的请求也可能经过多次重试
+type AttemptLog struct {
+	Attempts []Attempt
+	Backoff  time.Duration // 重试等待的总时长
+}
+
+// Count 返回尝试次数
+func (l AttemptLog) Count() int {
+	return len(l.Attempts)
+}
+
+// Retries 返回重试次数 (不含首次尝试)
+func (l AttemptLog) Retries() int {
+	return max(len(l.Attempts)-1, 0)
+}
+
+// AttemptError 是经过重试层的请求返回的错误, 携带全部尝试的记录.
+// Error 与 Unwrap 保持原错误, 可通过 errors.As 取得
+type AttemptError struct {
+	Err error
+	Log AttemptLog
+}
+
+func (e *AttemptError) Error() string { return e.Err.Error() }
+
+func (e *AttemptError) Unwrap() error { return e.Err }
+
+// attemptLogKey 是尝试记录在 Context 中的键
+type attemptLogKey struct{}
+
+// ContextWithAttemptLog 返回记录尝试信息的 Context, 经由该 Context 发出的请求完成后 (Do 返回时)
+// 将尝试记录写入 log. 用于 DecodeJSON, Call 等不返回 Response 的方法;
+// 多次请求复用时只保留最后一次, 不要在并发请求间共享
+func ContextWithAttemptLog(ctx context.Context) (context.Context, *AttemptLog) {
+	log := &AttemptLog{}
+	return context.WithValue(ctx, attemptLogKey{}, log), log
+}
+
+func attemptLogFrom(ctx context.Context) *AttemptLog {
+	log, _ := ctx.Value(attemptLogKey{}).(*AttemptLog)
+	return log
+}
+
+// newAttempt 根据一次尝试的结果创建记录
+func newAttempt(resp *http.Response, err error, elapsed time.Duration) Attempt {
+	a := Attempt{Err: err, Duration: elapsed}
+	if resp != nil {
+		a.StatusCode = resp.StatusCode
+	}
+	return a
+}
+
+// finishAttempts 在重试循环结束时保存尝试记录, 并为错误附加记录
+func (c *Client) finishAttempts(req *http.Request, attempts []Attempt, backoff time.Duration, resp *http.Response, err error) (*http.Response, error) {
+	log := attemptLogFrom(req.Context())
+	if log == nil && err == nil {
+		return resp, nil
+	}
+	result := AttemptLog{Attempts: slices.Clone(attempts), Backoff: backoff}
+	if log != nil {
+		*log = result
+	}
+	if err != nil {
+		err = &AttemptError{Err: c.wrapError(err), Log: result}
+	}
+	return resp, err
+}
@@ -0,0 +1,159 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseAttempts(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	resp, err := client.GET(srv.URL).Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	resp.Body.Close()
+
+	log := resp.Attempts()
+	if log.Count() != 3 || log.Retries() != 2 {
+		t.Fatalf("Count() = %d, Retries() = %d, want 3, 2", log.Count(), log.Retries())
+	}
+	for i, want := range []int{503, 503, 200} {
+		if got := log.Attempts[i].StatusCode; got != want {
+			t.Fatalf("Attempts[%d].StatusCode = %d, want %d", i, got, want)
+		}
+	}
+	var sum time.Duration
+	for _, a := range log.Attempts {
+		sum += a.Backoff
+	}
+	if log.Backoff <= 0 || log.Backoff != sum || log.Attempts[2].Backoff != 0 {
+		t.Fatalf("Backoff = %v (attempts %+v), want positive sum of per-attempt backoff", log.Backoff, log.Attempts)
+	}
+}
+
+func TestResponseAttemptsWithoutRetry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	resp, err := New(WithRetryOptions(RetryOptions{MaxAttempts: 0})).GET(srv.URL).Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	resp.Body.Close()
+	if log := resp.Attempts(); log.Count() != 1 || log.Attempts[0].StatusCode != http.StatusAccepted || log.Backoff != 0 {
+		t.Fatalf("Attempts() = %+v, want a single 202 attempt", log)
+	}
+}
+
+func TestAttemptErrorCarriesLog(t *testing.T) {
+	addr := closedAddr(t)
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	_, err := client.GET("http://" + addr).Send()
+
+	var attemptErr *AttemptError
+	if !errors.As(err, &attemptErr) {
+		t.Fatalf("err = %v, want *AttemptError", err)
+	}
+	if !errors.Is(err, ErrMaxRetriesExceeded) || !errors.Is(err, ErrConnect) {
+		t.Fatalf("err = %v, want ErrMaxRetriesExceeded and ErrConnect", err)
+	}
+	if attemptErr.Log.Count() != 2 {
+		t.Fatalf("Count() = %d, want 2", attemptErr.Log.Count())
+	}
+	for i, a := range attemptErr.Log.Attempts {
+		if a.Err == nil || a.StatusCode != 0 {
+			t.Fatalf("Attempts[%d] = %+v, want connection error", i, a)
+		}
+	}
+}
+
+func TestContextWithAttemptLog(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"a"}`))
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusBadGateway}}))
+	ctx, log := ContextWithAttemptLog(context.Background())
+	var out callUser
+	if err := client.GET(srv.URL).WithContext(ctx).DecodeJSON(&out); err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+	if log.Count() != 2 || log.Attempts[0].StatusCode != http.StatusBadGateway {
+		t.Fatalf("log = %+v, want 502 then 200", log)
+	}
+}
+
+func TestAttemptBackoffRecordsWaitBeforeCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	client := New(WithClock(clock), WithRetryOptions(RetryOptions{
+		MaxAttempts: 1, BaseDelay: 10 * time.Second, MaxDelay: 10 * time.Second,
+		RetryStatuses: []int{http.StatusServiceUnavailable},
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GET(srv.URL).WithContext(ctx).Send()
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	cancel()
+
+	var attemptErr *AttemptError
+	if err := <-done; !errors.As(err, &attemptErr) {
+		t.Fatalf("Send() error = %v, want *AttemptError", err)
+	}
+	if log := attemptErr.Log; log.Backoff != time.Second || log.Attempts[0].Backoff != time.Second {
+		t.Fatalf("Backoff = %v (attempts %+v), want 1s actually waited", log.Backoff, log.Attempts)
+	}
+}
+
+func TestSendDoesNotMutateBuilderContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	rb := New().GET(srv.URL)
+	ctx := rb.context
+	for range 2 {
+		resp, err := rb.Send()
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.Attempts().Count() != 1 {
+			t.Fatalf("Count() = %d, want 1", resp.Attempts().Count())
+		}
+	}
+	if rb.context != ctx {
+		t.Fatal("Send() replaced the builder context")
+	}
+}
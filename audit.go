@@ -0,0 +1,172 @@
+package httpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// auditRedacted 是被脱敏的查询参数值
+const auditRedacted = "REDACTED"
+
+// AuditRecord 是一次已完成请求的审计记录
+type AuditRecord struct {
+	Time          time.Time `json:"time"`               // 请求开始时间
+	Method        string    `json:"method"`             // 请求方法
+	URL           string    `json:"url"`                // 按规则脱敏后的 URL, 不含密码
+	Status        int       `json:"status,omitempty"`   // 最终响应状态码, 失败时为 0
+	Error         string    `json:"error,omitempty"`    // 最终错误, 其中的请求 URL 已脱敏
+	Duration      Duration  `json:"duration"`           // 从开始到响应体读取完毕或关闭的耗时, JSON 中为 "1.5s" 形式
+	BytesSent     int64     `json:"bytes_sent"`         // 所有尝试发送的请求体字节数
+	BytesReceived int64     `json:"bytes_received"`     // 最终响应体的字节数
+	Attempts      int       `json:"attempts"`           // 到达 Transport 的尝试次数 (含重试)
+	Identity      string    `json:"identity,omitempty"` // 客户端身份
+}
+
+// AuditSink 接收审计记录, 每个完成的请求调用一次, 需要并发安全
+type AuditSink func(ctx context.Context, record AuditRecord)
+
+// AuditOptions 审计配置
+type AuditOptions struct {
+	Sink     AuditSink // 记录的接收方, 为 nil 时不启用审计
+	Identity string    // 写入记录的客户端身份 (例如服务名或凭据 ID)
+
+	// RedactQuery 列出需要脱敏的查询参数名 (不区分大小写), 其值替换为 "REDACTED"; "*" 表示全部参数
+	RedactQuery []string
+}
+
+// WithAudit 为客户端发出的每个请求在完成时 (响应体读取完毕或关闭, 或请求失败) 生成一条审计记录,
+// 用于需要记录所有外发调用的合规场景. 审计位于调用链最外层, 因此包含重试次数与全部耗时
+func WithAudit(opts AuditOptions) Option {
+	return func(c *Client) {
+		if opts.Sink == nil {
+			c.audit = nil
+			return
+		}
+		redact := make(map[string]bool, len(opts.RedactQuery))
+		for _, name := range opts.RedactQuery {
+			redact[strings.ToLower(name)] = true
+		}
+		c.audit = &auditor{sink: opts.Sink, identity: opts.Identity, redact: redact}
+	}
+}
+
+// NewJSONAuditSink 返回一个将记录以 JSON Lines 格式写入 w 的 AuditSink, 写入失败时丢弃记录.
+// 需要使用其他 JSON 实现时可自行编写 AuditSink
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	var mu sync.Mutex
+	return func(ctx context.Context, record AuditRecord) {
+		line, err := defaultJSON.Marshal(record)
+		if err != nil {
+			return
+		}
+		line = append(line, '\n')
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(line)
+	}
+}
+
+// auditor 是 WithAudit 的运行时状态
+type auditor struct {
+	sink     AuditSink
+	identity string
+	redact   map[string]bool // 小写参数名, "*" 表示全部
+}
+
+// auditState 通过 Context 传递, 由 trafficRoundTripper 累计每次尝试
+type auditState struct {
+	attempts atomic.Int32
+	sent     atomic.Int64
+}
+
+type auditStateKey struct{}
+
+func auditStateFrom(ctx context.Context) *auditState {
+	st, _ := ctx.Value(auditStateKey{}).(*auditState)
+	return st
+}
+
+// redactURL 返回去掉密码并按规则脱敏查询参数的 URL
+func (a *auditor) redactURL(u *url.URL) string {
+	if u.RawQuery == "" || len(a.redact) == 0 {
+		return u.Redacted()
+	}
+
+	redacted := *u
+	parts := strings.Split(u.RawQuery, "&")
+	for i, part := range parts {
+		key, _, _ := strings.Cut(part, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil {
+			name = key
+		}
+		if a.redact["*"] || a.redact[strings.ToLower(name)] {
+			parts[i] = key + "=" + auditRedacted
+		}
+	}
+	redacted.RawQuery = strings.Join(parts, "&")
+	return redacted.Redacted()
+}
+
+// redactError 返回错误信息, 其中出现的请求 URL 与查询串按 redactURL 的规则脱敏
+// (url.Error, RobotsError 等错误会原样带上完整的 URL)
+func (a *auditor) redactError(err error, u *url.URL) string {
+	text := err.Error()
+	raw, redacted := u.String(), a.redactURL(u)
+	if raw == redacted {
+		return text
+	}
+	text = strings.ReplaceAll(text, raw, redacted)
+	if ru, perr := url.Parse(redacted); perr == nil && u.RawQuery != "" && ru.RawQuery != u.RawQuery {
+		text = strings.ReplaceAll(text, "?"+u.RawQuery, "?"+ru.RawQuery)
+	}
+	return text
+}
+
+// auditRoundTripper 是一个内部中间件, 位于调用链最外层, 在请求完成时输出审计记录
+func (c *Client) auditRoundTripper(a *auditor, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		st := &auditState{}
+		record := AuditRecord{
+			Time:     c.clock.Now(),
+			Method:   req.Method,
+			URL:      a.redactURL(req.URL),
+			Identity: a.identity,
+		}
+		ctx := req.Context()
+		var received atomic.Int64
+		emit := sync.OnceFunc(func() {
+			record.Duration = Duration(c.clock.Now().Sub(record.Time))
+			record.BytesSent = st.sent.Load()
+			record.BytesReceived = received.Load()
+			record.Attempts = int(st.attempts.Load())
+			a.sink(ctx, record)
+		})
+
+		resp, err := next.RoundTrip(req.WithContext(context.WithValue(ctx, auditStateKey{}, st)))
+		if resp != nil {
+			resp.Request = req
+			record.Status = resp.StatusCode
+		}
+		if err != nil {
+			record.Error = a.redactError(err, req.URL)
+		}
+		// 101 协议升级的响应体是可写的连接, 不做包装
+		if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			emit()
+			return resp, err
+		}
+
+		resp.Body = &trackedBody{
+			countingReadCloser: countingReadCloser{ReadCloser: resp.Body, n: &received},
+			onDone:             emit,
+		}
+		return resp, nil
+	})
+}
@@ -0,0 +1,139 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+func TestAuditRecordsCompletedRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var records []AuditRecord
+	client := New(
+		WithAudit(AuditOptions{
+			Sink: func(ctx context.Context, record AuditRecord) {
+				mu.Lock()
+				records = append(records, record)
+				mu.Unlock()
+			},
+			Identity:    "billing-service",
+			RedactQuery: []string{"Token"},
+		}),
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}),
+	)
+
+	got, err := client.POST(srv.URL + "/pay?token=secret&id=7").SetRawBody([]byte("abc")).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "hello" {
+		t.Fatalf("body = %q, want %q", got, "hello")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	r := records[0]
+	if want := srv.URL + "/pay?token=REDACTED&id=7"; r.URL != want {
+		t.Fatalf("URL = %q, want %q", r.URL, want)
+	}
+	if r.Method != http.MethodPost || r.Status != http.StatusOK || r.Identity != "billing-service" {
+		t.Fatalf("record = %+v", r)
+	}
+	if r.Attempts != 2 || r.BytesSent != 6 || r.BytesReceived != 5 {
+		t.Fatalf("Attempts/BytesSent/BytesReceived = %d/%d/%d, want 2/6/5", r.Attempts, r.BytesSent, r.BytesReceived)
+	}
+	if r.Duration <= 0 {
+		t.Fatalf("Duration = %v, want > 0", r.Duration)
+	}
+}
+
+func TestJSONAuditSinkOnError(t *testing.T) {
+	var buf bytes.Buffer
+	client := New(
+		WithAudit(AuditOptions{Sink: NewJSONAuditSink(&buf), RedactQuery: []string{"*"}}),
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+	)
+	if _, err := client.GET("http://user:pass@" + closedAddr(t) + "/?a=1&b=2").Text(); err == nil {
+		t.Fatal("request to closed port succeeded")
+	}
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	if strings.Contains(line, "\n") {
+		t.Fatalf("sink wrote multiple lines: %q", buf.String())
+	}
+	var r AuditRecord
+	if err := json.Unmarshal([]byte(line), &r); err != nil {
+		t.Fatalf("Unmarshal(%q) error = %v", line, err)
+	}
+	if r.Error == "" || r.Status != 0 || r.Attempts != 1 {
+		t.Fatalf("record = %+v, want error with 1 attempt", r)
+	}
+	if strings.Contains(r.URL, "pass") || !strings.HasSuffix(r.URL, "/?a=REDACTED&b=REDACTED") {
+		t.Fatalf("URL = %q, want password and query redacted", r.URL)
+	}
+}
+
+func TestAuditRedactsURLInError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "User-agent: *\nDisallow: /\n")
+	}))
+	defer srv.Close()
+
+	var records []AuditRecord
+	client := New(
+		WithAudit(AuditOptions{Sink: func(ctx context.Context, r AuditRecord) { records = append(records, r) }, RedactQuery: []string{"token"}}),
+		WithRobots(RobotsOptions{}),
+	)
+	_, err := client.GET(srv.URL + "/private?token=secret&page=2").Bytes()
+	if !errors.Is(err, ErrRobotsDisallowed) || !strings.Contains(err.Error(), "secret") {
+		t.Fatalf("Bytes() error = %v, want robots error with the full URL", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	if got := records[0].Error; strings.Contains(got, "secret") || !strings.Contains(got, "token=REDACTED&page=2") {
+		t.Fatalf("Error = %q, want token redacted", got)
+	}
+}
+
+func TestAuditUsesClientClock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	var record AuditRecord
+	client := New(
+		WithClock(clock),
+		WithAudit(AuditOptions{Sink: func(ctx context.Context, r AuditRecord) { record = r }}),
+	)
+	if _, err := client.GET(srv.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if !record.Time.Equal(clock.Now()) || record.Duration != 0 {
+		t.Fatalf("Time/Duration = %v/%v, want %v/0", record.Time, record.Duration, clock.Now())
+	}
+}
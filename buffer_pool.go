@@ -0,0 +1,210 @@
+package httpc
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+)
+
+// bufferTierScales 是默认缓冲池的分级倍数, 以 bufferSize 为基础 (默认 32KB/256KB/1MB)
+var bufferTierScales = [...]int{1, 8, 32}
+
+// BufferPoolStats 是默认缓冲池的统计
+type BufferPoolStats struct {
+	Gets          int64 // Get 次数
+	Hits          int64 // 复用已保留 buffer 的次数
+	Puts          int64 // Put 次数
+	Drops         int64 // 因超出保留上限或容量过大而丢弃的 buffer 数
+	Retained      int64 // 当前保留的 buffer 数量
+	BytesRetained int64 // 当前保留的 buffer 总容量
+}
+
+// HitRate 返回 Get 的命中率, 没有 Get 时为 0
+func (s BufferPoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// BufferPoolStats 返回默认缓冲池的统计, 使用 WithBufferPool 自定义缓冲池时返回零值
+func (c *Client) BufferPoolStats() BufferPoolStats {
+	p, ok := c.bufferPool.(*defaultPool)
+	if !ok {
+		return BufferPoolStats{}
+	}
+	return p.stats()
+}
+
+// Buffer 是从客户端缓冲池借出的响应体, 用完后需调用 Release 归还
+type Buffer struct {
+	buf  *bytes.Buffer
+	pool BufferPool
+}
+
+// Bytes 返回响应体内容, 仅在 Release 之前有效
+func (b *Buffer) Bytes() []byte {
+	if b.buf == nil {
+		return nil
+	}
+	return b.buf.Bytes()
+}
+
+// String 以字符串形式返回响应体内容的副本
+func (b *Buffer) String() string {
+	return string(b.Bytes())
+}
+
+// Len 返回响应体的字节数
+func (b *Buffer) Len() int {
+	return len(b.Bytes())
+}
+
+// Release 将底层 buffer 归还缓冲池, 之后不应再使用 Bytes 返回的切片; 重复调用是安全的
+func (b *Buffer) Release() {
+	if b.buf == nil {
+		return
+	}
+	b.pool.Put(b.buf)
+	b.buf = nil
+}
+
+// BytesPooled 执行请求并将响应体读入从缓冲池借出的 Buffer, 适用于读取后立即解析并丢弃的热路径,
+// 避免每个请求分配新的切片. 调用方必须在使用完毕后调用 Release
+func (rb *RequestBuilder) BytesPooled() (*Buffer, error) {
+	resp, err := rb.Execute()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, rb.client.errorResponse(resp)
+	}
+	buf, err := rb.client.readPooled(resp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+	return &Buffer{buf: buf, pool: rb.client.bufferPool}, nil
+}
+
+// getBuffer 从缓冲池获取一个容量不小于 n 的空 buffer
+func (c *Client) getBuffer(n int) *bytes.Buffer {
+	if p, ok := c.bufferPool.(*defaultPool); ok {
+		return p.getSize(n)
+	}
+	buf := c.bufferPool.Get()
+	buf.Grow(n)
+	return buf
+}
+
+// defaultPool 是每个客户端独立的分级缓冲池
+// 按容量分为多个等级, 每级使用有界的空闲列表, 所有等级合计最多保留 limit 个 buffer
+type defaultPool struct {
+	tiers [len(bufferTierScales)]bufferTier
+	limit int64
+
+	retained      atomic.Int64
+	bytesRetained atomic.Int64
+	gets          atomic.Int64
+	hits          atomic.Int64
+	puts          atomic.Int64
+	drops         atomic.Int64
+}
+
+// bufferTier 是单个容量等级, 保留的 buffer 容量均不小于 size
+type bufferTier struct {
+	size int
+	free chan *bytes.Buffer
+}
+
+// newDefaultPool 创建缓冲池, bufferSize 为最小等级的容量, maxRetained 为最多保留的 buffer 数量 (<= 0 时不保留)
+func newDefaultPool(bufferSize, maxRetained int) *defaultPool {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	p := &defaultPool{limit: int64(max(maxRetained, 0))}
+	for i, scale := range bufferTierScales {
+		p.tiers[i] = bufferTier{
+			size: bufferSize * scale,
+			free: make(chan *bytes.Buffer, max(maxRetained, 0)),
+		}
+	}
+	return p
+}
+
+// Get 返回一个最小等级容量的空 buffer
+func (p *defaultPool) Get() *bytes.Buffer {
+	return p.getSize(0)
+}
+
+// getSize 返回一个容量不小于 n 的空 buffer, n 超过最大等级时直接分配
+func (p *defaultPool) getSize(n int) *bytes.Buffer {
+	p.gets.Add(1)
+	for i := range p.tiers {
+		tier := &p.tiers[i]
+		if tier.size < n {
+			continue
+		}
+		select {
+		case buf := <-tier.free:
+			p.retained.Add(-1)
+			p.bytesRetained.Add(-int64(buf.Cap()))
+			p.hits.Add(1)
+			return buf
+		default:
+		}
+	}
+	for i := range p.tiers {
+		if p.tiers[i].size >= n {
+			return bytes.NewBuffer(make([]byte, 0, p.tiers[i].size))
+		}
+	}
+	return bytes.NewBuffer(make([]byte, 0, n))
+}
+
+// Put 将 buffer 放回其容量对应的等级; 超过保留上限, 小于最小等级或大于最大等级两倍的 buffer 被丢弃
+func (p *defaultPool) Put(buf *bytes.Buffer) {
+	if buf == nil {
+		return
+	}
+	p.puts.Add(1)
+	size := buf.Cap()
+	top := p.tiers[len(p.tiers)-1].size
+	if size < p.tiers[0].size || size > top*2 {
+		p.drops.Add(1)
+		return
+	}
+	if p.retained.Add(1) > p.limit {
+		p.retained.Add(-1)
+		p.drops.Add(1)
+		return
+	}
+
+	tier := &p.tiers[0]
+	for i := len(p.tiers) - 1; i >= 0; i-- {
+		if size >= p.tiers[i].size {
+			tier = &p.tiers[i]
+			break
+		}
+	}
+	buf.Reset()
+	p.bytesRetained.Add(int64(size))
+	select {
+	case tier.free <- buf:
+	default:
+		p.retained.Add(-1)
+		p.bytesRetained.Add(-int64(size))
+		p.drops.Add(1)
+	}
+}
+
+func (p *defaultPool) stats() BufferPoolStats {
+	return BufferPoolStats{
+		Gets:          p.gets.Load(),
+		Hits:          p.hits.Load(),
+		Puts:          p.puts.Load(),
+		Drops:         p.drops.Load(),
+		Retained:      p.retained.Load(),
+		BytesRetained: p.bytesRetained.Load(),
+	}
+}
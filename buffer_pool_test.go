@@ -0,0 +1,133 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultPoolTiers(t *testing.T) {
+	p := newDefaultPool(1<<10, 10)
+
+	if got := p.Get().Cap(); got != 1<<10 {
+		t.Fatalf("Get().Cap() = %d, want %d", got, 1<<10)
+	}
+	if got := p.getSize(4 << 10).Cap(); got != 8<<10 {
+		t.Fatalf("getSize(4KB).Cap() = %d, want %d", got, 8<<10)
+	}
+	if got := p.getSize(64 << 10).Cap(); got != 64<<10 {
+		t.Fatalf("getSize(64KB).Cap() = %d, want %d", got, 64<<10)
+	}
+
+	large := bytes.NewBuffer(make([]byte, 0, 32<<10))
+	p.Put(large)
+	// 小请求也可以复用较大等级的 buffer
+	if got := p.Get(); got != large {
+		t.Fatal("Get() did not reuse the retained buffer")
+	}
+
+	stats := p.stats()
+	if stats.Gets != 4 || stats.Hits != 1 || stats.Puts != 1 {
+		t.Fatalf("stats = %+v, want Gets 4, Hits 1, Puts 1", stats)
+	}
+	if stats.HitRate() != 0.25 {
+		t.Fatalf("HitRate() = %v, want 0.25", stats.HitRate())
+	}
+}
+
+func TestDefaultPoolEnforcesLimit(t *testing.T) {
+	p := newDefaultPool(1<<10, 2)
+
+	for range 3 {
+		p.Put(bytes.NewBuffer(make([]byte, 0, 1<<10)))
+	}
+	p.Put(bytes.NewBuffer(make([]byte, 0, 16)))      // 小于最小等级
+	p.Put(bytes.NewBuffer(make([]byte, 0, 128<<10))) // 超过最大等级两倍
+
+	stats := p.stats()
+	if stats.Retained != 2 || stats.BytesRetained != 2<<10 {
+		t.Fatalf("Retained = %d (%d bytes), want 2 (%d bytes)", stats.Retained, stats.BytesRetained, 2<<10)
+	}
+	if stats.Drops != 3 {
+		t.Fatalf("Drops = %d, want 3", stats.Drops)
+	}
+
+	p.Get()
+	if stats := p.stats(); stats.Retained != 1 || stats.BytesRetained != 1<<10 {
+		t.Fatalf("after Get: Retained = %d (%d bytes), want 1 (%d bytes)", stats.Retained, stats.BytesRetained, 1<<10)
+	}
+}
+
+func TestClientBufferPoolIsPerClient(t *testing.T) {
+	a := New(WithBufferSize(4<<10), WithMaxBufferPoolSize(1))
+	b := New()
+
+	buf := a.bufferPool.Get()
+	if buf.Cap() != 4<<10 {
+		t.Fatalf("Cap() = %d, want %d", buf.Cap(), 4<<10)
+	}
+	a.bufferPool.Put(buf)
+
+	if got := a.BufferPoolStats().Retained; got != 1 {
+		t.Fatalf("a.Retained = %d, want 1", got)
+	}
+	if got := b.BufferPoolStats().Retained; got != 0 {
+		t.Fatalf("b.Retained = %d, want 0", got)
+	}
+	if got := New(WithBufferPool(plainBufferPool{})).BufferPoolStats(); got != (BufferPoolStats{}) {
+		t.Fatalf("custom pool stats = %+v, want zero", got)
+	}
+}
+
+func TestBytesPooled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("pooled body"))
+	}))
+	defer server.Close()
+
+	client := New()
+	buf, err := client.GET(server.URL).BytesPooled()
+	if err != nil {
+		t.Fatalf("BytesPooled() error = %v", err)
+	}
+	if got := buf.String(); got != "pooled body" {
+		t.Fatalf("String() = %q, want %q", got, "pooled body")
+	}
+
+	buf.Release()
+	buf.Release()
+	if buf.Bytes() != nil {
+		t.Fatal("Bytes() after Release != nil")
+	}
+	if got := client.BufferPoolStats().Retained; got != 1 {
+		t.Fatalf("Retained = %d, want 1", got)
+	}
+
+	// 第二次请求复用归还的 buffer
+	buf, err = client.GET(server.URL).BytesPooled()
+	if err != nil {
+		t.Fatalf("BytesPooled() error = %v", err)
+	}
+	buf.Release()
+	if got := client.BufferPoolStats().Hits; got != 1 {
+		t.Fatalf("Hits = %d, want 1", got)
+	}
+
+	_, err = client.GET(server.URL + "/missing").BytesPooled()
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("BytesPooled() error = %v, want 404 HTTPError", err)
+	}
+}
+
+// plainBufferPool 是测试用的自定义缓冲池
+type plainBufferPool struct{}
+
+func (plainBufferPool) Get() *bytes.Buffer { return new(bytes.Buffer) }
+func (plainBufferPool) Put(*bytes.Buffer)  {}
@@ -0,0 +1,317 @@
+package httpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 缓存条目中记录的内部头部, 返回给调用方前移除
+const (
+	cacheResponseTimeHeader = "X-Httpc-Response-Time" // 收到响应的时间 (RFC 3339, 纳秒精度)
+	cacheVariedHeaderPrefix = "X-Httpc-Varied-"       // Vary 列出的请求头在存储时的值
+)
+
+// defaultCacheMaxBodySize 是默认可缓存的最大响应体
+const defaultCacheMaxBodySize = 1 << 20
+
+// CacheStore 保存序列化后的响应, 可以接入 Redis 等外部存储.
+// 存储失败不影响请求, 因此方法不返回错误; 实现需要并发安全
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+	Delete(ctx context.Context, key string)
+}
+
+// CacheOptions HTTP 缓存配置
+type CacheOptions struct {
+	Store       CacheStore // 为 nil 时使用默认配置的 MemoryCacheStore
+	MaxBodySize int64      // 可缓存的最大响应体, 0 使用默认值 (1MB)
+}
+
+// WithCache 为 GET 请求启用私有 HTTP 缓存: 带有明确新鲜度 (Cache-Control max-age 或 Expires) 的响应
+// 在新鲜期内直接从缓存返回, 不经过重试, 限速与 Transport. 不做条件请求重新验证, 过期的条目视为未命中.
+// 带有 Authorization 或 Range 的请求不使用缓存
+func WithCache(opts CacheOptions) Option {
+	return func(c *Client) {
+		if opts.Store == nil {
+			opts.Store = NewMemoryCacheStore(MemoryCacheOptions{Clock: clientClock{c}})
+		}
+		if opts.MaxBodySize <= 0 {
+			opts.MaxBodySize = defaultCacheMaxBodySize
+		}
+		c.cache = &httpCache{store: opts.Store, maxBodySize: opts.MaxBodySize}
+	}
+}
+
+// clientClock 始终使用客户端当前的时间源, 不受 WithClock 与 WithCache 的顺序影响
+type clientClock struct{ c *Client }
+
+func (k clientClock) Now() time.Time                         { return k.c.clock.Now() }
+func (k clientClock) After(d time.Duration) <-chan time.Time { return k.c.clock.After(d) }
+
+// httpCache 是 WithCache 的运行时状态
+type httpCache struct {
+	store       CacheStore
+	maxBodySize int64
+}
+
+// cacheableStatus 是 RFC 9110 中默认可缓存的状态码
+var cacheableStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// cacheRoundTripper 是一个内部中间件, 位于超时与重试之外, 命中时直接返回缓存的响应
+func (c *Client) cacheRoundTripper(cache *httpCache, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if !isSafeMethod(req.Method) {
+			// 不安全的方法成功后使目标 URL 的缓存失效 (RFC 9111 4.4)
+			resp, err := next.RoundTrip(req)
+			if err == nil && resp != nil && resp.StatusCode < 400 {
+				cache.store.Delete(context.WithoutCancel(req.Context()), cacheKey(req))
+			}
+			return resp, err
+		}
+		if req.Method != http.MethodGet || req.Header.Get("Authorization") != "" || req.Header.Get("Range") != "" {
+			return next.RoundTrip(req)
+		}
+		reqCC := parseCacheControl(req.Header)
+		if _, ok := reqCC["no-store"]; ok {
+			return next.RoundTrip(req)
+		}
+
+		ctx := context.WithoutCancel(req.Context()) // 写入缓存发生在读取响应体时, 不随请求取消
+		key := cacheKey(req)
+		if _, noCache := reqCC["no-cache"]; !noCache {
+			if resp := c.cachedResponse(cache, req, key, reqCC); resp != nil {
+				c.metrics.cacheHits.Add(1)
+				if m := c.metrics.labeled(req.Context()); m != nil {
+					m.cacheHits.Add(1)
+				}
+				return resp, nil
+			}
+		}
+
+		resp, err := next.RoundTrip(req)
+		if err != nil || resp == nil || resp.Body == nil {
+			return resp, err
+		}
+		lifetime, ok := freshnessLifetime(resp)
+		if !ok || !cacheableStatus[resp.StatusCode] || resp.ContentLength > cache.maxBodySize {
+			return resp, nil
+		}
+		if strings.TrimSpace(resp.Header.Get("Vary")) == "*" {
+			return resp, nil
+		}
+
+		received := c.clock.Now()
+		ttl := lifetime - currentAge(resp, received, received)
+		if ttl <= 0 {
+			return resp, nil
+		}
+		resp.Body = &cachingBody{
+			ReadCloser: resp.Body,
+			limit:      cache.maxBodySize,
+			onEOF: func(body []byte) {
+				if data, err := serializeCachedResponse(req, resp, body, received); err == nil {
+					cache.store.Set(ctx, key, data, ttl)
+				}
+			},
+		}
+		return resp, nil
+	})
+}
+
+// cacheKey 返回请求的缓存键: URL, 以及 SetHostHeader 与 ConnectTo/SetServerName 的覆盖,
+// 覆盖后的请求可能到达不同的上游, 不能与未覆盖的请求共享缓存
+func cacheKey(req *http.Request) string {
+	key := req.URL.String()
+	if req.Host != "" && req.Host != req.URL.Host {
+		key += " host=" + req.Host
+	}
+	if o, ok := requestConnectOverride(req); ok {
+		key += " connect=" + o.addr + " sni=" + o.serverName
+	}
+	return key
+}
+
+// isSafeMethod 判断请求方法是否为安全方法 (RFC 9110 9.2.1)
+func isSafeMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// cachedResponse 返回新鲜且与请求的 Vary 头匹配的缓存响应, 没有时返回 nil
+func (c *Client) cachedResponse(cache *httpCache, req *http.Request, key string, reqCC map[string]string) *http.Response {
+	data, ok := cache.store.Get(req.Context(), key)
+	if !ok {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req)
+	if err != nil {
+		cache.store.Delete(req.Context(), key)
+		return nil
+	}
+
+	received, err := time.Parse(time.RFC3339Nano, resp.Header.Get(cacheResponseTimeHeader))
+	if err != nil {
+		return nil
+	}
+	for _, name := range varyHeaders(resp.Header) {
+		if resp.Header.Get(cacheVariedHeaderPrefix+name) != req.Header.Get(name) {
+			return nil
+		}
+	}
+
+	lifetime, ok := freshnessLifetime(resp)
+	if !ok {
+		return nil
+	}
+	age := currentAge(resp, received, c.clock.Now())
+	if age >= lifetime {
+		return nil
+	}
+	if v, ok := reqCC["max-age"]; ok {
+		if maxAge, err := strconv.Atoi(v); err == nil && age > time.Duration(maxAge)*time.Second {
+			return nil
+		}
+	}
+
+	for name := range resp.Header {
+		if strings.HasPrefix(name, "X-Httpc-") {
+			resp.Header.Del(name)
+		}
+	}
+	resp.Header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	return resp
+}
+
+// serializeCachedResponse 将响应头与完整的响应体序列化为 HTTP/1.1 报文, 并附带内部头部
+func serializeCachedResponse(req *http.Request, resp *http.Response, body []byte, received time.Time) ([]byte, error) {
+	header := resp.Header.Clone()
+	header.Set(cacheResponseTimeHeader, received.Format(time.RFC3339Nano))
+	for _, name := range varyHeaders(resp.Header) {
+		header.Set(cacheVariedHeaderPrefix+name, req.Header.Get(name))
+	}
+	stored := &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	var buf bytes.Buffer
+	if err := stored.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// freshnessLifetime 返回响应的新鲜期, 响应不可缓存或没有明确的新鲜度时 ok 为 false
+func freshnessLifetime(resp *http.Response) (time.Duration, bool) {
+	cc := parseCacheControl(resp.Header)
+	if _, ok := cc["no-store"]; ok {
+		return 0, false
+	}
+	if _, ok := cc["no-cache"]; ok {
+		return 0, false
+	}
+	if v, ok := cc["max-age"]; ok {
+		maxAge, err := strconv.Atoi(v)
+		if err != nil || maxAge <= 0 {
+			return 0, false
+		}
+		return time.Duration(maxAge) * time.Second, true
+	}
+
+	expires, err := http.ParseTime(resp.Header.Get("Expires"))
+	if err != nil {
+		return 0, false
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	lifetime := expires.Sub(date)
+	return lifetime, lifetime > 0
+}
+
+// currentAge 按 RFC 9111 4.2.3 计算响应在 now 时的年龄, received 为收到响应的时间
+func currentAge(resp *http.Response, received, now time.Time) time.Duration {
+	var age time.Duration
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		age = max(received.Sub(date), 0)
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("Age")); err == nil && v > 0 {
+		age = max(age, time.Duration(v)*time.Second)
+	}
+	return age + max(now.Sub(received), 0)
+}
+
+// parseCacheControl 解析 Cache-Control 指令, 指令名为小写, 无值的指令对应空字符串
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for part := range strings.SplitSeq(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+// varyHeaders 返回 Vary 列出的请求头名称 (规范化形式)
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, line := range header.Values("Vary") {
+		for name := range strings.SplitSeq(line, ",") {
+			if name = strings.TrimSpace(name); name != "" && name != "*" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+// cachingBody 在调用方读取响应体的同时保留一份副本, 读取到 EOF 时写入缓存
+// 响应体超过上限或未读完就关闭时不缓存
+type cachingBody struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	limit int64
+	over  bool
+	once  sync.Once
+	onEOF func(body []byte)
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && !b.over {
+		if int64(b.buf.Len()+n) > b.limit {
+			b.over = true
+			b.buf = bytes.Buffer{}
+		} else {
+			b.buf.Write(p[:n])
+		}
+	}
+	if err == io.EOF && !b.over {
+		b.once.Do(func() { b.onEOF(b.buf.Bytes()) })
+	}
+	return n, err
+}
@@ -0,0 +1,170 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCacheTestServer(t *testing.T, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		_, _ = io.WriteString(w, "response "+string(rune('0'+n)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func TestCacheServesFreshResponses(t *testing.T) {
+	server, hits := newCacheTestServer(t, http.Header{"Cache-Control": {"max-age=60"}})
+	clock := NewFakeClock(time.Now())
+	client := New(WithCache(CacheOptions{}), WithClock(clock))
+
+	for range 2 {
+		got, err := client.GET(server.URL).Text()
+		if err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+		if got != "response 1" {
+			t.Fatalf("body = %q, want %q", got, "response 1")
+		}
+	}
+	if hits.Load() != 1 {
+		t.Fatalf("server hits = %d, want 1", hits.Load())
+	}
+	if got := client.Metrics().CacheHits; got != 1 {
+		t.Fatalf("CacheHits = %d, want 1", got)
+	}
+
+	clock.Advance(30 * time.Second)
+	resp, err := client.GET(server.URL).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+	if age := resp.Header.Get("Age"); age != "30" && age != "31" {
+		t.Fatalf("Age = %q, want 30", age)
+	}
+	if resp.Header.Get(cacheResponseTimeHeader) != "" {
+		t.Fatal("internal cache header leaked into the response")
+	}
+
+	// 过期后重新请求
+	clock.Advance(31 * time.Second)
+	if got, _ := client.GET(server.URL).Text(); got != "response 2" {
+		t.Fatalf("body after expiry = %q, want %q", got, "response 2")
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		build  func(rb *RequestBuilder) *RequestBuilder
+	}{
+		{name: "no-store response", header: http.Header{"Cache-Control": {"no-store, max-age=60"}}},
+		{name: "no freshness", header: http.Header{"Etag": {`"v1"`}}},
+		{name: "vary star", header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}},
+		{
+			name:   "request no-cache",
+			header: http.Header{"Cache-Control": {"max-age=60"}},
+			build:  func(rb *RequestBuilder) *RequestBuilder { return rb.SetHeader("Cache-Control", "no-cache") },
+		},
+		{
+			name:   "authorization",
+			header: http.Header{"Cache-Control": {"max-age=60"}},
+			build:  func(rb *RequestBuilder) *RequestBuilder { return rb.SetHeader("Authorization", "Bearer x") },
+		},
+		{
+			name:   "vary mismatch",
+			header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept-Language"}},
+			build: func() func(rb *RequestBuilder) *RequestBuilder {
+				var n int
+				return func(rb *RequestBuilder) *RequestBuilder {
+					n++
+					return rb.SetHeader("Accept-Language", string(rune('a'+n)))
+				}
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, hits := newCacheTestServer(t, tt.header)
+			client := New(WithCache(CacheOptions{}))
+			for range 2 {
+				rb := client.GET(server.URL)
+				if tt.build != nil {
+					rb = tt.build(rb)
+				}
+				if _, err := rb.Text(); err != nil {
+					t.Fatalf("Text() error = %v", err)
+				}
+			}
+			if hits.Load() != 2 {
+				t.Fatalf("server hits = %d, want 2", hits.Load())
+			}
+		})
+	}
+}
+
+func TestCacheSkipsPartiallyReadBodies(t *testing.T) {
+	server, hits := newCacheTestServer(t, http.Header{"Cache-Control": {"max-age=60"}})
+	client := New(WithCache(CacheOptions{}))
+
+	resp, err := client.GET(server.URL).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := client.GET(server.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if hits.Load() != 2 {
+		t.Fatalf("server hits = %d, want 2", hits.Load())
+	}
+}
+
+func TestCacheKeyIncludesOverrides(t *testing.T) {
+	server, hits := newCacheTestServer(t, http.Header{"Cache-Control": {"max-age=60"}})
+	client := New(WithCache(CacheOptions{}))
+
+	if _, err := client.GET(server.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	got, err := client.GET(server.URL).SetHostHeader("other.example").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "response 2" || hits.Load() != 2 {
+		t.Fatalf("body = %q, hits = %d, want a fresh response for the overridden Host", got, hits.Load())
+	}
+}
+
+func TestCacheInvalidatedByUnsafeMethods(t *testing.T) {
+	server, hits := newCacheTestServer(t, http.Header{"Cache-Control": {"max-age=60"}})
+	client := New(WithCache(CacheOptions{}))
+
+	if _, err := client.GET(server.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if _, err := client.POST(server.URL).SetRawBody([]byte("x")).Text(); err != nil {
+		t.Fatalf("POST Text() error = %v", err)
+	}
+	got, err := client.GET(server.URL).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "response 3" || hits.Load() != 3 {
+		t.Fatalf("body = %q, hits = %d, want the cached entry invalidated by POST", got, hits.Load())
+	}
+}
@@ -0,0 +1,354 @@
+package httpc
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidRequestStruct 表示 Call 的请求结构体定义无效
+var ErrInvalidRequestStruct = errors.New("httpc: invalid request struct")
+
+// WithBaseURL 设置 Call 使用的基础地址, 相对的 path 定义 (例如 "/users/{id}") 拼接在其后.
+// 只作用于 Call, RequestBuilder 的 URL 原样使用
+func WithBaseURL(base string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(base, "/")
+	}
+}
+
+// resolveURL 将相对 URL 拼接到基础地址之后, 绝对 URL 原样返回
+func (c *Client) resolveURL(u string) string {
+	if c.baseURL == "" {
+		return u
+	}
+	if parsed, err := url.Parse(u); err == nil && parsed.IsAbs() {
+		return u
+	}
+	return c.baseURL + "/" + strings.TrimPrefix(u, "/")
+}
+
+// Call 根据带标签的结构体构建并执行请求, resp 按 DecodeAuto 的规则解码, 为 nil 时只检查状态码.
+//
+// 请求行定义在名为 "_" 的字段上, 其余导出字段通过标签映射到请求的各部分:
+//
+//	type UpdateUser struct {
+//		_      struct{} `httpc:"method=PATCH,path=/users/{id}"`
+//		ID     int      `path:"id"`
+//		DryRun bool     `query:"dry_run,omitempty"`
+//		Fields []string `query:"fields"`          // 多值参数按客户端的编码风格输出
+//		Token  string   `header:"Authorization"`
+//		Body   User     `body:"json"`             // json, xml, form (url.Values) 或 raw ([]byte, string, io.Reader)
+//	}
+//
+// 标量字段支持字符串, 数值, 布尔, encoding.TextMarshaler 及其指针 (nil 指针视为未设置)
+func (c *Client) Call(ctx context.Context, req any, resp any) error {
+	rb, err := c.CallRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	if resp != nil {
+		return rb.DecodeAuto(resp)
+	}
+
+	r, err := rb.Execute()
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 400 {
+		return c.errorResponse(r)
+	}
+	_, err = io.Copy(io.Discard, r.Body)
+	return err
+}
+
+// CallRequest 根据带标签的结构体构建 RequestBuilder 而不执行, 便于测试请求定义或追加设置
+func (c *Client) CallRequest(ctx context.Context, req any) (*RequestBuilder, error) {
+	rv := reflect.ValueOf(req)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: nil %T", ErrInvalidRequestStruct, req)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrInvalidRequestStruct, req)
+	}
+	spec, err := callSpecFor(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	path := spec.path
+	type queryValues struct {
+		name   string
+		values []string
+	}
+	var query []queryValues
+	header := make(http.Header)
+	var body reflect.Value
+	for _, f := range spec.fields {
+		fv := rv.FieldByIndex(f.index)
+		if f.kind == "body" {
+			body = fv
+			continue
+		}
+		values, err := formatCallValues(fv)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s field %s: %v", ErrInvalidRequestStruct, rv.Type(), f.field, err)
+		}
+		if len(values) == 0 || (f.omitempty && fv.IsZero()) {
+			if f.kind == "path" {
+				return nil, fmt.Errorf("%w: %s path parameter %q is not set", ErrInvalidRequestStruct, rv.Type(), f.name)
+			}
+			continue
+		}
+		switch f.kind {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+f.name+"}", url.PathEscape(values[0]))
+		case "query":
+			query = append(query, queryValues{name: f.name, values: values})
+		case "header":
+			for _, v := range values {
+				header.Add(f.name, v)
+			}
+		}
+	}
+
+	rb := c.NewRequestBuilder(spec.method, c.resolveURL(path))
+	if ctx != nil {
+		rb.WithContext(ctx)
+	}
+	for _, q := range query {
+		rb.SetQueryValues(q.name, q.values...)
+	}
+	for key, values := range header {
+		rb.header[key] = values
+	}
+	if body.IsValid() {
+		if err := setCallBody(rb, spec.bodyFormat, body); err != nil {
+			return nil, err
+		}
+	}
+	return rb, nil
+}
+
+// callSpec 是请求结构体类型解析后的定义
+type callSpec struct {
+	method     string
+	path       string
+	bodyFormat string
+	fields     []callField
+}
+
+// callField 是映射到请求某部分的字段
+type callField struct {
+	index     []int
+	field     string
+	kind      string // path, query, header, body
+	name      string
+	omitempty bool
+}
+
+var callSpecs sync.Map // reflect.Type -> *callSpec
+
+// callSpecFor 解析并缓存结构体类型的请求定义
+func callSpecFor(t reflect.Type) (*callSpec, error) {
+	if spec, ok := callSpecs.Load(t); ok {
+		return spec.(*callSpec), nil
+	}
+	spec, err := parseCallSpec(t)
+	if err != nil {
+		return nil, err
+	}
+	callSpecs.Store(t, spec)
+	return spec, nil
+}
+
+func parseCallSpec(t reflect.Type) (*callSpec, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s: %s", ErrInvalidRequestStruct, t, fmt.Sprintf(format, args...))
+	}
+
+	spec := &callSpec{method: http.MethodGet}
+	defined := false
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name == "_" {
+			tag, ok := field.Tag.Lookup("httpc")
+			if !ok {
+				continue
+			}
+			defined = true
+			for part := range strings.SplitSeq(tag, ",") {
+				key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+				switch key {
+				case "method":
+					spec.method = strings.ToUpper(value)
+				case "path":
+					spec.path = value
+				default:
+					return nil, invalid("unknown httpc tag option %q", key)
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		for _, kind := range []string{"path", "query", "header", "body"} {
+			tag, ok := field.Tag.Lookup(kind)
+			if !ok {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			f := callField{index: field.Index, field: field.Name, kind: kind, name: name, omitempty: opts == "omitempty"}
+			if kind == "body" {
+				if spec.bodyFormat != "" {
+					return nil, invalid("multiple body fields")
+				}
+				switch name {
+				case "json", "xml", "form", "raw":
+				default:
+					return nil, invalid("unknown body format %q on field %s", name, field.Name)
+				}
+				spec.bodyFormat = name
+			} else if name == "" {
+				f.name = field.Name
+			}
+			spec.fields = append(spec.fields, f)
+			break
+		}
+	}
+	if !defined || spec.path == "" {
+		return nil, invalid(`missing _ field with httpc:"method=...,path=..." tag`)
+	}
+
+	// path 中的每个占位符都需要对应的 path 字段
+	for rest := spec.path; ; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, invalid("unterminated placeholder in path %q", spec.path)
+		}
+		name := rest[start+1 : start+end]
+		found := false
+		for _, f := range spec.fields {
+			if f.kind == "path" && f.name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, invalid("no path field for placeholder {%s}", name)
+		}
+		rest = rest[start+end+1:]
+	}
+	return spec, nil
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// formatCallValues 将字段格式化为字符串, 切片字段返回多个值, nil 指针与 nil 切片返回空
+func formatCallValues(fv reflect.Value) ([]string, error) {
+	if fv.Kind() == reflect.Slice && !fv.Type().Implements(textMarshalerType) && fv.Type().Elem().Kind() != reflect.Uint8 {
+		values := make([]string, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			v, ok, err := formatCallValue(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	}
+	v, ok, err := formatCallValue(fv)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return []string{v}, nil
+}
+
+// formatCallValue 格式化单个标量值, 支持基础类型, 指针及 encoding.TextMarshaler
+func formatCallValue(fv reflect.Value) (string, bool, error) {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return "", false, nil
+		}
+		if fv.Type().Implements(textMarshalerType) {
+			break
+		}
+		fv = fv.Elem()
+	}
+	if fv.Type().Implements(textMarshalerType) {
+		text, err := fv.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err == nil, err
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()), true, nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(fv.Bytes()), true, nil
+		}
+	}
+	return "", false, fmt.Errorf("unsupported kind %s", fv.Kind())
+}
+
+// setCallBody 按 body 标签的格式设置请求体, nil 指针表示没有请求体
+func setCallBody(rb *RequestBuilder, format string, fv reflect.Value) error {
+	if (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface || fv.Kind() == reflect.Map || fv.Kind() == reflect.Slice) && fv.IsNil() {
+		return nil
+	}
+	v := fv.Interface()
+	var err error
+	switch format {
+	case "json":
+		_, err = rb.SetJSONBody(v)
+	case "xml":
+		_, err = rb.SetXMLBody(v)
+	case "form":
+		values, ok := v.(url.Values)
+		if !ok {
+			return fmt.Errorf("%w: form body must be url.Values, got %T", ErrInvalidRequestStruct, v)
+		}
+		rb.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+		rb.SetRawBody([]byte(values.Encode()))
+	case "raw":
+		switch body := v.(type) {
+		case []byte:
+			rb.SetRawBody(body)
+		case string:
+			rb.SetRawBody([]byte(body))
+		case io.Reader:
+			rb.SetBody(body)
+		default:
+			return fmt.Errorf("%w: raw body must be []byte, string or io.Reader, got %T", ErrInvalidRequestStruct, v)
+		}
+	}
+	return err
+}
@@ -0,0 +1,154 @@
+package httpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type callUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type updateUserCall struct {
+	_       struct{}   `httpc:"method=patch,path=/users/{id}"`
+	ID      string     `path:"id"`
+	DryRun  bool       `query:"dry_run,omitempty"`
+	Fields  []string   `query:"fields"`
+	Since   *time.Time `query:"since"`
+	Token   string     `header:"Authorization"`
+	Body    callUser   `body:"json"`
+	ignored string
+}
+
+func TestCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %q, want PATCH", r.Method)
+		}
+		if r.URL.EscapedPath() != "/v1/users/a%2Fb" {
+			t.Errorf("path = %q, want /v1/users/a%%2Fb", r.URL.EscapedPath())
+		}
+		if got := r.URL.Query()["fields"]; len(got) != 2 || got[0] != "id" || got[1] != "name" {
+			t.Errorf("fields = %v, want [id name]", got)
+		}
+		if r.URL.Query().Has("dry_run") || r.URL.Query().Has("since") {
+			t.Errorf("query = %q, want dry_run and since omitted", r.URL.RawQuery)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer t" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer t")
+		}
+		var in callUser
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Name != "bob" {
+			t.Errorf("body = %+v (%v), want name bob", in, err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":7,"name":"bob"}`)
+	}))
+	defer srv.Close()
+
+	client := New(WithBaseURL(srv.URL + "/v1/"))
+	var out callUser
+	req := updateUserCall{ID: "a/b", Fields: []string{"id", "name"}, Token: "Bearer t", Body: callUser{Name: "bob"}}
+	if err := client.Call(context.Background(), &req, &out); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if out.ID != 7 {
+		t.Fatalf("ID = %d, want 7", out.ID)
+	}
+}
+
+func TestCallRequest(t *testing.T) {
+	type searchCall struct {
+		_     struct{}   `httpc:"path=https://api.example.com/search"`
+		Q     string     `query:"q"`
+		Limit *int       `query:"limit"`
+		Page  uint       `query:"page,omitempty"`
+		Tags  url.Values `body:"form"`
+	}
+
+	limit := 10
+	rb, err := New().CallRequest(context.Background(), searchCall{Q: "go", Limit: &limit, Tags: url.Values{"t": {"x"}}})
+	if err != nil {
+		t.Fatalf("CallRequest() error = %v", err)
+	}
+	req, err := rb.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if req.Method != http.MethodGet {
+		t.Fatalf("Method = %q, want GET", req.Method)
+	}
+	if got := req.URL.String(); got != "https://api.example.com/search?limit=10&q=go" {
+		t.Fatalf("URL = %q, want %q", got, "https://api.example.com/search?limit=10&q=go")
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Fatalf("Content-Type = %q, want form", got)
+	}
+}
+
+func TestCallNoResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "missing", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	type deleteCall struct {
+		_  struct{} `httpc:"method=DELETE,path=/items/{id}"`
+		ID int      `path:"id"`
+	}
+	client := New(WithBaseURL(srv.URL), WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	err := client.Call(context.Background(), deleteCall{ID: 3}, nil)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("err = %v, want HTTPError 404", err)
+	}
+}
+
+func TestCallInvalidDefinition(t *testing.T) {
+	type noLine struct {
+		ID int `path:"id"`
+	}
+	type missingPath struct {
+		_ struct{} `httpc:"path=/users/{id}"`
+	}
+	type badBody struct {
+		_    struct{} `httpc:"path=/x"`
+		Body string   `body:"yaml"`
+	}
+	type badKind struct {
+		_ struct{}       `httpc:"path=/x"`
+		M map[string]int `query:"m"`
+	}
+
+	client := New()
+	for _, req := range []any{noLine{}, missingPath{}, badBody{}, badKind{M: map[string]int{"a": 1}}, 42, (*noLine)(nil)} {
+		if _, err := client.CallRequest(context.Background(), req); !errors.Is(err, ErrInvalidRequestStruct) {
+			t.Fatalf("CallRequest(%T) err = %v, want ErrInvalidRequestStruct", req, err)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	c := New(WithBaseURL("https://api.example.com/v1/"))
+	for in, want := range map[string]string{
+		"/users/1":                   "https://api.example.com/v1/users/1",
+		"users/1":                    "https://api.example.com/v1/users/1",
+		"/search?u=https://x":        "https://api.example.com/v1/search?u=https://x",
+		"https://other.example.com/": "https://other.example.com/",
+	} {
+		if got := c.resolveURL(in); got != want {
+			t.Errorf("resolveURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := c.GET("/users/1").url; got != "/users/1" {
+		t.Errorf("GET url = %q, want unchanged", got)
+	}
+}
@@ -0,0 +1,232 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+)
+
+// 分块上传默认配置
+const (
+	defaultUploadPartSize    = 8 << 20 // 8MB
+	defaultUploadConcurrency = 4
+	defaultUploadPartRetries = 3
+)
+
+// UploadPart 描述分块上传中的一个分块
+type UploadPart struct {
+	Number int    // 分块序号, 从 1 开始
+	Offset int64  // 分块在源数据中的偏移量
+	Size   int64  // 分块大小
+	ETag   string // 服务端返回的 ETag 响应头
+}
+
+// ChunkedUploadOptions 分块上传配置 (S3 multipart 风格)
+// Init / NewPartRequest / Complete 为必需的钩子, Abort 可选
+type ChunkedUploadOptions struct {
+	PartSize    int64 // 每个分块的大小, 默认 8MB
+	Concurrency int   // 并发上传的分块数, 默认 4
+	PartRetries int   // 单个分块在客户端重试之外的额外重试次数 (仅限可重试的失败), 默认 3, 小于 0 表示不额外重试
+
+	// Init 初始化上传, 返回上传 ID
+	Init func(ctx context.Context) (uploadID string, err error)
+	// NewPartRequest 为分块构建请求 (方法/URL/Header), 请求体由驱动填充
+	NewPartRequest func(ctx context.Context, uploadID string, part UploadPart) *RequestBuilder
+	// Complete 在全部分块成功后调用, parts 按序号升序排列
+	Complete func(ctx context.Context, uploadID string, parts []UploadPart) error
+	// Abort 在任一阶段失败后调用, 用于清理服务端的未完成上传
+	Abort func(ctx context.Context, uploadID string) error
+	// OnPartDone 在每个分块上传成功后调用 (可能被并发调用)
+	OnPartDone func(part UploadPart)
+}
+
+// ChunkedUploadFile 以分块方式并发上传文件
+func (c *Client) ChunkedUploadFile(ctx context.Context, filePath string, opts ChunkedUploadOptions) ([]UploadPart, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return c.ChunkedUpload(ctx, file, opts)
+}
+
+// ChunkedUpload 将 r 切分为多个分块并发上传
+// 内存占用上限约为 (Concurrency+1) * PartSize; 任一分块失败时取消其余分块并调用 Abort
+func (c *Client) ChunkedUpload(ctx context.Context, r io.Reader, opts ChunkedUploadOptions) ([]UploadPart, error) {
+	if opts.Init == nil || opts.NewPartRequest == nil || opts.Complete == nil {
+		return nil, errors.New("httpc: chunked upload requires Init, NewPartRequest and Complete hooks")
+	}
+	if opts.PartSize <= 0 {
+		opts.PartSize = defaultUploadPartSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultUploadConcurrency
+	}
+	if opts.PartRetries < 0 {
+		opts.PartRetries = 0
+	} else if opts.PartRetries == 0 {
+		opts.PartRetries = defaultUploadPartRetries
+	}
+
+	uploadID, err := opts.Init(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("httpc: chunked upload init: %w", err)
+	}
+
+	parts, err := c.uploadParts(ctx, r, uploadID, opts)
+	if err == nil {
+		err = opts.Complete(ctx, uploadID, parts)
+		if err != nil {
+			err = fmt.Errorf("httpc: chunked upload complete: %w", err)
+		}
+	}
+	if err != nil {
+		if opts.Abort != nil {
+			if abortErr := opts.Abort(context.WithoutCancel(ctx), uploadID); abortErr != nil {
+				err = errors.Join(err, fmt.Errorf("httpc: chunked upload abort: %w", abortErr))
+			}
+		}
+		return nil, err
+	}
+	return parts, nil
+}
+
+// uploadParts 顺序读取分块并分发给并发 worker 上传
+func (c *Client) uploadParts(ctx context.Context, r io.Reader, uploadID string, opts ChunkedUploadOptions) ([]UploadPart, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	type job struct {
+		part UploadPart
+		data []byte
+	}
+	jobs := make(chan job)
+	buffers := make(chan []byte, opts.Concurrency+1)
+	for range opts.Concurrency + 1 {
+		buffers <- make([]byte, opts.PartSize)
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []UploadPart
+		wg    sync.WaitGroup
+	)
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				part, err := c.uploadPart(ctx, uploadID, j.part, j.data, opts)
+				buffers <- j.data[:cap(j.data)]
+				if err != nil {
+					cancel(err)
+					continue
+				}
+				mu.Lock()
+				parts = append(parts, part)
+				mu.Unlock()
+				if opts.OnPartDone != nil {
+					opts.OnPartDone(part)
+				}
+			}
+		}()
+	}
+
+	var readErr error
+	var offset int64
+	for number := 1; ; number++ {
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if buf == nil {
+			break
+		}
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			part := UploadPart{Number: number, Offset: offset, Size: int64(n)}
+			offset += int64(n)
+			select {
+			case jobs <- job{part: part, data: buf[:n]}:
+			case <-ctx.Done():
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("httpc: chunked upload read part %d: %w", number, err)
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+// partRetryable 判断分块上传的失败是否可重试: 错误按客户端的错误类别策略判断,
+// 状态码按 RetryOptions.RetryStatuses 判断, 未配置时只重试 408, 429 与 5xx
+func (c *Client) partRetryable(resp *http.Response, err error) bool {
+	opts := c.settings().retryOpts
+	if err != nil || len(opts.RetryStatuses) > 0 {
+		return c.shouldRetry(&opts, resp, err)
+	}
+	return resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// uploadPart 上传单个分块, 失败时按客户端的退避策略重试
+func (c *Client) uploadPart(ctx context.Context, uploadID string, part UploadPart, data []byte, opts ChunkedUploadOptions) (UploadPart, error) {
+	var lastErr error
+	for attempt := 0; attempt <= opts.PartRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return part, context.Cause(ctx)
+			case <-c.clock.After(c.retryDelay(&c.settings().retryOpts, attempt-1, 0, nil)):
+			}
+		}
+
+		rb := opts.NewPartRequest(ctx, uploadID, part)
+		if rb == nil {
+			return part, fmt.Errorf("httpc: chunked upload part %d: nil request", part.Number)
+		}
+		resp, err := rb.WithContext(ctx).SetRawBody(data).Execute()
+		if err != nil {
+			// 按状态码重试耗尽时 Execute 同时返回响应与错误
+			if resp != nil {
+				resp.Body.Close()
+			}
+			lastErr = err
+			if !c.partRetryable(nil, err) {
+				break
+			}
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			lastErr = c.errorResponse(resp)
+			resp.Body.Close()
+			if !c.partRetryable(resp, nil) {
+				break
+			}
+			continue
+		}
+		part.ETag = resp.Header.Get("ETag")
+		resp.Body.Close()
+		return part, nil
+	}
+	return part, fmt.Errorf("httpc: chunked upload part %d: %w", part.Number, lastErr)
+}
@@ -0,0 +1,171 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientChunkedUploadRetriesPartsAndCompletesInOrder(t *testing.T) {
+	var mu sync.Mutex
+	received := map[int][]byte{}
+	failedOnce := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		number, _ := strconv.Atoi(r.URL.Query().Get("partNumber"))
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if number == 2 && !failedOnce {
+			failedOnce = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received[number] = body
+		w.Header().Set("ETag", `"etag-`+strconv.Itoa(number)+`"`)
+	}))
+	defer server.Close()
+
+	client := New(WithRetryOptions(RetryOptions{}))
+	payload := bytes.Repeat([]byte("abcdefghij"), 10)
+
+	var completed []UploadPart
+	parts, err := client.ChunkedUpload(context.Background(), bytes.NewReader(payload), ChunkedUploadOptions{
+		PartSize:    30,
+		Concurrency: 2,
+		Init:        func(ctx context.Context) (string, error) { return "upload-1", nil },
+		NewPartRequest: func(ctx context.Context, uploadID string, part UploadPart) *RequestBuilder {
+			return client.PUT(server.URL).
+				SetQueryParam("uploadId", uploadID).
+				SetQueryParam("partNumber", strconv.Itoa(part.Number))
+		},
+		Complete: func(ctx context.Context, uploadID string, parts []UploadPart) error {
+			completed = parts
+			return nil
+		},
+		Abort: func(ctx context.Context, uploadID string) error {
+			t.Error("Abort() called on successful upload")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("ChunkedUpload() error = %v", err)
+	}
+	if len(parts) != 4 || len(completed) != 4 {
+		t.Fatalf("parts = %d, completed = %d, want 4", len(parts), len(completed))
+	}
+
+	var assembled []byte
+	for i, part := range completed {
+		if part.Number != i+1 {
+			t.Fatalf("part[%d].Number = %d, want %d", i, part.Number, i+1)
+		}
+		if want := `"etag-` + strconv.Itoa(part.Number) + `"`; part.ETag != want {
+			t.Fatalf("part %d ETag = %q, want %q", part.Number, part.ETag, want)
+		}
+		assembled = append(assembled, received[part.Number]...)
+	}
+	if !bytes.Equal(assembled, payload) {
+		t.Fatalf("assembled = %q, want %q", assembled, payload)
+	}
+}
+
+func TestClientChunkedUploadAbortsOnPartFailure(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := New(WithRetryOptions(RetryOptions{}))
+	aborted := false
+	_, err := client.ChunkedUpload(context.Background(), bytes.NewReader(make([]byte, 100)), ChunkedUploadOptions{
+		PartSize: 100, // 单个分块; 403 不可重试, 即使 PartRetries 为默认值也只发送一次
+		Init:     func(ctx context.Context) (string, error) { return "upload-2", nil },
+		NewPartRequest: func(ctx context.Context, uploadID string, part UploadPart) *RequestBuilder {
+			return client.PUT(server.URL)
+		},
+		Complete: func(ctx context.Context, uploadID string, parts []UploadPart) error {
+			t.Error("Complete() called on failed upload")
+			return nil
+		},
+		Abort: func(ctx context.Context, uploadID string) error {
+			aborted = uploadID == "upload-2"
+			return nil
+		},
+	})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("ChunkedUpload() error = %v, want 403 HTTPError", err)
+	}
+	if !aborted {
+		t.Fatal("Abort() not called")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("part requests = %d, want 1 (4xx is not retried)", got)
+	}
+}
+
+// bodyCloseCounter 统计经过中间件的响应中未关闭的响应体数量
+func bodyCloseCounter(open *atomic.Int32) MiddlewareFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if resp != nil {
+				open.Add(1)
+				resp.Body = &countingCloser{ReadCloser: resp.Body, open: open}
+			}
+			return resp, err
+		})
+	}
+}
+
+type countingCloser struct {
+	io.ReadCloser
+	open   *atomic.Int32
+	closed atomic.Bool
+}
+
+func (c *countingCloser) Close() error {
+	if c.closed.CompareAndSwap(false, true) {
+		c.open.Add(-1)
+	}
+	return c.ReadCloser.Close()
+}
+
+func TestClientChunkedUploadClosesBodyWhenRetriesExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var open atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}),
+		WithMiddleware(bodyCloseCounter(&open)),
+	)
+	_, err := client.ChunkedUpload(context.Background(), bytes.NewReader(make([]byte, 10)), ChunkedUploadOptions{
+		PartSize:    10,
+		PartRetries: -1,
+		Init:        func(ctx context.Context) (string, error) { return "upload-3", nil },
+		NewPartRequest: func(ctx context.Context, uploadID string, part UploadPart) *RequestBuilder {
+			return client.PUT(server.URL)
+		},
+		Complete: func(ctx context.Context, uploadID string, parts []UploadPart) error { return nil },
+		Abort:    func(ctx context.Context, uploadID string) error { return nil },
+	})
+	if !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("ChunkedUpload() error = %v, want ErrMaxRetriesExceeded", err)
+	}
+	if n := open.Load(); n != 0 {
+		t.Fatalf("%d response bodies left open", n)
+	}
+}
@@ -36,23 +36,24 @@ func New(opts ...Option) *Client {
 			Timeout: 0, // 默认 Client Timeout 为 0，表示不超时，由 Request Context 控制
 		},
 		//transport:     transport,
-		retryOpts:     defaultRetryOptions(),
 		randomFloat64: rand.Float64,
-		bufferPool:    newDefaultPool(defaultBufferSize),
 		userAgent:     defaultUserAgent,
 		dumpLog:       nil, // 默认不启用日志
-		maxIdleConns:  defaultMaxIdleConns,
+		logLevel:      LogLevelInfo,
+		maxIdleConns:  maxIdleConns,
 		bufferSize:    defaultBufferSize,
 		maxBufferPool: defaultMaxBufferPool,
-		timeout:       0, // 默认不设置全局超时
 		middlewares:   []MiddlewareFunc{},
 		dialer:        dialer,
+		clock:         realClock{},
+
+		xmlCharsetReader: defaultXMLCharsetReader,
+		poolStats:        &poolStats{},
 	}
 
 	// 默认 Transport 配置
 	transport := &http.Transport{
 		Proxy:                  http.ProxyFromEnvironment,
-		DialContext:            dialer.DialContext,
 		MaxIdleConns:           maxIdleConns,
 		MaxIdleConnsPerHost:    maxIdleConns / 2,
 		MaxConnsPerHost:        0, // 默认为 0，表示无限制
@@ -70,22 +71,31 @@ func New(opts ...Option) *Client {
 
 	c.transport = transport
 	c.client.Transport = transport
-	if c.timeout != 0 { // 如果设置了全局超时，则更新 Client 的 Timeout
-		c.client.Timeout = c.timeout
-	}
+	// 默认重试策略, 不设置全局超时
+	c.live.Store(&liveConfig{retryOpts: defaultRetryOptions()})
 
 	for _, opt := range opts {
 		opt(c)
 		// 应用 Option 后，需要重新设置 Transport 到 Client，确保配置生效
 		c.client.Transport = c.transport
-		if c.timeout != 0 { // 如果设置了全局超时，则更新 Client 的 Timeout
-			c.client.Timeout = c.timeout
-		}
 	}
+	if c.jsonEngine == nil {
+		c.jsonEngine = &experimentJSON{marshalOpts: c.jsonMarshalOpts, unmarshalOpts: c.jsonUnmarshalOpts}
+	}
+	if c.bufferPool == nil {
+		c.bufferPool = newDefaultPool(c.bufferSize, c.maxBufferPool)
+	}
+	c.applyServerName()
+	c.installLiveHooks()
+	c.overrides = c.newOverrideTransports()
+	c.rebuildChain()
 
 	return c
 }
 
+// defaultMaxRetryAfter 是 Retry-After 的默认上限
+const defaultMaxRetryAfter = 5 * time.Minute
+
 // defaultRetryOptions 返回默认的重试策略
 func defaultRetryOptions() RetryOptions {
 	return RetryOptions{
@@ -99,16 +109,20 @@ func defaultRetryOptions() RetryOptions {
 
 // SetRetryOptions 动态设置重试选项
 func (c *Client) SetRetryOptions(opts RetryOptions) {
-	c.retryOpts = opts
+	c.updateLive(func(l *liveConfig) {
+		l.retryOpts = opts
+	})
 }
 
 // SetDumpLogFunc 动态设置日志记录函数
 func (c *Client) SetDumpLogFunc(dumpLog DumpLogFunc) {
 	c.dumpLog = dumpLog
+	c.rebuildChain()
 }
 
 // SetTimeout 动态设置客户端超时
 func (c *Client) SetTimeout(timeout time.Duration) {
-	c.timeout = timeout
-	c.client.Timeout = timeout // 同时更新 http.Client 的 Timeout
+	c.updateLive(func(l *liveConfig) {
+		l.timeout = timeout
+	})
 }
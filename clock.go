@@ -0,0 +1,107 @@
+package httpc
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 抽象客户端在重试, 退避与限速中使用的时间源
+// 测试中可以注入 FakeClock, 在不真实休眠的情况下验证重试与退避行为
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// WithClock 设置客户端使用的时间源, nil 恢复系统时间
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		if clock == nil {
+			clock = realClock{}
+		}
+		c.clock = clock
+	}
+}
+
+// realClock 使用系统时间
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// FakeClock 是一个手动推进的 Clock, 仅在调用 Advance 时流逝时间
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+	changed chan struct{} // 等待者数量变化时关闭并重建, 用于 BlockUntil
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock 创建一个从 now 开始的 FakeClock
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, changed: make(chan struct{})}
+}
+
+// Now 返回当前的虚拟时间
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After 返回一个在虚拟时间推进 d 之后触发的 channel
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, fakeWaiter{deadline: f.now.Add(d), ch: ch})
+	f.notify()
+	return ch
+}
+
+// Advance 将虚拟时间推进 d, 并触发所有到期的等待者
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	clear(f.waiters[len(remaining):])
+	f.waiters = remaining
+	f.notify()
+}
+
+// BlockUntil 阻塞直到至少有 n 个 After 等待者, 用于在 Advance 之前与被测代码同步
+func (f *FakeClock) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		count, changed := len(f.waiters), f.changed
+		f.mu.Unlock()
+		if count >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// notify 唤醒 BlockUntil, 调用方需持有 f.mu
+func (f *FakeClock) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
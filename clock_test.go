@@ -0,0 +1,96 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFakeClockAdvanceFiresDueWaiters(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	short, long := clock.After(time.Second), clock.After(time.Minute)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case got := <-short:
+		if !got.Equal(start.Add(30 * time.Second)) {
+			t.Fatalf("short fired at %v, want %v", got, start.Add(30*time.Second))
+		}
+	default:
+		t.Fatal("short waiter did not fire")
+	}
+	select {
+	case <-long:
+		t.Fatal("long waiter fired early")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-long:
+	default:
+		t.Fatal("long waiter did not fire")
+	}
+}
+
+func TestRetryUsesInjectedClock(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	clock := NewFakeClock(time.Now())
+	client := New(
+		WithClock(clock),
+		WithRetryOptions(RetryOptions{
+			MaxAttempts:   2,
+			BaseDelay:     time.Hour,
+			MaxDelay:      time.Hour,
+			RetryStatuses: []int{http.StatusServiceUnavailable},
+		}),
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		body, err := client.GET(server.URL).Text()
+		if err == nil && body != "ok" {
+			t.Errorf("Text() = %q, want %q", body, "ok")
+		}
+		done <- err
+	}()
+
+	for range 2 {
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not finish after advancing the fake clock")
+	}
+	if got := hits.Load(); got != 3 {
+		t.Fatalf("hits = %d, want 3", got)
+	}
+}
+
+func TestRetryAfterDateUsesClock(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := New(WithClock(NewFakeClock(now)))
+	resp := &http.Response{Header: http.Header{"Retry-After": {now.Add(90 * time.Second).Format(http.TimeFormat)}}}
+
+	got, ok := client.retryAfter(&client.settings().retryOpts, resp)
+	if !ok || got != 90*time.Second {
+		t.Fatalf("retryAfter() = %v, %v, want 90s", got, ok)
+	}
+}
@@ -0,0 +1,155 @@
+package httpc
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+)
+
+// Decoder 响应体解码器, 按 Content-Type 注册到客户端的编解码注册表中
+type Decoder interface {
+	Decode(r io.Reader, v any) error
+}
+
+// DecoderFunc 是一个适配器, 允许使用普通函数作为 Decoder
+type DecoderFunc func(r io.Reader, v any) error
+
+// Decode 实现了 Decoder 接口
+func (f DecoderFunc) Decode(r io.Reader, v any) error {
+	return f(r, v)
+}
+
+// 默认的回退解码顺序, 在响应缺少 Content-Type 或类型未注册时使用
+var defaultDecodeFallback = []string{"application/json", "application/xml"}
+
+// WithDecoder 为指定媒体类型注册解码器, 覆盖内置实现
+// mediaType 不含参数, 例如 "application/msgpack"
+func WithDecoder(mediaType string, decoder Decoder) Option {
+	return func(c *Client) {
+		if c.decoders == nil {
+			c.decoders = make(map[string]Decoder)
+		}
+		c.decoders[strings.ToLower(mediaType)] = decoder
+	}
+}
+
+// WithDecodeFallback 设置 DecodeAuto 的回退解码顺序
+// 当响应 Content-Type 缺失或没有匹配的解码器, 且请求未通过 Accept 指定顺序时生效
+func WithDecodeFallback(mediaTypes ...string) Option {
+	return func(c *Client) {
+		c.decodeFallback = mediaTypes
+	}
+}
+
+// Accept 设置 Accept 头, 并记录 DecodeAuto 的回退解码顺序
+func (rb *RequestBuilder) Accept(mediaTypes ...string) *RequestBuilder {
+	rb.accept = mediaTypes
+	rb.header.Set("Accept", strings.Join(mediaTypes, ", "))
+	return rb
+}
+
+// DecodeAuto 根据响应 Content-Type 从注册表中选择解码器
+// 若无匹配的解码器, 则依次尝试 Accept 声明的类型 (或客户端配置的回退顺序)
+func (rb *RequestBuilder) DecodeAuto(v any) error {
+	resp, err := rb.Execute()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return rb.client.decodeAutoResponse(resp, rb.accept, v)
+}
+
+// decodeAutoResponse 按 Content-Type 解码响应, 无法识别时依次尝试 accept (为空时使用客户端回退顺序)
+func (c *Client) decodeAutoResponse(resp *http.Response, accept []string, v any) error {
+	if resp.StatusCode >= 400 {
+		return c.errorResponse(resp)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if decoder := c.decoderFor(mediaType); decoder != nil {
+			if err := decoder.Decode(resp.Body, v); err != nil {
+				return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+			}
+			return nil
+		}
+	}
+
+	fallback := accept
+	if len(fallback) == 0 {
+		fallback = c.decodeFallback
+	}
+	if len(fallback) == 0 {
+		fallback = defaultDecodeFallback
+	}
+
+	buf, err := c.readPooled(resp)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+	defer c.bufferPool.Put(buf)
+	body := buf.Bytes()
+	var lastErr error
+	for _, mediaType := range fallback {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		decoder := c.decoderFor(strings.TrimSpace(mediaType))
+		if decoder == nil {
+			continue
+		}
+		if lastErr = decoder.Decode(bytes.NewReader(body), v); lastErr == nil {
+			return nil
+		}
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, lastErr)
+	}
+	return fmt.Errorf("%w: no decoder for Content-Type %q", ErrDecodeResponse, contentType)
+}
+
+// decoderFor 查找媒体类型对应的解码器, 用户注册的解码器优先于内置实现
+func (c *Client) decoderFor(mediaType string) Decoder {
+	mediaType = strings.ToLower(mediaType)
+	if decoder, ok := c.decoders[mediaType]; ok {
+		return decoder
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return DecoderFunc(c.unmarshalJSONReader)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return DecoderFunc(func(r io.Reader, v any) error {
+			return c.newXMLDecoder(r).Decode(v)
+		})
+	case mediaType == "application/x-www-form-urlencoded":
+		return DecoderFunc(decodeForm)
+	case mediaType == "application/x-gob":
+		return DecoderFunc(func(r io.Reader, v any) error {
+			return gob.NewDecoder(r).Decode(v)
+		})
+	}
+	return nil
+}
+
+// decodeForm 解析表单数据, v 可以是 *url.Values 或结构体指针
+func decodeForm(r io.Reader, v any) error {
+	body, err := iox.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return err
+	}
+	if target, ok := v.(*url.Values); ok {
+		*target = values
+		return nil
+	}
+	return bindForm(values, v)
+}
@@ -0,0 +1,80 @@
+package httpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilderDecodeAutoSelectsDecoderByContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "application/json, application/xml" {
+			t.Errorf("Accept = %q, want negotiated list", got)
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		_, _ = io.WriteString(w, `<user><name>touka</name></user>`)
+	}))
+	defer server.Close()
+
+	var user struct {
+		Name string `json:"name" xml:"name"`
+	}
+	err := New().GET(server.URL).Accept("application/json", "application/xml").DecodeAuto(&user)
+	if err != nil {
+		t.Fatalf("DecodeAuto() error = %v", err)
+	}
+	if user.Name != "touka" {
+		t.Fatalf("Name = %q, want touka", user.Name)
+	}
+}
+
+func TestRequestBuilderDecodeAutoFallsBackInAcceptOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Content-Type"] = nil
+		_, _ = io.WriteString(w, `<user><name>touka</name></user>`)
+	}))
+	defer server.Close()
+
+	var user struct {
+		Name string `json:"name" xml:"name"`
+	}
+	if err := New().GET(server.URL).Accept("application/json", "application/xml").DecodeAuto(&user); err != nil {
+		t.Fatalf("DecodeAuto() error = %v", err)
+	}
+	if user.Name != "touka" {
+		t.Fatalf("Name = %q, want touka", user.Name)
+	}
+
+	err := New(WithDecodeFallback("application/json")).GET(server.URL).DecodeAuto(&user)
+	if !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("DecodeAuto() with json-only fallback error = %v, want ErrDecodeResponse", err)
+	}
+}
+
+func TestWithDecoderRegistersCustomMediaType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = io.WriteString(w, "a,b,c")
+	}))
+	defer server.Close()
+
+	client := New(WithDecoder("text/csv", DecoderFunc(func(r io.Reader, v any) error {
+		body, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		*(v.(*[]string)) = strings.Split(string(body), ",")
+		return nil
+	})))
+
+	var fields []string
+	if err := client.GET(server.URL).DecodeAuto(&fields); err != nil {
+		t.Fatalf("DecodeAuto() error = %v", err)
+	}
+	if len(fields) != 3 || fields[2] != "c" {
+		t.Fatalf("fields = %#v, want [a b c]", fields)
+	}
+}
@@ -0,0 +1,357 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// ErrInvalidConfig 配置无效
+var ErrInvalidConfig = errors.New("httpc: invalid config")
+
+// Duration 是以 "1s", "500ms" 等字符串表示的 time.Duration, 用于配置文件
+type Duration time.Duration
+
+// MarshalText 实现 encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Config 是客户端的声明式配置, 可直接从 JSON/TOML 等配置文件解码
+// 零值字段保持 New 的默认配置
+type Config struct {
+	Timeout               Duration `json:"timeout,omitempty" toml:"timeout"`
+	DialTimeout           Duration `json:"dial_timeout,omitempty" toml:"dial_timeout"`
+	KeepAliveTimeout      Duration `json:"keep_alive_timeout,omitempty" toml:"keep_alive_timeout"`
+	TLSHandshakeTimeout   Duration `json:"tls_handshake_timeout,omitempty" toml:"tls_handshake_timeout"`
+	ExpectContinueTimeout Duration `json:"expect_continue_timeout,omitempty" toml:"expect_continue_timeout"`
+	IdleConnTimeout       Duration `json:"idle_conn_timeout,omitempty" toml:"idle_conn_timeout"`
+	ResponseHeaderTimeout Duration `json:"response_header_timeout,omitempty" toml:"response_header_timeout"`
+
+	MaxIdleConns        int    `json:"max_idle_conns,omitempty" toml:"max_idle_conns"`
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host,omitempty" toml:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int    `json:"max_conns_per_host,omitempty" toml:"max_conns_per_host"`
+	BufferSize          int    `json:"buffer_size,omitempty" toml:"buffer_size"`
+	ReadBufferSize      int    `json:"read_buffer_size,omitempty" toml:"read_buffer_size"`   // Transport 读缓冲区大小
+	WriteBufferSize     int    `json:"write_buffer_size,omitempty" toml:"write_buffer_size"` // Transport 写缓冲区大小
+	UserAgent           string `json:"user_agent,omitempty" toml:"user_agent"`
+	TransportShards     int    `json:"transport_shards,omitempty" toml:"transport_shards"` // >0 时按主机分片 Transport, 值为分片上限
+
+	DisableCompression bool `json:"disable_compression,omitempty" toml:"disable_compression"`
+	DisableKeepAlives  bool `json:"disable_keep_alives,omitempty" toml:"disable_keep_alives"`
+
+	// Proxy 代理地址, 支持 http://, https://, socks5://
+	Proxy string `json:"proxy,omitempty" toml:"proxy"`
+
+	Retry     *RetryConfig     `json:"retry,omitempty" toml:"retry"`
+	DNS       *DNSConfig       `json:"dns,omitempty" toml:"dns"`
+	TLS       *TLSConfig       `json:"tls,omitempty" toml:"tls"`
+	Protocols *ProtocolsConfig `json:"protocols,omitempty" toml:"protocols"`
+	RateLimit *RateLimit       `json:"rate_limit,omitempty" toml:"rate_limit"`
+}
+
+// RetryConfig 重试配置, 零值字段使用默认重试策略
+type RetryConfig struct {
+	Disabled bool `json:"disabled,omitempty" toml:"disabled"` // 为 true 时不重试, 其余字段被忽略
+
+	MaxAttempts    int      `json:"max_attempts,omitempty" toml:"max_attempts"`
+	BaseDelay      Duration `json:"base_delay,omitempty" toml:"base_delay"`
+	MaxDelay       Duration `json:"max_delay,omitempty" toml:"max_delay"`
+	RetryStatuses  []int    `json:"retry_statuses,omitempty" toml:"retry_statuses"`
+	Jitter         bool     `json:"jitter,omitempty" toml:"jitter"`
+	JitterStrategy string   `json:"jitter_strategy,omitempty" toml:"jitter_strategy"` // proportional, full, decorrelated
+	MaxRetryAfter  Duration `json:"max_retry_after,omitempty" toml:"max_retry_after"`
+
+	// RetryErrors 按错误类别 (dns, conn_refused, conn_reset, tls, timeout, network, proxy) 覆盖是否重试
+	RetryErrors map[string]bool `json:"retry_errors,omitempty" toml:"retry_errors"`
+	// StatusDelays 按状态码 (以字符串为键, 例如 "429") 覆盖退避策略
+	StatusDelays map[string]RetryDelayConfig `json:"status_delays,omitempty" toml:"status_delays"`
+}
+
+// RetryDelayConfig 单个状态码的退避配置
+type RetryDelayConfig struct {
+	BaseDelay Duration `json:"base_delay,omitempty" toml:"base_delay"`
+	MaxDelay  Duration `json:"max_delay,omitempty" toml:"max_delay"`
+	Fixed     bool     `json:"fixed,omitempty" toml:"fixed"`
+}
+
+// DNSConfig 自定义 DNS 配置
+type DNSConfig struct {
+	Servers []string `json:"servers" toml:"servers"`
+	Timeout Duration `json:"timeout,omitempty" toml:"timeout"`
+}
+
+// TLSConfig TLS 配置
+type TLSConfig struct {
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" toml:"insecure_skip_verify"`
+	ServerName         string `json:"server_name,omitempty" toml:"server_name"`
+	CAFile             string `json:"ca_file,omitempty" toml:"ca_file"`         // PEM 格式的 CA 证书, 追加到系统根证书
+	CertFile           string `json:"cert_file,omitempty" toml:"cert_file"`     // 客户端证书
+	KeyFile            string `json:"key_file,omitempty" toml:"key_file"`       // 客户端私钥
+	MinVersion         string `json:"min_version,omitempty" toml:"min_version"` // 1.0, 1.1, 1.2, 1.3
+}
+
+// NewFromConfig 根据配置创建客户端, extra 中的 Option 在配置之后应用
+func NewFromConfig(cfg Config, extra ...Option) (*Client, error) {
+	opts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return New(append(opts, extra...)...), nil
+}
+
+// Options 将配置转换为等价的 Option 列表
+func (cfg Config) Options() ([]Option, error) {
+	var opts []Option
+
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(time.Duration(cfg.Timeout)))
+	}
+	if cfg.DialTimeout > 0 {
+		opts = append(opts, WithDialTimeout(time.Duration(cfg.DialTimeout)))
+	}
+	if cfg.KeepAliveTimeout > 0 {
+		opts = append(opts, WithKeepAliveTimeout(time.Duration(cfg.KeepAliveTimeout)))
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		opts = append(opts, WithTLSHandshakeTimeout(time.Duration(cfg.TLSHandshakeTimeout)))
+	}
+	if cfg.ExpectContinueTimeout > 0 {
+		opts = append(opts, WithExpectContinueTimeout(time.Duration(cfg.ExpectContinueTimeout)))
+	}
+	if cfg.IdleConnTimeout > 0 {
+		opts = append(opts, WithIdleConnTimeout(time.Duration(cfg.IdleConnTimeout)))
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		opts = append(opts, WithResponseHeaderTimeout(time.Duration(cfg.ResponseHeaderTimeout)))
+	}
+	if cfg.MaxIdleConns > 0 {
+		opts = append(opts, WithMaxIdleConns(cfg.MaxIdleConns))
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		opts = append(opts, WithMaxIdleConnsPerHost(cfg.MaxIdleConnsPerHost))
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		opts = append(opts, WithMaxConnsPerHost(cfg.MaxConnsPerHost))
+	}
+	if cfg.BufferSize > 0 {
+		opts = append(opts, WithBufferSize(cfg.BufferSize))
+	}
+	if cfg.ReadBufferSize > 0 || cfg.WriteBufferSize > 0 {
+		opts = append(opts, WithTransportBufferSizes(cfg.ReadBufferSize, cfg.WriteBufferSize))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, WithUserAgent(cfg.UserAgent))
+	}
+	if cfg.TransportShards > 0 {
+		opts = append(opts, WithTransportShards(cfg.TransportShards, nil))
+	}
+	if cfg.DisableCompression {
+		opts = append(opts, WithDisableCompression())
+	}
+	if cfg.DisableKeepAlives {
+		opts = append(opts, WithDisableKeepAlives())
+	}
+
+	if cfg.Retry != nil {
+		retryOpts, err := cfg.Retry.retryOptions()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithRetryOptions(retryOpts))
+	}
+	if cfg.DNS != nil && len(cfg.DNS.Servers) > 0 {
+		opts = append(opts, WithDNSResolver(cfg.DNS.Servers, time.Duration(cfg.DNS.Timeout)))
+	}
+	if cfg.Proxy != "" {
+		opt, err := proxyOption(cfg.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, opt)
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithTLSConfig(tlsConfig))
+	}
+	if cfg.Protocols != nil {
+		opts = append(opts, WithProtocols(*cfg.Protocols))
+	}
+	if cfg.RateLimit != nil {
+		opts = append(opts, WithRateLimit(*cfg.RateLimit))
+	}
+	return opts, nil
+}
+
+// proxyOption 根据代理地址的 scheme 选择代理 Option
+func proxyOption(proxyURL string) (Option, error) {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if isSocks5Scheme(u.Scheme) {
+		if _, err := proxy.FromURL(u, proxy.Direct); err != nil {
+			return nil, fmt.Errorf("%w: proxy: %v", ErrInvalidConfig, err)
+		}
+		return WithSocks5Proxy(proxyURL), nil
+	}
+	return WithHTTPProxy(proxyURL), nil
+}
+
+// errMalformedProxyURL 代替 url.Parse 的错误, 后者包含原始地址, 可能泄漏代理凭据
+var errMalformedProxyURL = errors.New("malformed proxy URL")
+
+// parseProxyURL 解析并校验代理地址
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: proxy: %v", ErrInvalidConfig, errMalformedProxyURL)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported proxy scheme %q", ErrInvalidConfig, u.Scheme)
+	}
+}
+
+func isSocks5Scheme(scheme string) bool {
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// retryOptions 将重试配置合并到默认重试策略上
+func (rc *RetryConfig) retryOptions() (RetryOptions, error) {
+	opts := defaultRetryOptions()
+	if rc.Disabled {
+		opts.MaxAttempts = 0
+		return opts, nil
+	}
+	if rc.MaxAttempts != 0 {
+		opts.MaxAttempts = rc.MaxAttempts
+	}
+	if rc.BaseDelay > 0 {
+		opts.BaseDelay = time.Duration(rc.BaseDelay)
+	}
+	if rc.MaxDelay > 0 {
+		opts.MaxDelay = time.Duration(rc.MaxDelay)
+	}
+	if rc.RetryStatuses != nil {
+		opts.RetryStatuses = rc.RetryStatuses
+	}
+	opts.Jitter = rc.Jitter
+	opts.MaxRetryAfter = time.Duration(rc.MaxRetryAfter)
+
+	switch rc.JitterStrategy {
+	case "", "proportional":
+		opts.JitterStrategy = JitterProportional
+	case "full":
+		opts.JitterStrategy = JitterFull
+	case "decorrelated":
+		opts.JitterStrategy = JitterDecorrelated
+	default:
+		return opts, fmt.Errorf("%w: unknown jitter strategy %q", ErrInvalidConfig, rc.JitterStrategy)
+	}
+
+	if len(rc.RetryErrors) > 0 {
+		opts.RetryErrors = make(map[ErrorClass]bool, len(rc.RetryErrors))
+		for name, retry := range rc.RetryErrors {
+			class, ok := errorClassByName(name)
+			if !ok {
+				return opts, fmt.Errorf("%w: unknown error class %q", ErrInvalidConfig, name)
+			}
+			opts.RetryErrors[class] = retry
+		}
+	}
+
+	if len(rc.StatusDelays) > 0 {
+		opts.StatusDelays = make(map[int]RetryDelay, len(rc.StatusDelays))
+		for code, delay := range rc.StatusDelays {
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				return opts, fmt.Errorf("%w: invalid status code %q", ErrInvalidConfig, code)
+			}
+			opts.StatusDelays[status] = RetryDelay{
+				BaseDelay: time.Duration(delay.BaseDelay),
+				MaxDelay:  time.Duration(delay.MaxDelay),
+				Fixed:     delay.Fixed,
+			}
+		}
+	}
+	return opts, nil
+}
+
+// errorClassByName 根据 ErrorClass.String 的结果查找错误类别
+func errorClassByName(name string) (ErrorClass, bool) {
+	for class := ErrorClassDNS; class <= ErrorClassBodyRead; class++ {
+		if class.String() == name {
+			return class, true
+		}
+	}
+	return ErrorClassUnknown, false
+}
+
+// tlsConfig 根据配置构建 *tls.Config
+func (tc *TLSConfig) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: tc.InsecureSkipVerify,
+		ServerName:         tc.ServerName,
+	}
+
+	switch tc.MinVersion {
+	case "":
+	case "1.0":
+		config.MinVersion = tls.VersionTLS10
+	case "1.1":
+		config.MinVersion = tls.VersionTLS11
+	case "1.2":
+		config.MinVersion = tls.VersionTLS12
+	case "1.3":
+		config.MinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("%w: unknown TLS version %q", ErrInvalidConfig, tc.MinVersion)
+	}
+
+	if tc.CAFile != "" {
+		pem, err := os.ReadFile(tc.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: read CA file: %v", ErrInvalidConfig, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificates found in %s", ErrInvalidConfig, tc.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if tc.CertFile != "" || tc.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tc.CertFile, tc.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: load client certificate: %v", ErrInvalidConfig, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,127 @@
+package httpc
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-json-experiment/json"
+)
+
+func TestNewFromConfigJSON(t *testing.T) {
+	data := `{
+		"timeout": "30s",
+		"idle_conn_timeout": "2m",
+		"response_header_timeout": "15s",
+		"user_agent": "config-test/1.0",
+		"proxy": "http://proxy.example.com:8080",
+		"retry": {
+			"max_attempts": 4,
+			"base_delay": "50ms",
+			"jitter": true,
+			"jitter_strategy": "full",
+			"retry_errors": {"timeout": false},
+			"status_delays": {"429": {"base_delay": "10s", "fixed": true}}
+		},
+		"tls": {"server_name": "internal.example.com", "min_version": "1.2"},
+		"protocols": {"http1": true},
+		"rate_limit": {"requests_per_second": 5, "burst": 2, "per_host": true}
+	}`
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	client, err := NewFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+
+	if got := client.settings().timeout; got != 30*time.Second {
+		t.Fatalf("timeout = %v, want %v", got, 30*time.Second)
+	}
+	if client.transport.IdleConnTimeout != 2*time.Minute {
+		t.Fatalf("IdleConnTimeout = %v, want %v", client.transport.IdleConnTimeout, 2*time.Minute)
+	}
+	if client.transport.ResponseHeaderTimeout != 15*time.Second {
+		t.Fatalf("ResponseHeaderTimeout = %v, want %v", client.transport.ResponseHeaderTimeout, 15*time.Second)
+	}
+	if client.userAgent != "config-test/1.0" {
+		t.Fatalf("userAgent = %q, want %q", client.userAgent, "config-test/1.0")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	proxyURL, err := client.transport.Proxy(req)
+	if err != nil || proxyURL == nil || proxyURL.Host != "proxy.example.com:8080" {
+		t.Fatalf("Proxy() = %v, %v, want proxy.example.com:8080", proxyURL, err)
+	}
+
+	retry := client.settings().retryOpts
+	if retry.MaxAttempts != 4 || retry.BaseDelay != 50*time.Millisecond || retry.MaxDelay != time.Second {
+		t.Fatalf("retry = %+v, want MaxAttempts 4, BaseDelay 50ms, default MaxDelay", retry)
+	}
+	if !retry.Jitter || retry.JitterStrategy != JitterFull {
+		t.Fatalf("jitter = %v/%v, want true/JitterFull", retry.Jitter, retry.JitterStrategy)
+	}
+	if retry.RetryErrors[ErrorClassTimeout] {
+		t.Fatal("RetryErrors[timeout] = true, want false")
+	}
+	if d := retry.StatusDelays[429]; d.BaseDelay != 10*time.Second || !d.Fixed {
+		t.Fatalf("StatusDelays[429] = %+v, want fixed 10s", d)
+	}
+
+	if tc := client.transport.TLSClientConfig; tc == nil || tc.ServerName != "internal.example.com" || tc.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("TLSClientConfig = %+v, want ServerName and TLS 1.2", tc)
+	}
+	if client.transport.Protocols.HTTP2() {
+		t.Fatal("Protocols.HTTP2() = true, want false")
+	}
+	if limiter := client.settings().rateLimiter; limiter == nil || !limiter.limit.PerHost {
+		t.Fatal("rate limiter not configured")
+	}
+}
+
+func TestRetryConfigDisabled(t *testing.T) {
+	client, err := NewFromConfig(Config{Retry: &RetryConfig{Disabled: true, MaxAttempts: 5}})
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	if got := client.settings().retryOpts.MaxAttempts; got != 0 {
+		t.Fatalf("MaxAttempts = %d, want 0", got)
+	}
+}
+
+func TestWithSocks5ProxyInvalidURLFailsRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(WithSocks5Proxy("socks5://user:secret@[::1"), WithRetryOptions(RetryOptions{}))
+	_, err := client.GET(server.URL).Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid SOCKS5 proxy") {
+		t.Fatalf("Execute() error = %v, want invalid SOCKS5 proxy error instead of a direct connection", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Fatalf("Execute() error = %v, leaks proxy credentials", err)
+	}
+}
+
+func TestNewFromConfigRejectsInvalidValues(t *testing.T) {
+	tests := []Config{
+		{Proxy: "ftp://proxy.example.com"},
+		{Retry: &RetryConfig{JitterStrategy: "sometimes"}},
+		{Retry: &RetryConfig{RetryErrors: map[string]bool{"cosmic_ray": true}}},
+		{Retry: &RetryConfig{StatusDelays: map[string]RetryDelayConfig{"5xx": {}}}},
+		{TLS: &TLSConfig{MinVersion: "0.9"}},
+		{TLS: &TLSConfig{CAFile: "does-not-exist.pem"}},
+	}
+
+	for i, cfg := range tests {
+		if _, err := NewFromConfig(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("case %d: NewFromConfig() error = %v, want ErrInvalidConfig", i, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package httpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnInfo 描述一次连接事件
+// 经由 HTTP 代理或 SOCKS5 代理时, Addr 与 RemoteAddr 为代理的地址
+type ConnInfo struct {
+	Addr       string               // 拨号目标 (host:port)
+	LocalAddr  net.Addr             // 本地地址, 拨号失败时为 nil
+	RemoteAddr net.Addr             // 实际连接的对端地址, 拨号失败时为 nil
+	Protocol   string               // ALPN 协商的协议 ("h2", "http/1.1"), 明文连接或尚未握手时为空
+	TLS        *tls.ConnectionState // 仅 OnTLSDone 设置
+	Duration   time.Duration        // OnConnect: 拨号耗时; OnTLSDone: 握手耗时; OnConnReused: 空闲时长; OnConnClosed: 连接存活时长
+	Err        error                // OnConnect 与 OnTLSDone 失败时的错误
+}
+
+// ConnectionHooks 连接生命周期回调, 未设置的回调会被忽略
+// 回调在 Transport 的拨号与读写路径上同步调用, 需要并发安全并尽快返回
+type ConnectionHooks struct {
+	OnConnect    func(ConnInfo) // TCP 连接建立 (或失败) 后调用
+	OnTLSDone    func(ConnInfo) // TLS 握手完成 (或失败) 后调用
+	OnConnReused func(ConnInfo) // 请求复用已有连接时调用, HTTP/2 下每个复用连接的请求都会调用
+	OnConnClosed func(ConnInfo) // 连接关闭时调用一次
+}
+
+// WithConnectionHooks 设置连接生命周期回调, 用于记录客户端实际连接的 IP 与协议, 以及排查连接复用问题
+// 不经过 Transport 拨号的连接 (Tunnel, 自定义 WithTransport 的 DialTLSContext) 不会触发
+func WithConnectionHooks(hooks ConnectionHooks) Option {
+	return func(c *Client) {
+		c.connHooks = &hooks
+	}
+}
+
+// connHookState 在同一次请求尝试的拨号与 httptrace 事件之间传递新建的连接
+type connHookState struct {
+	conn atomic.Pointer[hookedConn]
+}
+
+type connHookStateKey struct{}
+
+// hookDial 包装拨号函数, 触发 OnConnect 并让返回的连接在关闭时触发 OnConnClosed
+func (c *Client) hookDial(ctx context.Context, network, addr string, dial dialFunc) (net.Conn, error) {
+	hooks := c.connHooks
+	start := time.Now()
+	conn, err := dial(ctx, network, addr)
+	if err != nil {
+		if hooks.OnConnect != nil {
+			hooks.OnConnect(ConnInfo{Addr: addr, Duration: time.Since(start), Err: err})
+		}
+		return nil, err
+	}
+
+	hc := &hookedConn{Conn: conn, hooks: hooks, addr: addr, connectedAt: time.Now()}
+	if hooks.OnConnect != nil {
+		hooks.OnConnect(hc.info(hc.connectedAt.Sub(start)))
+	}
+	if state, ok := ctx.Value(connHookStateKey{}).(*connHookState); ok {
+		state.conn.Store(hc)
+	}
+	return hc, nil
+}
+
+// connHooksRoundTripper 是一个内部中间件, 通过 httptrace 触发 OnTLSDone 与 OnConnReused
+func (c *Client) connHooksRoundTripper(next http.RoundTripper) http.RoundTripper {
+	hooks := c.connHooks
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		state := &connHookState{}
+		var tlsStart time.Time
+
+		trace := &httptrace.ClientTrace{
+			TLSHandshakeStart: func() {
+				tlsStart = time.Now()
+			},
+			TLSHandshakeDone: func(cs tls.ConnectionState, err error) {
+				var info ConnInfo
+				if hc := state.conn.Load(); hc != nil {
+					if err == nil {
+						hc.protocol.Store(&cs.NegotiatedProtocol)
+					}
+					info = hc.info(0)
+				}
+				info.Duration = time.Since(tlsStart)
+				info.Err = err
+				if err == nil {
+					info.Protocol = cs.NegotiatedProtocol
+					info.TLS = &cs
+				}
+				if hooks.OnTLSDone != nil {
+					hooks.OnTLSDone(info)
+				}
+			},
+			GotConn: func(info httptrace.GotConnInfo) {
+				if !info.Reused || hooks.OnConnReused == nil {
+					return
+				}
+				conn := info.Conn
+				if tc, ok := conn.(*tls.Conn); ok {
+					conn = tc.NetConn()
+				}
+				reused := ConnInfo{LocalAddr: info.Conn.LocalAddr(), RemoteAddr: info.Conn.RemoteAddr()}
+				if hc, ok := conn.(*hookedConn); ok {
+					reused = hc.info(0)
+				}
+				reused.Duration = info.IdleTime
+				hooks.OnConnReused(reused)
+			},
+		}
+		ctx := context.WithValue(req.Context(), connHookStateKey{}, state)
+		tracedReq := req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+		resp, err := next.RoundTrip(tracedReq)
+		if resp != nil {
+			resp.Request = req // 对调用方隐藏内部追踪用的请求副本
+		}
+		return resp, err
+	})
+}
+
+// hookedConn 记录连接信息, 关闭时触发一次 OnConnClosed
+type hookedConn struct {
+	net.Conn
+	hooks       *ConnectionHooks
+	addr        string
+	connectedAt time.Time
+	protocol    atomic.Pointer[string]
+	closeOnce   sync.Once
+}
+
+func (hc *hookedConn) info(d time.Duration) ConnInfo {
+	info := ConnInfo{
+		Addr:       hc.addr,
+		LocalAddr:  hc.Conn.LocalAddr(),
+		RemoteAddr: hc.Conn.RemoteAddr(),
+		Duration:   d,
+	}
+	if p := hc.protocol.Load(); p != nil {
+		info.Protocol = *p
+	}
+	return info
+}
+
+func (hc *hookedConn) Close() error {
+	err := hc.Conn.Close()
+	hc.closeOnce.Do(func() {
+		if hc.hooks.OnConnClosed != nil {
+			hc.hooks.OnConnClosed(hc.info(time.Since(hc.connectedAt)))
+		}
+	})
+	return err
+}
@@ -0,0 +1,95 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// connEvents 记录连接回调的触发顺序
+type connEvents struct {
+	mu     sync.Mutex
+	events []string
+	infos  []ConnInfo
+}
+
+func (e *connEvents) hook(name string) func(ConnInfo) {
+	return func(info ConnInfo) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.events = append(e.events, name)
+		e.infos = append(e.infos, info)
+	}
+}
+
+func (e *connEvents) snapshot() ([]string, []ConnInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.events...), append([]ConnInfo(nil), e.infos...)
+}
+
+func TestConnectionHooks(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	events := &connEvents{}
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	client := New(WithTLSConfig(tlsConfig), WithConnectionHooks(ConnectionHooks{
+		OnConnect:    events.hook("connect"),
+		OnTLSDone:    events.hook("tls"),
+		OnConnReused: events.hook("reused"),
+		OnConnClosed: events.hook("closed"),
+	}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GET(srv.URL).Text(); err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+	}
+	client.transport.CloseIdleConnections()
+
+	names, infos := events.snapshot()
+	if got, want := strings.Join(names, ","), "connect,tls,reused,closed"; got != want {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	for i, info := range infos {
+		if info.Addr != addr {
+			t.Fatalf("%s Addr = %q, want %q", names[i], info.Addr, addr)
+		}
+		if info.RemoteAddr == nil || info.RemoteAddr.String() != addr {
+			t.Fatalf("%s RemoteAddr = %v, want %s", names[i], info.RemoteAddr, addr)
+		}
+		if info.Err != nil {
+			t.Fatalf("%s Err = %v", names[i], info.Err)
+		}
+	}
+	if infos[1].Protocol != "http/1.1" || infos[1].TLS == nil {
+		t.Fatalf("tls Protocol = %q, TLS = %v, want http/1.1 with state", infos[1].Protocol, infos[1].TLS)
+	}
+	if infos[3].Protocol != "http/1.1" {
+		t.Fatalf("closed Protocol = %q, want %q", infos[3].Protocol, "http/1.1")
+	}
+}
+
+func TestConnectionHooksDialError(t *testing.T) {
+	events := &connEvents{}
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+		WithConnectionHooks(ConnectionHooks{OnConnect: events.hook("connect")}),
+	)
+
+	addr := closedAddr(t)
+	if _, err := client.GET("http://" + addr + "/").Text(); err == nil {
+		t.Fatal("Text() error = nil, want dial error")
+	}
+	names, infos := events.snapshot()
+	if len(names) != 1 || infos[0].Err == nil || infos[0].Addr != addr {
+		t.Fatalf("events = %v %+v, want one failed connect to %s", names, infos, addr)
+	}
+}
@@ -0,0 +1,101 @@
+package httpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// connectOverrideKey 是连接覆盖配置在请求 Context 中的键
+type connectOverrideKey struct{}
+
+// connectOverride 是请求级的连接覆盖 (ConnectTo, SetServerName)
+type connectOverride struct {
+	addr       string // 实际连接地址, 为空时按 URL 主机连接
+	serverName string // TLS SNI, 为空时使用客户端配置或 URL 主机
+}
+
+// key 返回连接池分片键, 同一组覆盖配置共享连接池
+func (o connectOverride) key() string {
+	return o.addr + "\x00" + o.serverName
+}
+
+// parseConnectOverride 从分片键还原覆盖配置
+func parseConnectOverride(key string) connectOverride {
+	addr, serverName, _ := strings.Cut(key, "\x00")
+	return connectOverride{addr: addr, serverName: serverName}
+}
+
+// SetHostHeader 覆盖请求的 Host 头, 不影响实际连接的地址与 TLS SNI
+func (rb *RequestBuilder) SetHostHeader(host string) *RequestBuilder {
+	rb.host = host
+	return rb
+}
+
+// ConnectTo 将请求发送到指定的 "host:port", URL 中的主机仍用于 Host 头与 TLS SNI/证书校验.
+// 适用于测试 CDN 后的源站或蓝绿切换验证. 这类请求绕过 HTTP 代理 (SOCKS5 代理仍生效),
+// 并使用按目标地址隔离的连接池, 不会与正常请求复用连接
+func (rb *RequestBuilder) ConnectTo(addr string) *RequestBuilder {
+	rb.connectOverride.addr = addr
+	return rb
+}
+
+// SetServerName 设置本次请求的 TLS SNI, 证书也按该名称校验, 与 URL 主机无关.
+// 使用按 SNI 隔离的连接池, 不会与正常请求复用连接
+func (rb *RequestBuilder) SetServerName(name string) *RequestBuilder {
+	rb.connectOverride.serverName = name
+	return rb
+}
+
+// WithServerName 设置客户端所有 TLS 连接的 SNI, 证书也按该名称校验
+func WithServerName(name string) Option {
+	return func(c *Client) {
+		c.serverName = name
+	}
+}
+
+// applyServerName 将 WithServerName 写入 TLS 配置, 在所有 Option 应用完成后调用,
+// 因此不受 WithTLSConfig 顺序影响
+func (c *Client) applyServerName() {
+	if c.serverName == "" {
+		return
+	}
+	c.transport.TLSClientConfig = withServerName(c.transport.TLSClientConfig, c.serverName)
+}
+
+// withServerName 返回设置了 ServerName 的 TLS 配置副本
+func withServerName(config *tls.Config, name string) *tls.Config {
+	if config == nil {
+		return &tls.Config{ServerName: name}
+	}
+	config = config.Clone()
+	config.ServerName = name
+	return config
+}
+
+// requestConnectOverride 返回请求携带的连接覆盖配置
+func requestConnectOverride(req *http.Request) (connectOverride, bool) {
+	o, ok := req.Context().Value(connectOverrideKey{}).(connectOverride)
+	return o, ok && o != connectOverride{}
+}
+
+// newOverrideTransports 创建按连接覆盖配置分片的 Transport 集合
+func (c *Client) newOverrideTransports() *transportShards {
+	return newTransportShards(defaultMaxTransportShards, nil, func(key string) *http.Transport {
+		o := parseConnectOverride(key)
+		t := c.transport.Clone()
+		if o.serverName != "" {
+			t.TLSClientConfig = withServerName(t.TLSClientConfig, o.serverName)
+		}
+		if o.addr != "" {
+			dial := t.DialContext
+			t.Proxy = nil
+			t.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return dial(ctx, network, o.addr)
+			}
+		}
+		return t
+	})
+}
@@ -0,0 +1,98 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConnectToWithHostHeader(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Host)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+
+	got, err := client.GET("http://origin.invalid/").ConnectTo(addr).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "origin.invalid" {
+		t.Fatalf("Host = %q, want %q", got, "origin.invalid")
+	}
+
+	got, err = client.GET("http://origin.invalid/").
+		ConnectTo(addr).
+		SetHostHeader("cdn.example.com").
+		Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "cdn.example.com" {
+		t.Fatalf("Host = %q, want %q", got, "cdn.example.com")
+	}
+
+	// 未指定 ConnectTo 的请求不能复用指向其他地址的连接
+	if _, err := client.GET("http://origin.invalid/").Text(); err == nil {
+		t.Fatal("request without ConnectTo succeeded, want DNS error")
+	}
+}
+
+func TestConnectToKeepsTLSServerName(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.ServerName)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	client := New(WithTLSConfig(tlsConfig))
+
+	// httptest 证书对 example.com 有效, 证书按 URL 主机校验
+	got, err := client.GET("https://example.com/").ConnectTo(addr).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "example.com" {
+		t.Fatalf("ServerName = %q, want %q", got, "example.com")
+	}
+}
+
+func TestServerNameOverride(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.ServerName)
+	}))
+	defer srv.Close()
+	tlsConfig := srv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	// WithServerName 在 WithTLSConfig 之前也生效
+	client := New(WithServerName("example.com"), WithTLSConfig(tlsConfig))
+	got, err := client.GET(srv.URL).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "example.com" {
+		t.Fatalf("ServerName = %q, want %q", got, "example.com")
+	}
+	if tlsConfig.ServerName != "" {
+		t.Fatalf("caller tls.Config modified, ServerName = %q", tlsConfig.ServerName)
+	}
+
+	client = New(WithTLSConfig(tlsConfig))
+	got, err = client.GET(srv.URL).SetServerName("www.example.com").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "www.example.com" {
+		t.Fatalf("ServerName = %q, want %q", got, "www.example.com")
+	}
+
+	// 证书不包含该名称时校验失败
+	if _, err := client.GET(srv.URL).SetServerName("internal.test").Text(); err == nil {
+		t.Fatal("request with mismatched ServerName succeeded, want certificate error")
+	}
+}
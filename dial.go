@@ -0,0 +1,134 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// 拨号分为三层, 由内到外:
+//   1. 基础拨号: WithDialContext 设置的函数, 默认为 c.dialer (受 WithDialTimeout/WithKeepAliveTimeout 影响)
+//   2. 直连拨号: 配置了 WithDNSResolver/WithResolver 时先用自定义解析器解析, 再依次尝试解析出的地址;
+//      未配置时直接使用基础拨号, 默认拨号器自行处理多地址回退与 Happy Eyeballs
+//   3. 代理拨号: 配置了 SOCKS5 代理时经由代理连接, 与代理服务器之间的连接使用直连拨号
+// 各层在拨号时才读取配置, 因此 Option 的先后顺序不影响最终结果
+
+// dialFunc 是拨号函数的签名
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// WithDialContext 设置基础拨号函数, 用于接入自定义拨号器 (例如其他 SOCKS 实现, TOR, 测试用的内存管道).
+// 自定义 DNS 解析与 SOCKS5 代理仍在其之上生效; 设置后 WithDialTimeout 与 WithKeepAliveTimeout 不再生效
+func WithDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return func(c *Client) {
+		c.dialContext = dial
+	}
+}
+
+// baseDial 使用基础拨号函数建立连接
+func (c *Client) baseDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.dialContext != nil {
+		return c.dialContext(ctx, network, addr)
+	}
+	return c.dialer.DialContext(ctx, network, addr)
+}
+
+// directDial 不经过代理建立连接, 配置了自定义 DNS 时先解析
+func (c *Client) directDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	if c.resolver != nil {
+		return c.resolver.DialContext(ctx, network, addr)
+	}
+	if c.dialContext != nil {
+		// 自定义拨号器自行处理解析 (例如 TOR 需要由远端解析主机名)
+		return c.dialContext(ctx, network, addr)
+	}
+	return c.dialer.DialContext(ctx, network, addr)
+}
+
+// dialTimeout 返回整体拨号超时, 自定义拨号器的超时由其自身控制
+func (c *Client) dialTimeout() time.Duration {
+	if c.dialContext != nil {
+		return 0
+	}
+	return c.dialer.Timeout
+}
+
+// ipNetwork 将拨号网络映射为 LookupIP 使用的地址族
+func ipNetwork(network string) string {
+	switch network {
+	case "tcp4", "udp4":
+		return "ip4"
+	case "tcp6", "udp6":
+		return "ip6"
+	default:
+		return "ip"
+	}
+}
+
+// minDialSlice 是分配给单个地址的最短拨号时间, 与标准库 net.Dialer 一致
+const minDialSlice = 2 * time.Second
+
+// errNoAddresses 表示解析结果中没有可拨号的地址
+var errNoAddresses = errors.New("httpc: no addresses to dial")
+
+// dialIPs 依次尝试 ips, 将剩余的拨号时间 (timeout 与 ctx 截止时间中较早者) 平分给尚未尝试的地址,
+// 全部失败时返回第一个错误. 供自定义解析器使用, 默认拨号路径由 net.Dialer 处理.
+// now 必须与 context 截止时间使用同一时间源 (真实时间)
+func dialIPs(ctx context.Context, now func() time.Time, dial dialFunc, network, port string, ips []net.IP, timeout time.Duration) (net.Conn, error) {
+	if len(ips) == 0 {
+		return nil, errNoAddresses
+	}
+
+	deadline, hasDeadline := ctx.Deadline()
+	if timeout > 0 {
+		if d := now().Add(timeout); !hasDeadline || d.Before(deadline) {
+			deadline, hasDeadline = d, true
+		}
+	}
+
+	var firstErr error
+	for i, ip := range ips {
+		dialCtx, cancel := ctx, context.CancelFunc(func() {})
+		if hasDeadline {
+			dialCtx, cancel = context.WithDeadline(ctx, partialDeadline(now(), deadline, len(ips)-i))
+		}
+		conn, err := dial(dialCtx, network, net.JoinHostPort(ip.String(), port))
+		cancel()
+		if err == nil {
+			return conn, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, firstErr
+}
+
+// partialDeadline 返回剩余 remaining 个地址时当前地址的拨号截止时间
+func partialDeadline(now, deadline time.Time, remaining int) time.Time {
+	timeRemaining := deadline.Sub(now)
+	if timeRemaining <= 0 {
+		return deadline
+	}
+	slice := timeRemaining / time.Duration(remaining)
+	if slice < minDialSlice {
+		slice = min(minDialSlice, timeRemaining)
+	}
+	return now.Add(slice)
+}
+
+// forwardDialer 将 directDial 适配为 proxy.Dialer, 供 SOCKS5 代理连接代理服务器使用
+type forwardDialer struct {
+	client *Client
+}
+
+func (d forwardDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.client.directDial(context.Background(), network, addr)
+}
+
+func (d forwardDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.client.directDial(ctx, network, addr)
+}
@@ -0,0 +1,181 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithDialContextRedirectsConnections(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	target := strings.TrimPrefix(srv.URL, "http://")
+
+	var mu sync.Mutex
+	var dialed []string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, addr)
+		mu.Unlock()
+		var d net.Dialer
+		return d.DialContext(ctx, network, target)
+	}
+
+	// 之后的 WithDialTimeout 不能覆盖自定义拨号
+	client := New(WithDialContext(dial), WithDialTimeout(time.Second), WithKeepAliveTimeout(time.Second))
+	got, err := client.GET("http://service.internal:8080/").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "ok" {
+		t.Fatalf("body = %q, want %q", got, "ok")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) != 1 || dialed[0] != "service.internal:8080" {
+		t.Fatalf("dialed = %v, want [service.internal:8080]", dialed)
+	}
+}
+
+func TestWithDialContextUnderSocks5Proxy(t *testing.T) {
+	errDial := errors.New("dial blocked")
+	var mu sync.Mutex
+	var dialed []string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, addr)
+		mu.Unlock()
+		return nil, errDial
+	}
+
+	client := New(
+		WithSocks5Proxy("socks5://proxy.internal:1080"),
+		WithDialContext(dial),
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+	)
+	if _, err := client.GET("http://example.com/").Text(); !errors.Is(err, errDial) {
+		t.Fatalf("error = %v, want %v", err, errDial)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) != 1 || dialed[0] != "proxy.internal:1080" {
+		t.Fatalf("dialed = %v, want [proxy.internal:1080]", dialed)
+	}
+}
+
+func TestDialIPsFailsOverToNextAddress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	// 127.0.0.2 上没有监听, 连接被拒绝后应切换到 127.0.0.1
+	var d net.Dialer
+	ips := []net.IP{net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")}
+	conn, err := dialIPs(context.Background(), time.Now, d.DialContext, "tcp", port, ips, time.Second)
+	if err != nil {
+		t.Fatalf("dialIPs() error = %v", err)
+	}
+	defer conn.Close()
+	if got := conn.RemoteAddr().String(); got != ln.Addr().String() {
+		t.Fatalf("RemoteAddr = %q, want %q", got, ln.Addr().String())
+	}
+}
+
+func TestDialIPsSlicesTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	var got []time.Duration
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		deadline, _ := ctx.Deadline()
+		got = append(got, deadline.Sub(clock.Now()))
+		clock.Advance(time.Second) // 每个地址耗时 1s 后失败
+		return nil, errors.New("unreachable")
+	}
+	ips := []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("192.0.2.2"), net.ParseIP("192.0.2.3")}
+
+	if _, err := dialIPs(context.Background(), clock.Now, dial, "tcp", "80", ips, 9*time.Second); err == nil {
+		t.Fatal("dialIPs() error = nil, want error")
+	}
+	// 剩余时间重新平分给尚未尝试的地址, 最后一个地址获得全部剩余时间
+	want := []time.Duration{3 * time.Second, 4 * time.Second, 7 * time.Second}
+	if !slices.Equal(got, want) {
+		t.Fatalf("slices = %v, want %v", got, want)
+	}
+
+	got = nil
+	_, _ = dialIPs(context.Background(), clock.Now, dial, "tcp", "80", ips, 3*time.Second)
+	if want := []time.Duration{minDialSlice, minDialSlice, time.Second}; !slices.Equal(got, want) {
+		t.Fatalf("slices = %v, want %v", got, want)
+	}
+}
+
+func TestWithResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	var lookups atomic.Int32
+	resolver := ResolverFunc(func(ctx context.Context, host string) ([]net.IP, error) {
+		lookups.Add(1)
+		if host != "api.service" {
+			return nil, errors.New("unknown service")
+		}
+		// 第一个地址不可达, 应切换到第二个
+		return []net.IP{net.ParseIP("127.0.0.2"), net.ParseIP("127.0.0.1")}, nil
+	})
+	client := New(WithResolver(resolver), WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+
+	got, err := client.GET("http://api.service:" + port + "/").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if want := "api.service:" + port; got != want {
+		t.Fatalf("Host = %q, want %q", got, want)
+	}
+
+	// 解析失败不回退到系统 DNS
+	if _, err := client.GET("http://localhost:" + port + "/").Text(); err == nil || !strings.Contains(err.Error(), "unknown service") {
+		t.Fatalf("error = %v, want resolver error", err)
+	}
+	if got := lookups.Load(); got != 2 {
+		t.Fatalf("lookups = %d, want 2", got)
+	}
+}
+
+func TestWithResolverIgnoresFakeClock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	resolver := ResolverFunc(func(ctx context.Context, host string) ([]net.IP, error) {
+		return []net.IP{net.ParseIP("127.0.0.1")}, nil
+	})
+	// 假时钟停在过去, 拨号截止时间若按假时钟计算会立即超时
+	client := New(WithClock(NewFakeClock(time.Unix(0, 0))), WithResolver(resolver), WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	if _, err := client.GET("http://api.service:" + port + "/").Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+}
+
+func TestWithDNSResolverUsesRealTimeForDialDeadlines(t *testing.T) {
+	client := New(WithClock(NewFakeClock(time.Unix(0, 0))), WithDNSResolver([]string{"127.0.0.1:53"}, time.Second))
+	if got := client.resolver.now(); time.Since(got) > time.Minute {
+		t.Fatalf("now() = %v, want real time", got)
+	}
+}
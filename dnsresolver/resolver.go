@@ -0,0 +1,264 @@
+// Package dnsresolver 提供基于 miekg/dns 的 httpc.Resolver 实现:
+// 支持 EDNS0, 自定义端口与传输协议 (UDP/TCP/DoT), UDP 截断时回退 TCP, 以及 CNAME 链检查
+package dnsresolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// 默认配置常量
+const (
+	defaultTimeout       = 5 * time.Second
+	defaultUDPSize       = 1232 // DNS Flag Day 2020 推荐的 EDNS0 负载大小
+	defaultMaxCNAMEChain = 8
+)
+
+// ErrCNAMEChainTooLong 表示 CNAME 链超过了允许的跳数 (或存在环)
+var ErrCNAMEChainTooLong = errors.New("dnsresolver: CNAME chain too long")
+
+// Resolver 使用指定的 DNS 服务器解析主机名, 实现了 httpc.Resolver 接口
+type Resolver struct {
+	servers  []string      // "host:port"
+	network  string        // udp, tcp, tcp-tls
+	timeout  time.Duration // 单次查询超时
+	udpSize  uint16        // EDNS0 UDP 负载大小, 0 表示不发送 EDNS0
+	maxCNAME int           // 最大 CNAME 跳数
+}
+
+// Option 配置 Resolver
+type Option func(*Resolver)
+
+// WithNet 设置传输协议: "udp" (默认, 截断时回退 TCP), "tcp" 或 "tcp-tls" (DNS over TLS)
+func WithNet(network string) Option {
+	return func(r *Resolver) {
+		r.network = network
+	}
+}
+
+// WithTimeout 设置单次查询的超时时间
+func WithTimeout(d time.Duration) Option {
+	return func(r *Resolver) {
+		r.timeout = d
+	}
+}
+
+// WithEDNS0 设置 EDNS0 声明的 UDP 负载大小, 0 表示不发送 EDNS0 OPT 记录
+func WithEDNS0(udpSize uint16) Option {
+	return func(r *Resolver) {
+		r.udpSize = udpSize
+	}
+}
+
+// WithMaxCNAMEChain 设置允许跟随的最大 CNAME 跳数
+func WithMaxCNAMEChain(n int) Option {
+	return func(r *Resolver) {
+		r.maxCNAME = n
+	}
+}
+
+// New 创建解析器, servers 为 "host" 或 "host:port" (默认端口 53, tcp-tls 为 853), 按顺序尝试
+func New(servers []string, opts ...Option) *Resolver {
+	r := &Resolver{
+		network:  "udp",
+		timeout:  defaultTimeout,
+		udpSize:  defaultUDPSize,
+		maxCNAME: defaultMaxCNAMEChain,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	port := "53"
+	if r.network == "tcp-tls" {
+		port = "853"
+	}
+	for _, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, port)
+		}
+		r.servers = append(r.servers, server)
+	}
+	return r
+}
+
+// Result 是一次解析的完整结果
+type Result struct {
+	Name   string        // 查询的主机名
+	CNAMEs []string      // CNAME 链, 按跳转顺序, 不含末尾的点
+	IPs    []net.IP      // A 记录在前, AAAA 记录在后
+	TTL    time.Duration // 结果中所有记录的最小 TTL
+}
+
+// LookupIP 实现了 httpc.Resolver 接口
+func (r *Resolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	result, err := r.Lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return result.IPs, nil
+}
+
+// Lookup 查询主机的 A 与 AAAA 记录并跟随 CNAME 链
+// 除 ErrCNAMEChainTooLong 外错误均为 *net.DNSError, 因此 httpc 会将其归类为 DNS 错误;
+// 只要 A 或 AAAA 之一有结果即返回成功
+func (r *Resolver) Lookup(ctx context.Context, host string) (*Result, error) {
+	type answer struct {
+		chain *chainResult
+		err   error
+	}
+	aCh := make(chan answer, 1)
+	go func() {
+		chain, err := r.resolveChain(ctx, host, dns.TypeA)
+		aCh <- answer{chain, err}
+	}()
+	aaaa, aaaaErr := r.resolveChain(ctx, host, dns.TypeAAAA)
+	a := <-aCh
+
+	result := &Result{Name: host}
+	for _, chain := range []*chainResult{a.chain, aaaa} {
+		if chain == nil {
+			continue
+		}
+		if len(chain.cnames) > len(result.CNAMEs) {
+			result.CNAMEs = chain.cnames
+		}
+		result.IPs = append(result.IPs, chain.ips...)
+		if chain.ttl > 0 && (result.TTL == 0 || chain.ttl < result.TTL) {
+			result.TTL = chain.ttl
+		}
+	}
+	if len(result.IPs) == 0 {
+		if a.err != nil {
+			return nil, a.err
+		}
+		if aaaaErr != nil {
+			return nil, aaaaErr
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return result, nil
+}
+
+// chainResult 是单一记录类型的解析结果
+type chainResult struct {
+	cnames []string
+	ips    []net.IP
+	ttl    time.Duration
+}
+
+// resolveChain 查询 qtype 记录, 跟随应答中的 CNAME, 链尾没有记录时继续查询链尾名称
+func (r *Resolver) resolveChain(ctx context.Context, host string, qtype uint16) (*chainResult, error) {
+	result := &chainResult{}
+	name := dns.Fqdn(host)
+
+	for {
+		resp, err := r.exchange(ctx, host, name, qtype)
+		if err != nil {
+			return nil, err
+		}
+
+		// 应答中可能包含完整的 CNAME 链, 按名称依次跟随
+		followed := false
+		for {
+			target := result.collect(resp, name)
+			if target == "" || len(result.ips) > 0 {
+				break
+			}
+			if len(result.cnames) >= r.maxCNAME {
+				return nil, fmt.Errorf("%w: %s", ErrCNAMEChainTooLong, host)
+			}
+			result.cnames = append(result.cnames, strings.TrimSuffix(target, "."))
+			name, followed = target, true
+		}
+
+		if len(result.ips) > 0 || !followed {
+			return result, nil
+		}
+	}
+}
+
+// collect 收集应答中属于 name 的地址记录, 返回 name 的 CNAME 目标 (没有时为空)
+func (c *chainResult) collect(resp *dns.Msg, name string) string {
+	target := ""
+	for _, rr := range resp.Answer {
+		if !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+		switch rr := rr.(type) {
+		case *dns.A:
+			c.ips = append(c.ips, rr.A)
+		case *dns.AAAA:
+			c.ips = append(c.ips, rr.AAAA)
+		case *dns.CNAME:
+			target = rr.Target
+		default:
+			continue
+		}
+		c.observeTTL(rr.Header().Ttl)
+	}
+	return target
+}
+
+func (c *chainResult) observeTTL(ttl uint32) {
+	d := time.Duration(ttl) * time.Second
+	if c.ttl == 0 || d < c.ttl {
+		c.ttl = d
+	}
+}
+
+// exchange 依次向各服务器发送查询, UDP 应答被截断时使用 TCP 重试同一服务器
+func (r *Resolver) exchange(ctx context.Context, host, name string, qtype uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+	if r.udpSize > 0 {
+		msg.SetEdns0(r.udpSize, false)
+	}
+
+	if len(r.servers) == 0 {
+		return nil, &net.DNSError{Err: "no DNS servers configured", Name: host}
+	}
+
+	var lastErr error
+	for _, server := range r.servers {
+		resp, err := r.exchangeWith(ctx, r.network, msg, server)
+		if err == nil && resp.Truncated && r.network == "udp" {
+			resp, err = r.exchangeWith(ctx, "tcp", msg, server)
+		}
+		if err != nil {
+			lastErr = &net.DNSError{Err: err.Error(), Name: host, Server: server, IsTimeout: isTimeout(err)}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp, nil
+		case dns.RcodeNameError:
+			return nil, &net.DNSError{Err: "no such host", Name: host, Server: server, IsNotFound: true}
+		default:
+			// SERVFAIL, REFUSED 等: 尝试下一个服务器
+			lastErr = &net.DNSError{Err: "server returned " + dns.RcodeToString[resp.Rcode], Name: host, Server: server, IsTemporary: true}
+		}
+	}
+	return nil, lastErr
+}
+
+func (r *Resolver) exchangeWith(ctx context.Context, network string, msg *dns.Msg, server string) (*dns.Msg, error) {
+	client := &dns.Client{Net: network, Timeout: r.timeout, UDPSize: r.udpSize}
+	resp, _, err := client.ExchangeContext(ctx, msg, server)
+	return resp, err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,220 @@
+package dnsresolver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+// testServer 是同时监听 UDP 与 TCP 同一端口的 DNS 服务端
+type testServer struct {
+	addr string
+
+	mu       sync.Mutex
+	udpSizes []uint16 // 每个查询声明的 EDNS0 UDP 负载大小, 0 表示未发送
+	tcp      int      // 通过 TCP 收到的查询数
+}
+
+func newTestServer(t *testing.T, handler func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg)) *testServer {
+	t.Helper()
+	s := &testServer{}
+
+	var pc net.PacketConn
+	var ln net.Listener
+	for range 10 {
+		var err error
+		if pc, err = net.ListenPacket("udp", "127.0.0.1:0"); err != nil {
+			t.Fatalf("ListenPacket() error = %v", err)
+		}
+		if ln, err = net.Listen("tcp", pc.LocalAddr().String()); err == nil {
+			break
+		}
+		pc.Close()
+		pc = nil
+	}
+	if pc == nil {
+		t.Fatal("could not listen on matching UDP and TCP ports")
+	}
+	s.addr = pc.LocalAddr().String()
+
+	h := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		s.mu.Lock()
+		var size uint16
+		if opt := req.IsEdns0(); opt != nil {
+			size = opt.UDPSize()
+		}
+		s.udpSizes = append(s.udpSizes, size)
+		if w.RemoteAddr().Network() == "tcp" {
+			s.tcp++
+		}
+		s.mu.Unlock()
+
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+		handler(w, req, msg)
+		_ = w.WriteMsg(msg)
+	})
+	udp := &dns.Server{PacketConn: pc, Handler: h}
+	tcp := &dns.Server{Listener: ln, Handler: h}
+	go func() { _ = udp.ActivateAndServe() }()
+	go func() { _ = tcp.ActivateAndServe() }()
+	t.Cleanup(func() {
+		_ = udp.Shutdown()
+		_ = tcp.Shutdown()
+	})
+	return s
+}
+
+func rr(t *testing.T, s string) dns.RR {
+	t.Helper()
+	record, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatalf("NewRR(%q) error = %v", s, err)
+	}
+	return record
+}
+
+func TestLookupFollowsCNAMEChain(t *testing.T) {
+	srv := newTestServer(t, func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg) {
+		q := req.Question[0]
+		switch q.Name {
+		case "www.example.test.":
+			// 应答中只有链的第一跳, 客户端需要继续查询
+			msg.Answer = append(msg.Answer, rr(t, "www.example.test. 300 IN CNAME cdn.example.test."))
+		case "cdn.example.test.":
+			msg.Answer = append(msg.Answer, rr(t, "cdn.example.test. 60 IN CNAME edge.example.test."))
+			if q.Qtype == dns.TypeA {
+				msg.Answer = append(msg.Answer, rr(t, "edge.example.test. 30 IN A 127.0.0.1"))
+			} else {
+				msg.Answer = append(msg.Answer, rr(t, "edge.example.test. 30 IN AAAA ::1"))
+			}
+		}
+	})
+
+	result, err := New([]string{srv.addr}).Lookup(context.Background(), "www.example.test")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if got := strings.Join(result.CNAMEs, ","); got != "cdn.example.test,edge.example.test" {
+		t.Fatalf("CNAMEs = %q, want %q", got, "cdn.example.test,edge.example.test")
+	}
+	if len(result.IPs) != 2 || !result.IPs[0].Equal(net.ParseIP("127.0.0.1")) || !result.IPs[1].Equal(net.ParseIP("::1")) {
+		t.Fatalf("IPs = %v, want [127.0.0.1 ::1]", result.IPs)
+	}
+	if result.TTL.Seconds() != 30 {
+		t.Fatalf("TTL = %v, want 30s", result.TTL)
+	}
+}
+
+func TestLookupFallsBackToTCPOnTruncation(t *testing.T) {
+	srv := newTestServer(t, func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg) {
+		if w.RemoteAddr().Network() != "tcp" {
+			msg.Truncated = true
+			return
+		}
+		if req.Question[0].Qtype == dns.TypeA {
+			msg.Answer = append(msg.Answer, rr(t, "big.example.test. 60 IN A 127.0.0.1"))
+		}
+	})
+
+	ips, err := New([]string{srv.addr}, WithEDNS0(4096)).LookupIP(context.Background(), "big.example.test")
+	if err != nil {
+		t.Fatalf("LookupIP() error = %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(net.ParseIP("127.0.0.1")) {
+		t.Fatalf("IPs = %v, want [127.0.0.1]", ips)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.tcp != 2 {
+		t.Fatalf("TCP queries = %d, want 2", srv.tcp)
+	}
+	for _, size := range srv.udpSizes {
+		if size != 4096 {
+			t.Fatalf("EDNS0 UDP size = %d, want 4096", size)
+		}
+	}
+}
+
+func TestLookupNotFound(t *testing.T) {
+	srv := newTestServer(t, func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg) {
+		msg.Rcode = dns.RcodeNameError
+	})
+
+	_, err := New([]string{srv.addr}).LookupIP(context.Background(), "missing.example.test")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Fatalf("error = %v, want not-found *net.DNSError", err)
+	}
+}
+
+func TestLookupCNAMELoop(t *testing.T) {
+	srv := newTestServer(t, func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg) {
+		msg.Answer = append(msg.Answer,
+			rr(t, "a.example.test. 60 IN CNAME b.example.test."),
+			rr(t, "b.example.test. 60 IN CNAME a.example.test."),
+		)
+	})
+
+	_, err := New([]string{srv.addr}, WithMaxCNAMEChain(4)).LookupIP(context.Background(), "a.example.test")
+	if !errors.Is(err, ErrCNAMEChainTooLong) {
+		t.Fatalf("error = %v, want %v", err, ErrCNAMEChainTooLong)
+	}
+}
+
+// lookupIPResolver 与 httpc.Resolver 的方法集一致; 测试不依赖 httpc, 模块无需 replace
+type lookupIPResolver interface {
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+}
+
+var _ lookupIPResolver = (*Resolver)(nil)
+
+func TestResolverDialsResolvedAddress(t *testing.T) {
+	srv := newTestServer(t, func(w dns.ResponseWriter, req *dns.Msg, msg *dns.Msg) {
+		if req.Question[0].Qtype == dns.TypeA {
+			msg.Answer = append(msg.Answer, rr(t, "api.example.test. 60 IN A 127.0.0.1"))
+		}
+	})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Host))
+	}))
+	defer backend.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(backend.URL, "http://"))
+
+	var resolver lookupIPResolver = New([]string{srv.addr})
+	var dialer net.Dialer
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := resolver.LookupIP(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+		},
+	}}
+	resp, err := client.Get("http://api.example.test:" + port + "/")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if want := "api.example.test:" + port; string(got) != want {
+		t.Fatalf("Host = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+)
+
+// defaultDownloadFilename 在无法从响应中推断文件名时使用
+const defaultDownloadFilename = "download"
+
+// DownloadToFile 执行请求并将响应体写入 filePath
+// 数据先写入同目录下的临时文件, 完成后再重命名, 避免留下不完整的文件
+func (rb *RequestBuilder) DownloadToFile(filePath string) (int64, error) {
+	resp, err := rb.Execute()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return 0, rb.client.errorResponse(resp)
+	}
+	return writeFileAtomic(filePath, resp.Body)
+}
+
+// DownloadToDir 执行请求并将响应体保存到 dir 目录下, 返回最终文件路径
+// 文件名按 RFC 6266 从 Content-Disposition (含 filename* 编码) 中解析,
+// 缺失时回退到 URL 路径的最后一段; 文件名会被清理以防止路径穿越
+func (rb *RequestBuilder) DownloadToDir(dir string) (string, error) {
+	resp, err := rb.Execute()
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return "", rb.client.errorResponse(resp)
+	}
+
+	filePath := filepath.Join(dir, downloadFilename(resp))
+	if _, err := writeFileAtomic(filePath, resp.Body); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// downloadFilename 从响应中推断安全的文件名
+func downloadFilename(resp *http.Response) string {
+	if cd := resp.Header.Get("Content-Disposition"); cd != "" {
+		// mime.ParseMediaType 会处理 RFC 2231 的 filename* 编码, 并优先于 filename
+		if _, params, err := mime.ParseMediaType(cd); err == nil {
+			if name := sanitizeFilename(params["filename"]); name != "" {
+				return name
+			}
+		}
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		if name := sanitizeFilename(path.Base(resp.Request.URL.Path)); name != "" {
+			return name
+		}
+	}
+	return defaultDownloadFilename
+}
+
+// sanitizeFilename 去除目录部分与控制字符, 拒绝 "." / ".." 等不安全的名称
+func sanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	name = strings.TrimLeft(name, ".")
+	name = strings.TrimRight(name, ". ") // Windows 会静默去掉结尾的点与空格
+	if name == "" || name == "/" {
+		return ""
+	}
+	if isReservedFilename(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+// isReservedFilename 判断文件名是否为 Windows 保留的设备名 (CON, NUL, COM1 等, 带扩展名同样保留)
+func isReservedFilename(name string) bool {
+	base, _, _ := strings.Cut(name, ".")
+	base = strings.ToUpper(strings.TrimSpace(base))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL", "CONIN$", "CONOUT$":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '1' && base[3] <= '9'
+	}
+	return false
+}
+
+// writeFileAtomic 将 r 的内容写入 filePath, 通过临时文件 + 重命名保证原子性
+func writeFileAtomic(filePath string, r io.Reader) (int64, error) {
+	tmp, err := createDownloadTemp(filepath.Dir(filePath))
+	if err != nil {
+		return 0, fmt.Errorf("httpc: create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	n, copyErr := iox.Copy(tmp, r)
+	closeErr := tmp.Close()
+	if err := errors.Join(copyErr, closeErr); err != nil {
+		os.Remove(tmpName)
+		return n, fmt.Errorf("httpc: write download: %w", err)
+	}
+	if err := os.Rename(tmpName, filePath); err != nil {
+		os.Remove(tmpName)
+		return n, fmt.Errorf("httpc: rename download: %w", err)
+	}
+	return n, nil
+}
+
+// createDownloadTemp 在 dir 下创建临时文件. 与 os.CreateTemp (0600) 不同, 以 0666 创建并由 umask 决定最终权限,
+// 重命名后的文件与 os.Create 创建的文件权限一致
+func createDownloadTemp(dir string) (*os.File, error) {
+	for range 10000 {
+		name := filepath.Join(dir, ".httpc-download-"+strconv.FormatUint(rand.Uint64(), 36))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if os.IsExist(err) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &os.PathError{Op: "createtemp", Path: filepath.Join(dir, ".httpc-download-*"), Err: os.ErrExist}
+}
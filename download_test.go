@@ -0,0 +1,106 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestRequestBuilderDownloadToDirUsesContentDisposition(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Disposition", `attachment; filename="fallback.txt"; filename*=UTF-8''%E6%8A%A5%E5%91%8A.txt`)
+		_, _ = io.WriteString(w, "report")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	got, err := New().GET(server.URL + "/files/42").DownloadToDir(dir)
+	if err != nil {
+		t.Fatalf("DownloadToDir() error = %v", err)
+	}
+	if want := filepath.Join(dir, "报告.txt"); got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+	data, err := os.ReadFile(got)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "report" {
+		t.Fatalf("content = %q, want report", data)
+	}
+}
+
+func TestSanitizeFilenameRejectsTraversal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "../../etc/passwd", want: "passwd"},
+		{in: `..\..\windows\system.ini`, want: "system.ini"},
+		{in: "..", want: ""},
+		{in: ".hidden", want: "hidden"},
+		{in: "a\x00b.txt", want: "ab.txt"},
+		{in: "/", want: ""},
+		{in: "CON", want: "_CON"},
+		{in: "nul.txt", want: "_nul.txt"},
+		{in: "com1.tar.gz", want: "_com1.tar.gz"},
+		{in: "lpt9", want: "_lpt9"},
+		{in: "com0.txt", want: "com0.txt"},
+		{in: "console.log", want: "console.log"},
+		{in: "report. ", want: "report"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeFilename(tt.in); got != tt.want {
+			t.Fatalf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDownloadFilenameFallsBackToURLPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "bin")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	got, err := New().GET(server.URL + "/releases/app.tar.gz?token=1").DownloadToDir(dir)
+	if err != nil {
+		t.Fatalf("DownloadToDir() error = %v", err)
+	}
+	if want := filepath.Join(dir, "app.tar.gz"); got != want {
+		t.Fatalf("path = %q, want %q", got, want)
+	}
+}
+
+func TestDownloadToFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permission bits are not meaningful on Windows")
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "data")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "out.bin")
+	if _, err := New().GET(server.URL).DownloadToFile(target); err != nil {
+		t.Fatalf("DownloadToFile() error = %v", err)
+	}
+
+	// 与 os.Create 创建的文件权限一致 (0666 去掉 umask)
+	ref := filepath.Join(dir, "ref")
+	f, err := os.Create(ref)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	f.Close()
+	got, _ := os.Stat(target)
+	want, _ := os.Stat(ref)
+	if got.Mode().Perm() != want.Mode().Perm() {
+		t.Fatalf("mode = %v, want %v", got.Mode().Perm(), want.Mode().Perm())
+	}
+}
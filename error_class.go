@@ -0,0 +1,217 @@
+package httpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// 按失败原因划分的错误. 客户端返回的错误会附加对应的哨兵错误, 可通过 errors.Is 判断, 错误信息保持不变;
+// 需要更细的类别 (例如区分连接被拒绝与被重置) 时使用 Classify
+var (
+	ErrDNS      = errors.New("httpc: dns resolution failed")
+	ErrConnect  = errors.New("httpc: connect failed")
+	ErrTLS      = errors.New("httpc: tls handshake failed")
+	ErrTimeout  = ErrRequestTimeout // 与 ErrRequestTimeout 为同一个值, 涵盖所有超时 (含分段超时)
+	ErrProxy    = errors.New("httpc: proxy failed")
+	ErrBodyRead = errors.New("httpc: failed to read response body")
+)
+
+// ErrorClass 描述请求错误的类别, 用于按类别决定是否重试
+type ErrorClass int
+
+const (
+	ErrorClassUnknown     ErrorClass = iota // 非网络错误 (例如上下文取消, 中间件返回的错误)
+	ErrorClassDNS                           // DNS 解析失败
+	ErrorClassConnRefused                   // 连接被拒绝
+	ErrorClassConnReset                     // 连接被重置或对端提前关闭
+	ErrorClassTLS                           // TLS 握手或证书校验失败
+	ErrorClassTimeout                       // 超时
+	ErrorClassNetwork                       // 其他网络错误
+	ErrorClassProxy                         // 连接代理或代理握手失败
+	ErrorClassBodyRead                      // 读取响应体失败
+)
+
+// String 返回错误类别的名称
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassDNS:
+		return "dns"
+	case ErrorClassConnRefused:
+		return "conn_refused"
+	case ErrorClassConnReset:
+		return "conn_reset"
+	case ErrorClassTLS:
+		return "tls"
+	case ErrorClassTimeout:
+		return "timeout"
+	case ErrorClassNetwork:
+		return "network"
+	case ErrorClassProxy:
+		return "proxy"
+	case ErrorClassBodyRead:
+		return "body_read"
+	default:
+		return "unknown"
+	}
+}
+
+// defaultRetryErrorClasses 是各错误类别的默认重试策略
+// TLS 错误 (例如证书校验失败) 重试也不会成功, 默认不重试
+var defaultRetryErrorClasses = map[ErrorClass]bool{
+	ErrorClassDNS:         true,
+	ErrorClassConnRefused: true,
+	ErrorClassConnReset:   true,
+	ErrorClassTLS:         false,
+	ErrorClassTimeout:     true,
+	ErrorClassNetwork:     true,
+	ErrorClassProxy:       true,
+}
+
+// Classify 判断错误所属的类别, 便于按失败原因分支处理而无需匹配错误字符串;
+// nil, 上下文取消以及非网络错误 (例如 HTTPError, 中间件返回的错误) 返回 ErrorClassUnknown
+func Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassUnknown
+	}
+	if errors.Is(err, ErrTimeout) || errors.Is(err, ErrResponseHeaderTimeout) || errors.Is(err, ErrBodyReadTimeout) ||
+		errors.Is(err, ErrDialTimeout) || errors.Is(err, ErrTLSHandshakeTimeout) {
+		return ErrorClassTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassUnknown
+	}
+	if isProxyError(err) {
+		// 连接代理超时仍归为超时, 与其他阶段的超时一致
+		if isTimeoutError(err) {
+			return ErrorClassTimeout
+		}
+		return ErrorClassProxy
+	}
+	if errors.Is(err, ErrBodyRead) {
+		return ErrorClassBodyRead
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ErrorClassDNS
+	}
+	if isTLSError(err) {
+		return ErrorClassTLS
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorClassConnRefused
+	}
+	// Transport 在对端未返回响应就关闭连接 (常见于复用了已被服务端关闭的空闲连接) 时返回包装 io.EOF 的错误,
+	// 读取响应体时的 EOF 已在上面归为 ErrorClassBodyRead
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorClassConnReset
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	return ErrorClassUnknown
+}
+
+// isTimeoutError 判断错误链中是否有 context.DeadlineExceeded 或超时的 net.Error
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isProxyError 判断错误是否发生在连接代理或代理握手阶段
+// (net/http 的 proxyconnect, SOCKS5 的 socks connect, 以及 Tunnel 返回的错误)
+func isProxyError(err error) bool {
+	if errors.Is(err, ErrProxy) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks"))
+}
+
+// isConnectError 判断错误是否发生在建立连接阶段
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// classSentinel 返回错误类别对应的哨兵错误, 没有对应哨兵时返回 nil
+func classSentinel(class ErrorClass, err error) error {
+	switch class {
+	case ErrorClassDNS:
+		return ErrDNS
+	case ErrorClassConnRefused:
+		return ErrConnect
+	case ErrorClassTLS:
+		return ErrTLS
+	case ErrorClassTimeout:
+		return ErrTimeout
+	case ErrorClassProxy:
+		return ErrProxy
+	case ErrorClassBodyRead:
+		return ErrBodyRead
+	case ErrorClassConnReset, ErrorClassNetwork:
+		if isConnectError(err) {
+			return ErrConnect
+		}
+	}
+	return nil
+}
+
+// classifiedError 为错误附加类别对应的哨兵错误, 错误信息与原错误相同
+type classifiedError struct {
+	err      error
+	sentinel error
+}
+
+func (e *classifiedError) Error() string   { return e.err.Error() }
+func (e *classifiedError) Unwrap() []error { return []error{e.err, e.sentinel} }
+
+// isTLSError 判断错误是否来自 TLS 握手或证书校验
+func isTLSError(err error) bool {
+	var (
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+		verifyErr   *tls.CertificateVerificationError
+		authErr     x509.UnknownAuthorityError
+		hostErr     x509.HostnameError
+		invalidErr  x509.CertificateInvalidError
+		systemRoots x509.SystemRootsError
+	)
+	return errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &verifyErr) ||
+		errors.As(err, &authErr) ||
+		errors.As(err, &hostErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &systemRoots)
+}
+
+// retryOnError 按 RetryOptions.RetryErrors 判断该错误类别是否需要重试
+func retryOnError(opts *RetryOptions, err error) bool {
+	class := Classify(err)
+	if class == ErrorClassUnknown {
+		return false
+	}
+	if retry, ok := opts.RetryErrors[class]; ok {
+		return retry
+	}
+	return defaultRetryErrorClasses[class]
+}
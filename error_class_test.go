@@ -0,0 +1,214 @@
+package httpc
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{name: "dns", err: &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x"}}}, want: ErrorClassDNS},
+		{name: "refused", err: &url.Error{Op: "Get", URL: "http://x", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}, want: ErrorClassConnRefused},
+		{name: "reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: ErrorClassConnReset},
+		{name: "tls", err: &url.Error{Op: "Get", URL: "https://x", Err: x509.UnknownAuthorityError{}}, want: ErrorClassTLS},
+		{name: "timeout", err: fmt.Errorf("wrapped: %w", context.DeadlineExceeded), want: ErrorClassTimeout},
+		{name: "canceled", err: &url.Error{Op: "Get", URL: "http://x", Err: context.Canceled}, want: ErrorClassUnknown},
+		{name: "plain", err: errors.New("boom"), want: ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Fatalf("%s: Classify() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWrapErrorSentinels(t *testing.T) {
+	client := New()
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		class    ErrorClass
+	}{
+		{name: "dns", err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "x"}}, sentinel: ErrDNS, class: ErrorClassDNS},
+		{name: "refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, sentinel: ErrConnect, class: ErrorClassConnRefused},
+		{name: "unreachable", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.EHOSTUNREACH)}, sentinel: ErrConnect, class: ErrorClassNetwork},
+		{name: "tls", err: x509.UnknownAuthorityError{}, sentinel: ErrTLS, class: ErrorClassTLS},
+		{name: "dial timeout", err: fmt.Errorf("%w: boom", ErrDialTimeout), sentinel: ErrTimeout, class: ErrorClassTimeout},
+		{name: "proxyconnect", err: &net.OpError{Op: "proxyconnect", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, sentinel: ErrProxy, class: ErrorClassProxy},
+		{name: "socks", err: &net.OpError{Op: "socks connect", Err: errors.New("general failure")}, sentinel: ErrProxy, class: ErrorClassProxy},
+	}
+	for _, tt := range tests {
+		err := client.wrapError(tt.err)
+		if !errors.Is(err, tt.sentinel) || !errors.Is(err, tt.err) {
+			t.Fatalf("%s: wrapError() = %v, want %v wrapping the original error", tt.name, err, tt.sentinel)
+		}
+		if err.Error() != tt.err.Error() {
+			t.Fatalf("%s: Error() = %q, want unchanged %q", tt.name, err.Error(), tt.err.Error())
+		}
+		if got := Classify(err); got != tt.class {
+			t.Fatalf("%s: Classify() = %v, want %v", tt.name, got, tt.class)
+		}
+	}
+
+	proxyTimeout := &net.OpError{Op: "proxyconnect", Err: os.ErrDeadlineExceeded}
+	if err := client.wrapError(proxyTimeout); !errors.Is(err, ErrTimeout) || Classify(err) != ErrorClassTimeout {
+		t.Fatalf("wrapError(proxy timeout) = %v, class %v, want ErrTimeout", err, Classify(err))
+	}
+
+	plain := errors.New("boom")
+	if err := client.wrapError(plain); err != plain {
+		t.Fatalf("wrapError(plain) = %v, want unchanged", err)
+	}
+}
+
+func TestClientErrorsCarrySentinels(t *testing.T) {
+	addr := closedAddr(t)
+	_, err := New(WithRetryOptions(RetryOptions{MaxAttempts: 0})).GET("http://" + addr).Bytes()
+	if !errors.Is(err, ErrConnect) || Classify(err) != ErrorClassConnRefused {
+		t.Fatalf("no retry: err = %v, want ErrConnect", err)
+	}
+
+	_, err = New(WithRetryOptions(RetryOptions{MaxAttempts: 1, BaseDelay: time.Millisecond})).GET("http://" + addr).Bytes()
+	if !errors.Is(err, ErrConnect) || !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("retried: err = %v, want ErrConnect and ErrMaxRetriesExceeded", err)
+	}
+
+	_, err = New(WithRetryOptions(RetryOptions{MaxAttempts: 0}), WithHTTPProxy("http://"+addr)).GET("http://example.com/").Bytes()
+	if !errors.Is(err, ErrProxy) || Classify(err) != ErrorClassProxy {
+		t.Fatalf("proxy: err = %v, want ErrProxy", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		_, _ = w.Write([]byte("short"))
+	}))
+	defer srv.Close()
+	_, err = New().GET(srv.URL).Bytes()
+	if !errors.Is(err, ErrBodyRead) || Classify(err) != ErrorClassBodyRead {
+		t.Fatalf("body: err = %v, want ErrBodyRead", err)
+	}
+}
+
+// countingMiddleware 统计经过中间件的尝试次数
+func countingMiddleware(n *atomic.Int32) MiddlewareFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			n.Add(1)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestRetryOnConnRefusedForBodylessRequest(t *testing.T) {
+	var attempts atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithMiddleware(countingMiddleware(&attempts)),
+	)
+
+	if _, err := client.GET("http://" + closedAddr(t)).Execute(); err == nil {
+		t.Fatal("Execute() error = nil, want connection refused")
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+}
+
+func TestRetryOnProxyError(t *testing.T) {
+	var attempts atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithHTTPProxy("http://"+closedAddr(t)),
+		WithMiddleware(countingMiddleware(&attempts)),
+	)
+
+	_, err := client.GET("http://example.com/").Execute()
+	if !errors.Is(err, ErrProxy) || !errors.Is(err, ErrMaxRetriesExceeded) {
+		t.Fatalf("Execute() error = %v, want ErrProxy and ErrMaxRetriesExceeded", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+}
+
+func TestNoRetryForBodylessNonIdempotentRequest(t *testing.T) {
+	var attempts atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithMiddleware(countingMiddleware(&attempts)),
+	)
+
+	if _, err := client.POST("http://" + closedAddr(t)).Execute(); err == nil {
+		t.Fatal("Execute() error = nil, want connection refused")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+}
+
+func TestRetryErrorsOverrideDisablesClass(t *testing.T) {
+	var attempts atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{
+			MaxAttempts: 2,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    time.Millisecond,
+			RetryErrors: map[ErrorClass]bool{ErrorClassConnRefused: false},
+		}),
+		WithMiddleware(countingMiddleware(&attempts)),
+	)
+
+	if _, err := client.GET("http://" + closedAddr(t)).Execute(); err == nil {
+		t.Fatal("Execute() error = nil, want connection refused")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+}
+
+func TestNoRetryOnCertificateError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var attempts atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		WithMiddleware(countingMiddleware(&attempts)),
+	)
+
+	_, err := client.GET(server.URL).Execute()
+	if Classify(err) != ErrorClassTLS {
+		t.Fatalf("Execute() error = %v, want TLS error", err)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts = %d, want 1", got)
+	}
+}
@@ -55,14 +55,17 @@ func (c *Client) errorResponse(resp *http.Response) error {
 	// 定义为错误预览读取的最大字节数
 	const maxErrorBodyRead = 1 * 1024 // 读取最多 1KB
 
-	buf := c.bufferPool.Get()
-	defer c.bufferPool.Put(buf)
-
-	limitedReader := io.LimitReader(resp.Body, maxErrorBodyRead)
-	readErr := func() error { // 使用匿名函数捕获读取错误
-		_, err := iox.Copy(buf, limitedReader)
-		return err
-	}() // 立即执行
+	// 预览直接读入最终切片, 避免经由 buffer 再复制一次
+	previewSize := int64(maxErrorBodyRead)
+	if resp.ContentLength >= 0 && resp.ContentLength < previewSize {
+		previewSize = resp.ContentLength
+	}
+	bodyBytes := make([]byte, previewSize)
+	n, readErr := io.ReadFull(resp.Body, bodyBytes)
+	bodyBytes = bodyBytes[:n]
+	if errors.Is(readErr, io.ErrUnexpectedEOF) {
+		readErr = nil // 响应体短于预览长度
+	}
 
 	// *** 关键: 丢弃剩余的响应体 ***
 	const maxDiscardSize = 64 * 1024
@@ -86,16 +89,10 @@ func (c *Client) errorResponse(resp *http.Response) error {
 		c.dumpLog(reqCtx, logMsg) // 使用获取到的或默认的 Context
 	}
 
-	// 复制 Body 预览
-	bodyBytes := make([]byte, buf.Len())
-	copy(bodyBytes, buf.Bytes()) // 从 buf 复制，buf 会被回收
-
 	// 复制 Header
-	headerCopy := make(http.Header)
-	if resp.Header != nil {
-		for k, v := range resp.Header {
-			headerCopy[k] = append([]string(nil), v...)
-		}
+	headerCopy := resp.Header.Clone()
+	if headerCopy == nil {
+		headerCopy = make(http.Header)
 	}
 
 	// 创建结构化错误
@@ -0,0 +1,148 @@
+package httpc
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeForm 解析 application/x-www-form-urlencoded 响应
+// 常见于 OAuth token 端点及部分遗留 API
+func (rb *RequestBuilder) DecodeForm() (url.Values, error) {
+	resp, err := rb.Execute()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return rb.client.decodeFormResponse(resp)
+}
+
+// DecodeFormInto 解析 application/x-www-form-urlencoded 响应并绑定到结构体
+// 字段名通过 `form:"name"` 标签指定, 未指定时使用字段名; `form:"-"` 表示忽略该字段
+func (rb *RequestBuilder) DecodeFormInto(v any) error {
+	values, err := rb.DecodeForm()
+	if err != nil {
+		return err
+	}
+	if err := bindForm(values, v); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
+	return nil
+}
+
+func (c *Client) decodeFormResponse(resp *http.Response) (url.Values, error) {
+	if resp.StatusCode >= 400 {
+		return nil, c.errorResponse(resp)
+	}
+	buf, err := c.readPooled(resp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+	}
+	defer c.bufferPool.Put(buf)
+	values, err := url.ParseQuery(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
+	return values, nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// bindForm 将表单值绑定到结构体指针 v 的导出字段
+func bindForm(values url.Values, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("form target must be a non-nil pointer to struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("form"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := setFormField(rv.Field(i), vals); err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func setFormField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Slice && !reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setFormValue(slice.Index(i), val); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setFormValue(fv, vals[0])
+}
+
+// setFormValue 将单个字符串值写入 fv, 支持基础类型、指针及 encoding.TextUnmarshaler
+func setFormValue(fv reflect.Value, val string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setFormValue(fv.Elem(), val)
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported kind %s", fv.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package httpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestBuilderDecodeFormInto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = io.WriteString(w, "access_token=abc%20123&expires_in=3600&scope=read&scope=write&debug=true")
+	}))
+	defer server.Close()
+
+	values, err := New().POST(server.URL).DecodeForm()
+	if err != nil {
+		t.Fatalf("DecodeForm() error = %v", err)
+	}
+	if got := values.Get("access_token"); got != "abc 123" {
+		t.Fatalf("access_token = %q, want %q", got, "abc 123")
+	}
+
+	var token struct {
+		AccessToken string   `form:"access_token"`
+		ExpiresIn   int      `form:"expires_in"`
+		Scope       []string `form:"scope"`
+		Debug       *bool    `form:"debug"`
+		Ignored     string   `form:"-"`
+	}
+	if err := New().POST(server.URL).DecodeFormInto(&token); err != nil {
+		t.Fatalf("DecodeFormInto() error = %v", err)
+	}
+	if token.AccessToken != "abc 123" || token.ExpiresIn != 3600 {
+		t.Fatalf("token = %+v, want access_token and expires_in bound", token)
+	}
+	if len(token.Scope) != 2 || token.Scope[0] != "read" || token.Scope[1] != "write" {
+		t.Fatalf("Scope = %#v, want [read write]", token.Scope)
+	}
+	if token.Debug == nil || !*token.Debug {
+		t.Fatalf("Debug = %v, want true", token.Debug)
+	}
+}
+
+func TestRequestBuilderDecodeFormIntoRejectsInvalidValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "expires_in=soon")
+	}))
+	defer server.Close()
+
+	var token struct {
+		ExpiresIn int `form:"expires_in"`
+	}
+	err := New().GET(server.URL).DecodeFormInto(&token)
+	if !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("DecodeFormInto() error = %v, want ErrDecodeResponse", err)
+	}
+}
@@ -0,0 +1,153 @@
+package httpc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// frameHeaderSize 是帧长度前缀的字节数 (大端 uint32)
+const frameHeaderSize = 4
+
+// defaultMaxFrameSize 是 FrameReader 默认允许的最大帧长度
+const defaultMaxFrameSize = 16 << 20
+
+// ErrFrameTooLarge 表示读取到的帧长度超过了上限
+var ErrFrameTooLarge = errors.New("httpc: frame too large")
+
+// FrameWriter 将长度前缀帧 (4 字节大端长度 + 数据) 写入流, 并发调用 WriteFrame 是安全的
+type FrameWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	pw     *io.PipeWriter // SetFrameBody 创建的管道, 为 nil 时 Close 不做任何事
+	header [frameHeaderSize]byte
+}
+
+// NewFrameWriter 返回向 w 写入长度前缀帧的 FrameWriter, 可用于服务端或其他传输
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// WriteFrame 写入一个帧
+func (w *FrameWriter) WriteFrame(frame []byte) error {
+	if uint64(len(frame)) > 1<<32-1 {
+		return fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, len(frame))
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	binary.BigEndian.PutUint32(w.header[:], uint32(len(frame)))
+	if _, err := w.w.Write(w.header[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(frame)
+	return err
+}
+
+// Close 结束请求体, 服务端随后读到 EOF
+func (w *FrameWriter) Close() error {
+	return w.CloseWithError(nil)
+}
+
+// CloseWithError 以错误结束请求体, 正在发送的请求将以该错误失败
+func (w *FrameWriter) CloseWithError(err error) error {
+	if w.pw == nil {
+		return nil
+	}
+	return w.pw.CloseWithError(err)
+}
+
+// SetFrameBody 将请求体设置为一个管道并返回向其写入帧的 FrameWriter, 未设置 Content-Type 时使用 application/octet-stream.
+// 帧需要在另一个 goroutine 中写入, 写完后调用 Close; 管道请求体无法重放, 因此不会重试
+func (rb *RequestBuilder) SetFrameBody() *FrameWriter {
+	pr, pw := io.Pipe()
+	rb.setBody(pr)
+	if rb.header.Get("Content-Type") == "" {
+		rb.header.Set("Content-Type", "application/octet-stream")
+	}
+	return &FrameWriter{w: pw, pw: pw}
+}
+
+// FrameReader 从流中逐个读取长度前缀帧
+type FrameReader struct {
+	resp    *http.Response
+	reader  *bufio.Reader
+	buf     []byte
+	maxSize int
+	closed  atomic.Bool
+}
+
+// NewFrameReader 返回从 r 读取长度前缀帧的 FrameReader, 可用于服务端或其他传输
+func NewFrameReader(r io.Reader) *FrameReader {
+	return &FrameReader{reader: bufio.NewReader(r), maxSize: defaultMaxFrameSize}
+}
+
+// SetMaxFrameSize 设置允许的最大帧长度 (默认 16MB), 超过时 Next 返回 ErrFrameTooLarge
+func (r *FrameReader) SetMaxFrameSize(n int) {
+	r.maxSize = n
+}
+
+// Response 返回建立流时的原始 HTTP 响应, 由 NewFrameReader 创建时为 nil
+func (r *FrameReader) Response() *http.Response {
+	if r == nil {
+		return nil
+	}
+	return r.resp
+}
+
+// Next 读取下一个帧, 返回的切片在下一次调用 Next 前有效.
+// 流在帧边界处结束时返回 io.EOF, 在帧中间结束时返回 io.ErrUnexpectedEOF
+func (r *FrameReader) Next() ([]byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r.reader, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if uint64(size) > uint64(r.maxSize) {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit %d", ErrFrameTooLarge, size, r.maxSize)
+	}
+	if cap(r.buf) < int(size) {
+		r.buf = make([]byte, size)
+	}
+	frame := r.buf[:size]
+	if _, err := io.ReadFull(r.reader, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+// Close 关闭底层响应体
+func (r *FrameReader) Close() error {
+	if r == nil || r.resp == nil || r.resp.Body == nil {
+		return nil
+	}
+	if r.closed.Swap(true) {
+		return nil
+	}
+	return r.resp.Body.Close()
+}
+
+// Frames 执行请求并返回逐个读取响应帧的 FrameReader, 调用方负责 Close
+// 状态码 >= 400 时返回 HTTPError
+func (rb *RequestBuilder) Frames() (*FrameReader, error) {
+	resp, err := rb.Execute()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		httpErr := rb.client.errorResponse(resp)
+		resp.Body.Close()
+		return nil, httpErr
+	}
+
+	r := NewFrameReader(resp.Body)
+	r.resp = resp
+	return r, nil
+}
@@ -0,0 +1,84 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	// 服务端将每个请求帧加上前缀后写回
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/octet-stream" {
+			t.Errorf("Content-Type = %q, want application/octet-stream", got)
+		}
+		reader := NewFrameReader(r.Body)
+		writer := NewFrameWriter(w)
+		for {
+			frame, err := reader.Next()
+			if err != nil {
+				if err != io.EOF {
+					t.Errorf("server Next() error = %v", err)
+				}
+				return
+			}
+			_ = writer.WriteFrame(append([]byte("echo:"), frame...))
+		}
+	}))
+	defer server.Close()
+
+	rb := New().POST(server.URL)
+	writer := rb.SetFrameBody()
+	go func() {
+		for i := range 3 {
+			if err := writer.WriteFrame(fmt.Appendf(nil, "frame-%d", i)); err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+		writer.WriteFrame(nil)
+		writer.Close()
+	}()
+
+	frames, err := rb.Frames()
+	if err != nil {
+		t.Fatalf("Frames() error = %v", err)
+	}
+	defer frames.Close()
+
+	var got []string
+	for {
+		frame, err := frames.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, string(frame))
+	}
+	want := []string{"echo:frame-0", "echo:frame-1", "echo:frame-2", "echo:"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("frames = %q, want %q", got, want)
+	}
+}
+
+func TestFrameReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	_ = NewFrameWriter(&buf).WriteFrame([]byte("truncated"))
+
+	reader := NewFrameReader(bytes.NewReader(buf.Bytes()[:buf.Len()-2]))
+	if _, err := reader.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Next() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	reader = NewFrameReader(bytes.NewReader(buf.Bytes()))
+	reader.SetMaxFrameSize(4)
+	if _, err := reader.Next(); !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("Next() error = %v, want %v", err, ErrFrameTooLarge)
+	}
+}
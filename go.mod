@@ -8,4 +8,5 @@ require (
 	github.com/WJQSERVER-STUDIO/go-utils/iox v0.0.2
 	github.com/go-json-experiment/json v0.0.0-20260214004413-d219187c3433
 	golang.org/x/net v0.52.0
+	golang.org/x/text v0.36.0
 )
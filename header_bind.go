@@ -0,0 +1,141 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeWithHeaders 按 DecodeAuto 的规则解码响应体到 body, 并将响应头绑定到 meta.
+// meta 的字段通过 `header:"X-RateLimit-Remaining"` 标签指定响应头, 未打标签的字段被忽略.
+// 响应头在检查状态码之前绑定, 错误响应 (例如 429) 的限速信息同样可用; body 为 nil 时丢弃响应体
+func (rb *RequestBuilder) DecodeWithHeaders(body, meta any) error {
+	resp, err := rb.Execute()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if meta != nil {
+		if err := bindHeaders(resp.Header, meta); err != nil {
+			return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+		}
+	}
+	if body != nil {
+		return rb.client.decodeAutoResponse(resp, rb.accept, body)
+	}
+	if resp.StatusCode >= 400 {
+		return rb.client.errorResponse(resp)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// bindHeaders 将响应头绑定到结构体指针 v 中带 header 标签的导出字段
+func bindHeaders(header http.Header, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("header target must be a non-nil pointer to struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, ok := field.Tag.Lookup("header")
+		if !field.IsExported() || !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		vals := header.Values(name)
+		if len(vals) == 0 {
+			continue
+		}
+		if err := setHeaderField(rv.Field(i), vals); err != nil {
+			return fmt.Errorf("field %s (header %s): %w", field.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// setHeaderField 写入字段, 切片字段接收所有值, 逗号分隔的列表会被拆分
+func setHeaderField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Slice && !reflect.PointerTo(fv.Type()).Implements(textUnmarshalerType) {
+		var items []string
+		for _, val := range vals {
+			for item := range strings.SplitSeq(val, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setHeaderValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setHeaderValue(fv, strings.TrimSpace(vals[0]))
+}
+
+// setHeaderValue 在 setFormValue 的基础上支持响应头常见的时间格式:
+// time.Time 接受 HTTP 日期, RFC 3339 与 Unix 秒; time.Duration 接受秒数与 Go 时长字符串
+func setHeaderValue(fv reflect.Value, val string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setHeaderValue(fv.Elem(), val)
+	}
+
+	switch fv.Type() {
+	case timeType:
+		t, err := parseHeaderTime(val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if secs, err := strconv.ParseFloat(val, 64); err == nil {
+			fv.SetInt(int64(secs * float64(time.Second)))
+			return nil
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	return setFormValue(fv, val)
+}
+
+func parseHeaderTime(val string) (time.Time, error) {
+	if t, err := http.ParseTime(val); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, val); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", val)
+}
@@ -0,0 +1,102 @@
+package httpc
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type rateLimitMeta struct {
+	Remaining  int           `header:"X-RateLimit-Remaining"`
+	Reset      time.Time     `header:"X-RateLimit-Reset"`
+	RetryAfter time.Duration `header:"Retry-After"`
+	ETag       string        `header:"ETag"`
+	Modified   *time.Time    `header:"Last-Modified"`
+	Vary       []string      `header:"Vary"`
+	Missing    *int          `header:"X-Missing"`
+	Untagged   string
+}
+
+func TestDecodeWithHeaders(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Header().Set("Retry-After", "1.5")
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Header().Add("Vary", "Accept, Accept-Encoding")
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Untagged", "x")
+		_, _ = io.WriteString(w, `{"id":1,"name":"a"}`)
+	}))
+	defer srv.Close()
+
+	var body callUser
+	var meta rateLimitMeta
+	if err := New().GET(srv.URL).DecodeWithHeaders(&body, &meta); err != nil {
+		t.Fatalf("DecodeWithHeaders() error = %v", err)
+	}
+	if body.Name != "a" {
+		t.Fatalf("Name = %q, want %q", body.Name, "a")
+	}
+	if meta.Remaining != 42 {
+		t.Fatalf("Remaining = %d, want 42", meta.Remaining)
+	}
+	if !meta.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("Reset = %v, want unix 1700000000", meta.Reset)
+	}
+	if meta.RetryAfter != 1500*time.Millisecond {
+		t.Fatalf("RetryAfter = %v, want 1.5s", meta.RetryAfter)
+	}
+	if meta.ETag != `"abc"` {
+		t.Fatalf("ETag = %q, want %q", meta.ETag, `"abc"`)
+	}
+	if meta.Modified == nil || !meta.Modified.Equal(modified) {
+		t.Fatalf("Modified = %v, want %v", meta.Modified, modified)
+	}
+	if len(meta.Vary) != 3 || meta.Vary[2] != "Origin" {
+		t.Fatalf("Vary = %q, want [Accept Accept-Encoding Origin]", meta.Vary)
+	}
+	if meta.Missing != nil || meta.Untagged != "" {
+		t.Fatalf("Missing = %v, Untagged = %q, want unset", meta.Missing, meta.Untagged)
+	}
+}
+
+func TestDecodeWithHeadersErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30s")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var meta rateLimitMeta
+	err := New(WithRetryOptions(RetryOptions{MaxAttempts: 0})).GET(srv.URL).DecodeWithHeaders(nil, &meta)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want HTTPError 429", err)
+	}
+	if meta.RetryAfter != 30*time.Second {
+		t.Fatalf("RetryAfter = %v, want 30s", meta.RetryAfter)
+	}
+}
+
+func TestDecodeWithHeadersInvalid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "many")
+	}))
+	defer srv.Close()
+
+	var meta rateLimitMeta
+	if err := New().GET(srv.URL).DecodeWithHeaders(nil, &meta); !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("err = %v, want ErrDecodeResponse", err)
+	}
+	if err := New().GET(srv.URL).DecodeWithHeaders(nil, meta); !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("non-pointer meta: err = %v, want ErrDecodeResponse", err)
+	}
+}
@@ -2,13 +2,19 @@ package httpc
 
 import (
 	"context"
+	"errors"
 	"io"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/go-json-experiment/json"
+	"golang.org/x/text/encoding/simplifiedchinese"
 )
 
 func TestRequestBuilderBuildMergesQueryAndDefaultHeaders(t *testing.T) {
@@ -43,6 +49,32 @@ func TestRequestBuilderBuildMergesQueryAndDefaultHeaders(t *testing.T) {
 	}
 }
 
+func TestRequestBuilderRawQueryIsPreserved(t *testing.T) {
+	client := New()
+
+	req, err := client.GET("https://bucket.example.com/key?X-Amz-Signature=ab%2Fcd&X-Amz-Date=20260101T000000Z").
+		AddQueryParam("b", "x y").
+		AddRawQueryParam("a", "1%2C2").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := "X-Amz-Signature=ab%2Fcd&X-Amz-Date=20260101T000000Z&b=x+y&a=1%2C2"
+	if req.URL.RawQuery != want {
+		t.Fatalf("RawQuery = %q, want %q", req.URL.RawQuery, want)
+	}
+
+	req, err = client.GET("https://example.com/path?ignored=1").
+		SetRawQuery("z=1&a=%7E").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if req.URL.RawQuery != "z=1&a=%7E" {
+		t.Fatalf("RawQuery = %q, want %q", req.URL.RawQuery, "z=1&a=%7E")
+	}
+}
+
 func TestRequestBuilderNoDefaultHeaders(t *testing.T) {
 	client := New(WithUserAgent("test-agent/1.0"))
 
@@ -89,6 +121,135 @@ func TestOptionsStackTransportMergeAndProtocols(t *testing.T) {
 	}
 }
 
+func TestWithResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := New(WithResponseHeaderTimeout(50*time.Millisecond), WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	if got := client.transport.ResponseHeaderTimeout; got != 50*time.Millisecond {
+		t.Fatalf("ResponseHeaderTimeout = %v, want %v", got, 50*time.Millisecond)
+	}
+
+	_, err := client.GET(server.URL).Execute()
+	if err == nil {
+		t.Fatal("Execute() error = nil, want response header timeout")
+	}
+	if got := Classify(err); got != ErrorClassTimeout {
+		t.Fatalf("Classify() = %v, want %v", got, ErrorClassTimeout)
+	}
+}
+
+func TestWithDisableCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Accept-Encoding"))
+	}))
+	defer server.Close()
+
+	got, err := New().GET(server.URL).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "gzip" {
+		t.Fatalf("Accept-Encoding = %q, want %q", got, "gzip")
+	}
+
+	got, err = New(WithDisableCompression()).GET(server.URL).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "" {
+		t.Fatalf("Accept-Encoding = %q, want empty", got)
+	}
+}
+
+func TestWithDisableKeepAlives(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := New(WithDisableKeepAlives())
+	for range 3 {
+		if _, err := client.GET(server.URL).Bytes(); err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+	}
+	if got := conns.Load(); got != 3 {
+		t.Fatalf("connections = %d, want 3", got)
+	}
+}
+
+func TestConnectionLimitOptions(t *testing.T) {
+	client := New(
+		WithMaxIdleConns(64),
+		WithMaxIdleConnsPerHost(8),
+		WithMaxConnsPerHost(16),
+	)
+
+	if got := client.transport.MaxIdleConns; got != 64 {
+		t.Fatalf("MaxIdleConns = %d, want 64", got)
+	}
+	if got := client.transport.MaxIdleConnsPerHost; got != 8 {
+		t.Fatalf("MaxIdleConnsPerHost = %d, want 8", got)
+	}
+	if got := client.transport.MaxConnsPerHost; got != 16 {
+		t.Fatalf("MaxConnsPerHost = %d, want 16", got)
+	}
+}
+
+func TestWithTransportBufferSizes(t *testing.T) {
+	client := New(WithTransportBufferSizes(128<<10, 0))
+
+	if got := client.transport.ReadBufferSize; got != 128<<10 {
+		t.Fatalf("ReadBufferSize = %d, want %d", got, 128<<10)
+	}
+	if got := client.transport.WriteBufferSize; got != 32<<10 {
+		t.Fatalf("WriteBufferSize = %d, want %d", got, 32<<10)
+	}
+}
+
+func TestWithMaxConnsPerHostQueuesRequests(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := New(WithMaxConnsPerHost(2), WithProtocols(ProtocolsConfig{Http1: true}))
+	errs := make(chan error, 6)
+	for range 6 {
+		go func() {
+			_, err := client.GET(server.URL).Bytes()
+			errs <- err
+		}()
+	}
+	for range 6 {
+		if err := <-errs; err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+	}
+	if got := peak.Load(); got > 2 {
+		t.Fatalf("peak concurrent requests = %d, want <= 2", got)
+	}
+}
+
 func TestWithProtocolsForceH2COverridesOtherProtocols(t *testing.T) {
 	client := New(WithProtocols(ProtocolsConfig{
 		Http1:           true,
@@ -221,7 +382,7 @@ func TestCalculateExponentialBackoffWithoutJitter(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		if got := client.calculateExponentialBackoff(tt.attempt, false); got != tt.want {
+		if got := client.retryDelay(&client.settings().retryOpts, tt.attempt, 0, nil); got != tt.want {
 			t.Fatalf("attempt %d: backoff = %v, want %v", tt.attempt, got, tt.want)
 		}
 	}
@@ -235,7 +396,7 @@ func TestCalculateExponentialBackoffWithJitterUsesRandomFactor(t *testing.T) {
 	}))
 	client.randomFloat64 = func() float64 { return 0.25 }
 
-	got := client.calculateExponentialBackoff(1, true)
+	got := client.retryDelay(&client.settings().retryOpts, 1, 0, nil)
 	want := 300 * time.Millisecond
 	if got != want {
 		t.Fatalf("backoff with jitter = %v, want %v", got, want)
@@ -250,8 +411,199 @@ func TestCalculateExponentialBackoffWithJitterStillHonorsMaxDelay(t *testing.T)
 	}))
 	client.randomFloat64 = func() float64 { return 0.99 }
 
-	got := client.calculateExponentialBackoff(3, true)
+	got := client.retryDelay(&client.settings().retryOpts, 3, 0, nil)
 	if got != 800*time.Millisecond {
 		t.Fatalf("backoff with jitter cap = %v, want %v", got, 800*time.Millisecond)
 	}
 }
+
+func TestWithJSONOptionsAppliesToEncodeAndDecode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("ReadAll() error = %v", err)
+			return
+		}
+		if got := string(body); got != `{"id":"42"}` {
+			t.Errorf("body = %s, want stringified int64", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"7","extra":true}`))
+	}))
+	defer server.Close()
+
+	client := New(WithJSONOptions(
+		[]json.Options{json.StringifyNumbers(true)},
+		[]json.Options{json.StringifyNumbers(true), json.RejectUnknownMembers(true)},
+	))
+
+	type payload struct {
+		ID int64 `json:"id"`
+	}
+	builder, err := client.POST(server.URL).SetJSONBody(payload{ID: 42})
+	if err != nil {
+		t.Fatalf("SetJSONBody() error = %v", err)
+	}
+
+	var resp payload
+	err = builder.DecodeJSON(&resp)
+	if !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("DecodeJSON() error = %v, want ErrDecodeResponse for unknown member", err)
+	}
+}
+
+func TestDecodeXMLConvertsDeclaredCharset(t *testing.T) {
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(`<?xml version="1.0" encoding="GBK"?><item><title>你好</title></item>`)
+	if err != nil {
+		t.Fatalf("GBK encode error = %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = io.WriteString(w, encoded)
+	}))
+	defer server.Close()
+
+	var item struct {
+		Title string `xml:"title"`
+	}
+	if err := New().GET(server.URL).DecodeXML(&item); err != nil {
+		t.Fatalf("DecodeXML() error = %v", err)
+	}
+	if item.Title != "你好" {
+		t.Fatalf("Title = %q, want %q", item.Title, "你好")
+	}
+
+	err = New(WithXMLCharsetReader(nil)).GET(server.URL).DecodeXML(&item)
+	if !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("DecodeXML() without charset reader error = %v, want ErrDecodeResponse", err)
+	}
+}
+
+func TestRetryDelayUsesStatusDelays(t *testing.T) {
+	client := New(WithRetryOptions(RetryOptions{
+		BaseDelay: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+		StatusDelays: map[int]RetryDelay{
+			http.StatusTooManyRequests:    {BaseDelay: 30 * time.Second, Fixed: true},
+			http.StatusServiceUnavailable: {BaseDelay: 10 * time.Millisecond, MaxDelay: 30 * time.Millisecond},
+		},
+	}))
+
+	tests := []struct {
+		status  int
+		attempt int
+		want    time.Duration
+	}{
+		{status: http.StatusTooManyRequests, attempt: 0, want: 30 * time.Second},
+		{status: http.StatusTooManyRequests, attempt: 3, want: 30 * time.Second},
+		{status: http.StatusServiceUnavailable, attempt: 0, want: 10 * time.Millisecond},
+		{status: http.StatusServiceUnavailable, attempt: 1, want: 20 * time.Millisecond},
+		{status: http.StatusServiceUnavailable, attempt: 2, want: 30 * time.Millisecond},
+		{status: http.StatusBadGateway, attempt: 2, want: 400 * time.Millisecond}, // 无 Retry-After 时使用默认退避
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		if got := client.retryDelay(&client.settings().retryOpts, tt.attempt, 0, resp); got != tt.want {
+			t.Fatalf("status %d attempt %d: delay = %v, want %v", tt.status, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelayJittersResponsesWithoutRetryAfter(t *testing.T) {
+	client := New(WithRetryOptions(RetryOptions{
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       time.Second,
+		Jitter:         true,
+		JitterStrategy: JitterFull,
+	}))
+	client.randomFloat64 = func() float64 { return 0.25 }
+
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	if got := client.retryDelay(&client.settings().retryOpts, 2, 0, resp); got != 100*time.Millisecond {
+		t.Fatalf("delay = %v, want %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestRetryDelayCapsRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"86400"}}}
+
+	client := New(WithRetryOptions(RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second}))
+	if got := client.retryDelay(&client.settings().retryOpts, 0, 0, resp); got != defaultMaxRetryAfter {
+		t.Fatalf("default cap: delay = %v, want %v", got, defaultMaxRetryAfter)
+	}
+
+	client.SetRetryOptions(RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second, MaxRetryAfter: 10 * time.Second})
+	if got := client.retryDelay(&client.settings().retryOpts, 0, 0, resp); got != 10*time.Second {
+		t.Fatalf("custom cap: delay = %v, want %v", got, 10*time.Second)
+	}
+
+	client.SetRetryOptions(RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second, MaxRetryAfter: -1})
+	if got := client.retryDelay(&client.settings().retryOpts, 0, 0, resp); got != 24*time.Hour {
+		t.Fatalf("no cap: delay = %v, want %v", got, 24*time.Hour)
+	}
+}
+
+func TestBackoffFullJitter(t *testing.T) {
+	client := New(WithRetryOptions(RetryOptions{
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       time.Second,
+		Jitter:         true,
+		JitterStrategy: JitterFull,
+	}))
+	client.randomFloat64 = func() float64 { return 0.25 }
+
+	if got := client.retryDelay(&client.settings().retryOpts, 2, 0, nil); got != 100*time.Millisecond {
+		t.Fatalf("full jitter backoff = %v, want %v", got, 100*time.Millisecond)
+	}
+}
+
+func TestBackoffDecorrelatedJitter(t *testing.T) {
+	client := New(WithRetryOptions(RetryOptions{
+		BaseDelay:      100 * time.Millisecond,
+		MaxDelay:       time.Second,
+		Jitter:         true,
+		JitterStrategy: JitterDecorrelated,
+	}))
+	client.randomFloat64 = func() float64 { return 0.5 }
+
+	// rand[100ms, 300ms) 取中点
+	if got := client.retryDelay(&client.settings().retryOpts, 0, 100*time.Millisecond, nil); got != 200*time.Millisecond {
+		t.Fatalf("decorrelated delay = %v, want %v", got, 200*time.Millisecond)
+	}
+	// rand[100ms, 1.2s) 取中点, 受 MaxDelay 约束前为 650ms
+	if got := client.retryDelay(&client.settings().retryOpts, 3, 400*time.Millisecond, nil); got != 650*time.Millisecond {
+		t.Fatalf("decorrelated delay = %v, want %v", got, 650*time.Millisecond)
+	}
+	client.randomFloat64 = func() float64 { return 0.99 }
+	if got := client.retryDelay(&client.settings().retryOpts, 3, 800*time.Millisecond, nil); got != time.Second {
+		t.Fatalf("decorrelated delay cap = %v, want %v", got, time.Second)
+	}
+}
+
+func TestWithRandSourceIsDeterministic(t *testing.T) {
+	newClient := func() *Client {
+		return New(
+			WithRetryOptions(RetryOptions{
+				BaseDelay:      100 * time.Millisecond,
+				MaxDelay:       10 * time.Second,
+				Jitter:         true,
+				JitterStrategy: JitterFull,
+			}),
+			WithRandSource(rand.NewPCG(1, 2)),
+		)
+	}
+	a, b := newClient(), newClient()
+
+	distinct := make(map[time.Duration]bool)
+	for attempt := range 5 {
+		got, want := a.retryDelay(&a.settings().retryOpts, attempt, 0, nil), b.retryDelay(&b.settings().retryOpts, attempt, 0, nil)
+		if got != want {
+			t.Fatalf("attempt %d: backoff = %v, want %v from identically seeded client", attempt, got, want)
+		}
+		distinct[got] = true
+	}
+	if len(distinct) < 2 {
+		t.Fatalf("backoff values = %v, want randomized delays", distinct)
+	}
+}
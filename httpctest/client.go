@@ -0,0 +1,32 @@
+package httpctest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/httpc"
+)
+
+// 测试客户端的默认配置
+const (
+	testTimeout     = 5 * time.Second
+	testDialTimeout = time.Second
+)
+
+// NewTestClient 创建一个适合测试的客户端: 较短的超时与毫秒级的重试退避
+// opts 在默认配置之后应用, 可覆盖上述设置
+func NewTestClient(t testing.TB, opts ...httpc.Option) *httpc.Client {
+	t.Helper()
+	defaults := []httpc.Option{
+		httpc.WithTimeout(testTimeout),
+		httpc.WithDialTimeout(testDialTimeout),
+		httpc.WithTLSHandshakeTimeout(testDialTimeout),
+		httpc.WithRetryOptions(httpc.RetryOptions{
+			MaxAttempts:   2,
+			BaseDelay:     time.Millisecond,
+			MaxDelay:      10 * time.Millisecond,
+			RetryStatuses: []int{429, 500, 502, 503, 504},
+		}),
+	}
+	return httpc.New(append(defaults, opts...)...)
+}
@@ -0,0 +1,49 @@
+package httpctest
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateGoldenEnv 设置为非空值时, AssertGolden 用实际内容覆盖 golden 文件
+const UpdateGoldenEnv = "HTTPCTEST_UPDATE_GOLDEN"
+
+// FileResponse 读取 fixture 文件作为响应体, 例如 testdata/user.json
+func FileResponse(t testing.TB, status int, contentType, path string) Response {
+	t.Helper()
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("httpctest: read fixture %s: %v", path, err)
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	return Response{Status: status, Header: header, Body: body}
+}
+
+// AssertGolden 断言 got 与 golden 文件的内容一致
+// 设置环境变量 HTTPCTEST_UPDATE_GOLDEN=1 运行测试时会写入 got 以更新 golden 文件
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("httpctest: create golden dir: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("httpctest: update golden %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("httpctest: read golden %s: %v (set %s=1 to create it)", path, err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("httpctest: content does not match golden %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
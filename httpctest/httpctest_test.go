@@ -0,0 +1,104 @@
+package httpctest
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerRespondAndRecord(t *testing.T) {
+	server := NewServer(t)
+	server.Respond(http.MethodPost, "/users", Response{
+		Status: http.StatusCreated,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{"id":1}`),
+	})
+	client := NewTestClient(t)
+
+	resp, err := client.POST(server.URL+"/users").
+		SetHeader("X-Request-Id", "abc").
+		SetRawBody([]byte(`{"name":"gopher"}`)).
+		Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	server.AssertRequestCount(t, 1)
+	req := server.LastRequest(t)
+	req.AssertHeader(t, "X-Request-Id", "abc")
+	req.AssertBody(t, `{"name":"gopher"}`)
+	if req.Method != http.MethodPost || req.URL.Path != "/users" {
+		t.Fatalf("request = %s %s, want POST /users", req.Method, req.URL.Path)
+	}
+}
+
+func TestServerUnknownRouteIsNotFound(t *testing.T) {
+	server := NewServer(t)
+	resp, err := NewTestClient(t).GET(server.URL + "/missing").Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestRespondSequenceDrivesRetries(t *testing.T) {
+	server := NewServer(t)
+	server.RespondSequence(http.MethodGet, "/flaky",
+		Response{Status: http.StatusServiceUnavailable},
+		Response{Status: http.StatusOK, Body: []byte("ok")},
+	)
+
+	body, err := NewTestClient(t).GET(server.URL + "/flaky").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if body != "ok" {
+		t.Fatalf("Text() = %q, want %q", body, "ok")
+	}
+	server.AssertRequestCount(t, 2)
+}
+
+func TestServerLatency(t *testing.T) {
+	server := NewServer(t, WithLatency(50*time.Millisecond))
+	server.Respond("", "/", Response{Body: []byte("slow")})
+
+	start := time.Now()
+	if _, err := NewTestClient(t).GET(server.URL + "/").Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("elapsed = %v, want at least 50ms", elapsed)
+	}
+}
+
+func TestGoldenFixtures(t *testing.T) {
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "user.json")
+	if err := os.WriteFile(fixture, []byte(`{"name":"gopher"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(t)
+	server.Respond(http.MethodGet, "/user", FileResponse(t, http.StatusOK, "application/json", fixture))
+
+	body, err := NewTestClient(t).GET(server.URL + "/user").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+
+	golden := filepath.Join(dir, "golden", "user.golden")
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGolden(t, golden, []byte(strings.ToUpper(body)))
+	t.Setenv(UpdateGoldenEnv, "")
+	AssertGolden(t, golden, []byte(`{"NAME":"GOPHER"}`))
+}
@@ -0,0 +1,207 @@
+// Package httpctest 提供基于 httpc 编写测试的辅助工具:
+// 可编程的本地服务端, 请求记录与断言, golden 文件夹具, 以及使用短超时的测试客户端
+package httpctest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RecordedRequest 是服务端收到的一个请求的快照
+type RecordedRequest struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// Response 描述一个预设的响应
+type Response struct {
+	Status  int           // 状态码, 默认 200
+	Header  http.Header   // 响应头
+	Body    []byte        // 响应体
+	Latency time.Duration // 写响应前的延迟, 请求取消时提前返回
+}
+
+// Server 是一个可编程的本地测试服务端, 会记录收到的所有请求
+// 未注册的路由返回 404
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]http.Handler // "METHOD /path" 或 "/path"
+	latency  time.Duration
+	requests []RecordedRequest
+}
+
+// ServerOption 配置 Server
+type ServerOption func(*Server)
+
+// WithLatency 为所有响应增加固定延迟
+func WithLatency(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.latency = d
+	}
+}
+
+// NewServer 启动一个测试服务端, 并在测试结束时关闭
+func NewServer(t testing.TB, opts ...ServerOption) *Server {
+	t.Helper()
+	s := &Server{routes: make(map[string]http.Handler)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle 为 method 与 path 注册处理器, method 为空时匹配任意方法
+func (s *Server) Handle(method, path string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[routeKey(method, path)] = handler
+}
+
+// HandleFunc 为 method 与 path 注册处理函数
+func (s *Server) HandleFunc(method, path string, handler http.HandlerFunc) {
+	s.Handle(method, path, handler)
+}
+
+// Respond 为 method 与 path 注册固定响应
+func (s *Server) Respond(method, path string, resp Response) {
+	s.Handle(method, path, respondHandler(resp))
+}
+
+// RespondSequence 依次返回 resps 中的响应, 用完后重复最后一个, 适合测试重试
+func (s *Server) RespondSequence(method, path string, resps ...Response) {
+	if len(resps) == 0 {
+		return
+	}
+	var mu sync.Mutex
+	next := 0
+	handlers := make([]http.Handler, len(resps))
+	for i, resp := range resps {
+		handlers[i] = respondHandler(resp)
+	}
+	s.Handle(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		h := handlers[min(next, len(handlers)-1)]
+		next++
+		mu.Unlock()
+		h.ServeHTTP(w, r)
+	}))
+}
+
+// Requests 返回目前收到的所有请求
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// LastRequest 返回最近收到的请求, 没有请求时测试失败
+func (s *Server) LastRequest(t testing.TB) RecordedRequest {
+	t.Helper()
+	requests := s.Requests()
+	if len(requests) == 0 {
+		t.Fatal("httpctest: no requests received")
+	}
+	return requests[len(requests)-1]
+}
+
+// AssertRequestCount 断言收到的请求数量
+func (s *Server) AssertRequestCount(t testing.TB, want int) {
+	t.Helper()
+	if got := len(s.Requests()); got != want {
+		t.Fatalf("httpctest: received %d requests, want %d", got, want)
+	}
+}
+
+// AssertHeader 断言请求头的值
+func (r RecordedRequest) AssertHeader(t testing.TB, key, want string) {
+	t.Helper()
+	if got := r.Header.Get(key); got != want {
+		t.Fatalf("httpctest: request header %s = %q, want %q", key, got, want)
+	}
+}
+
+// AssertBody 断言请求体内容
+func (r RecordedRequest) AssertBody(t testing.TB, want string) {
+	t.Helper()
+	if got := string(r.Body); got != want {
+		t.Fatalf("httpctest: request body = %q, want %q", got, want)
+	}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: r.Method,
+		URL:    r.URL,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	handler, ok := s.routes[routeKey(r.Method, r.URL.Path)]
+	if !ok {
+		handler, ok = s.routes[routeKey("", r.URL.Path)]
+	}
+	latency := s.latency
+	s.mu.Unlock()
+
+	if !sleep(r, latency) {
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// respondHandler 返回写出固定响应的处理器
+func respondHandler(resp Response) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !sleep(r, resp.Latency) {
+			return
+		}
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		status := resp.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write(resp.Body)
+	})
+}
+
+func routeKey(method, path string) string {
+	if method == "" {
+		return path
+	}
+	return method + " " + path
+}
+
+// sleep 等待 d, 请求被取消时返回 false
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	select {
+	case <-r.Context().Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -0,0 +1,91 @@
+package httpc
+
+import (
+	stdjson "encoding/json"
+	"io"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+	"github.com/go-json-experiment/json"
+)
+
+// JSONEngine 是 SetJSONBody, DecodeJSON 与 DecodeAuto 使用的 JSON 编解码实现.
+// 默认使用 go-json-experiment; sonic 的 sonic.API 可直接作为 JSONEngine 使用,
+// go-json 等只提供包级函数的实现可通过 JSONFuncs 适配
+type JSONEngine interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// jsonStreamEngine 是支持流式编解码的 JSONEngine, 实现后 SetJSONBody 通过管道边编码边发送
+type jsonStreamEngine interface {
+	JSONEngine
+	MarshalWrite(w io.Writer, v any) error
+	UnmarshalRead(r io.Reader, v any) error
+}
+
+// JSONFuncs 是一个适配器, 允许使用一对函数作为 JSONEngine,
+// 例如 httpc.JSONFuncs{MarshalFunc: gojson.Marshal, UnmarshalFunc: gojson.Unmarshal}
+type JSONFuncs struct {
+	MarshalFunc   func(v any) ([]byte, error)
+	UnmarshalFunc func(data []byte, v any) error
+}
+
+// Marshal 实现了 JSONEngine 接口
+func (f JSONFuncs) Marshal(v any) ([]byte, error) {
+	return f.MarshalFunc(v)
+}
+
+// Unmarshal 实现了 JSONEngine 接口
+func (f JSONFuncs) Unmarshal(data []byte, v any) error {
+	return f.UnmarshalFunc(data, v)
+}
+
+// StdJSONEngine 返回基于标准库 encoding/json 的 JSONEngine
+func StdJSONEngine() JSONEngine {
+	return JSONFuncs{MarshalFunc: stdjson.Marshal, UnmarshalFunc: stdjson.Unmarshal}
+}
+
+// defaultJSON 是不属于某个客户端的编解码 (例如 JSON Schema, NewJSONAuditSink) 使用的默认实现
+var defaultJSON JSONEngine = &experimentJSON{}
+
+// WithJSONEngine 替换客户端的 JSON 编解码实现, nil 恢复默认的 go-json-experiment 实现.
+// 使用自定义实现时 WithJSONOptions 不再生效
+func WithJSONEngine(engine JSONEngine) Option {
+	return func(c *Client) {
+		c.jsonEngine = engine
+	}
+}
+
+// experimentJSON 是默认的 JSONEngine, 应用 WithJSONOptions 设置的选项
+type experimentJSON struct {
+	marshalOpts   []json.Options
+	unmarshalOpts []json.Options
+}
+
+func (e *experimentJSON) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v, e.marshalOpts...)
+}
+
+func (e *experimentJSON) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v, e.unmarshalOpts...)
+}
+
+func (e *experimentJSON) MarshalWrite(w io.Writer, v any) error {
+	return json.MarshalWrite(w, v, e.marshalOpts...)
+}
+
+func (e *experimentJSON) UnmarshalRead(r io.Reader, v any) error {
+	return json.UnmarshalRead(r, v, e.unmarshalOpts...)
+}
+
+// unmarshalJSONReader 使用客户端的 JSONEngine 从 r 解码, 不支持流式解码的实现先读取全部内容
+func (c *Client) unmarshalJSONReader(r io.Reader, v any) error {
+	if engine, ok := c.jsonEngine.(jsonStreamEngine); ok {
+		return engine.UnmarshalRead(r, v)
+	}
+	data, err := iox.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.jsonEngine.Unmarshal(data, v)
+}
@@ -0,0 +1,83 @@
+package httpc
+
+import (
+	stdjson "encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// countingJSON 统计调用次数的 JSONEngine
+type countingJSON struct {
+	marshals   atomic.Int32
+	unmarshals atomic.Int32
+}
+
+func (e *countingJSON) Marshal(v any) ([]byte, error) {
+	e.marshals.Add(1)
+	return stdjson.Marshal(v)
+}
+
+func (e *countingJSON) Unmarshal(data []byte, v any) error {
+	e.unmarshals.Add(1)
+	return stdjson.Unmarshal(data, v)
+}
+
+func TestWithJSONEngine(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	type payload struct {
+		Name string `json:"name"`
+	}
+	engine := &countingJSON{}
+	client := New(WithJSONEngine(engine))
+
+	builder, err := client.POST(server.URL).SetJSONBody(payload{Name: "httpc"})
+	if err != nil {
+		t.Fatalf("SetJSONBody() error = %v", err)
+	}
+	var got payload
+	if err := builder.DecodeJSON(&got); err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+	if got.Name != "httpc" {
+		t.Fatalf("Name = %q, want %q", got.Name, "httpc")
+	}
+
+	got = payload{}
+	builder, _ = client.POST(server.URL).SetJSONBody(payload{Name: "auto"})
+	if err := builder.DecodeAuto(&got); err != nil {
+		t.Fatalf("DecodeAuto() error = %v", err)
+	}
+	if got.Name != "auto" {
+		t.Fatalf("Name = %q, want %q", got.Name, "auto")
+	}
+	if engine.marshals.Load() != 2 || engine.unmarshals.Load() != 2 {
+		t.Fatalf("marshals/unmarshals = %d/%d, want 2/2", engine.marshals.Load(), engine.unmarshals.Load())
+	}
+}
+
+func TestStdJSONEngineEncodeError(t *testing.T) {
+	client := New(WithJSONEngine(StdJSONEngine()))
+
+	// 不支持流式编码的实现会立即编码, 因此错误在 SetJSONBody 时返回
+	_, err := client.POST("http://example.com").SetJSONBody(make(chan int))
+	if err == nil || !strings.Contains(err.Error(), "encode json body error") {
+		t.Fatalf("SetJSONBody() error = %v, want encode error", err)
+	}
+}
+
+func TestWithJSONEngineNilRestoresDefault(t *testing.T) {
+	client := New(WithJSONEngine(StdJSONEngine()), WithJSONEngine(nil))
+	if _, ok := client.jsonEngine.(*experimentJSON); !ok {
+		t.Fatalf("jsonEngine = %T, want *experimentJSON", client.jsonEngine)
+	}
+}
@@ -0,0 +1,687 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrSchemaValidation 表示响应体不符合 JSON Schema
+var ErrSchemaValidation = errors.New("httpc: response does not match schema")
+
+// SchemaViolation 描述一处不符合 Schema 的位置
+type SchemaViolation struct {
+	Path    string // 实例中的位置 (JSON Pointer, 根为 "")
+	Keyword string // 未通过的 Schema 关键字, 例如 "type", "required"
+	Message string
+}
+
+// SchemaError 列出响应体不符合 JSON Schema 的所有位置, errors.Is(err, ErrSchemaValidation) 为 true
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrSchemaValidation.Error())
+	for i, v := range e.Violations {
+		if i == 0 {
+			sb.WriteString(": ")
+		} else {
+			sb.WriteString("; ")
+		}
+		path := v.Path
+		if path == "" {
+			path = "/"
+		}
+		sb.WriteString(path)
+		sb.WriteString(": ")
+		sb.WriteString(v.Message)
+	}
+	return sb.String()
+}
+
+func (e *SchemaError) Unwrap() error { return ErrSchemaValidation }
+
+// JSONSchema 是编译后的 JSON Schema, 可并发使用.
+// 支持 draft 2020-12 的常用校验关键字: type, enum, const, properties, required, additionalProperties,
+// patternProperties, items, prefixItems, 数值/长度/数量范围, pattern, uniqueItems, allOf/anyOf/oneOf/not,
+// 以及指向本文档内部的 $ref ("#/$defs/..."); format 等注解关键字会被忽略
+type JSONSchema struct {
+	root *schemaNode
+}
+
+// CompileJSONSchema 解析并编译 JSON Schema, Schema 本身无效时返回错误
+func CompileJSONSchema(schema []byte) (*JSONSchema, error) {
+	var doc any
+	if err := defaultJSON.Unmarshal(schema, &doc); err != nil {
+		return nil, fmt.Errorf("httpc: invalid JSON schema: %w", err)
+	}
+	c := &schemaCompiler{doc: doc, nodes: make(map[string]*schemaNode)}
+	root, err := c.compile(doc, "")
+	if err == nil {
+		err = c.checkCycles()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("httpc: invalid JSON schema: %w", err)
+	}
+	return &JSONSchema{root: root}, nil
+}
+
+// Validate 校验原始 JSON 数据, 不符合时返回 *SchemaError
+func (s *JSONSchema) Validate(data []byte) error {
+	var v any
+	if err := defaultJSON.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
+	return s.validateInstance(v)
+}
+
+// ValidateValue 校验已解码的 Go 值 (按其 JSON 编码结果校验), 不符合时返回 *SchemaError
+func (s *JSONSchema) ValidateValue(v any) error {
+	data, err := defaultJSON.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("httpc: encode value for schema validation: %w", err)
+	}
+	return s.Validate(data)
+}
+
+func (s *JSONSchema) validateInstance(v any) error {
+	var violations []SchemaViolation
+	s.root.validate(v, "", &violations)
+	if len(violations) > 0 {
+		return &SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+// ValidateResponse 在返回响应前按 JSON Schema 校验响应体 (状态码 < 400 时), 适用于契约测试与防御性地消费第三方 API.
+// 设置后响应体会被完整读入内存, DecodeJSON, Bytes 等方法读取的是校验过的数据; 不符合时返回 *SchemaError.
+// 空响应体 (如 204 或空的 200) 不做校验
+func (rb *RequestBuilder) ValidateResponse(schema *JSONSchema) *RequestBuilder {
+	rb.schema = schema
+	return rb
+}
+
+// validateResponse 读取并校验响应体, 校验通过时用读取的数据替换响应体
+func (c *Client) validateResponse(schema *JSONSchema, resp *http.Response) error {
+	if resp.StatusCode >= 400 || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	body, err := c.readBody(resp)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return nil
+	}
+
+	var v any
+	if err := c.jsonEngine.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
+	return schema.validateInstance(v)
+}
+
+// schemaNode 是编译后的 (子) Schema
+type schemaNode struct {
+	always *bool // true/false Schema
+
+	refTo *schemaNode // $ref 指向的节点
+
+	types    []string
+	enum     []any
+	constVal any
+	hasConst bool
+
+	properties        map[string]*schemaNode
+	patternProperties []patternSchema
+	additional        *schemaNode
+	required          []string
+	minProperties     int
+	maxProperties     int // -1 表示不限制
+
+	prefixItems []*schemaNode
+	items       *schemaNode
+	minItems    int
+	maxItems    int // -1 表示不限制
+	uniqueItems bool
+
+	minLength int
+	maxLength int // -1 表示不限制
+	pattern   *regexp.Regexp
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	allOf, anyOf, oneOf []*schemaNode
+	not                 *schemaNode
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *schemaNode
+}
+
+// schemaCompiler 按 JSON Pointer 缓存已编译的节点, 使递归的 $ref 可以终止
+type schemaCompiler struct {
+	doc   any
+	nodes map[string]*schemaNode
+}
+
+func (c *schemaCompiler) compile(raw any, ptr string) (*schemaNode, error) {
+	if n, ok := c.nodes[ptr]; ok {
+		return n, nil
+	}
+	n := &schemaNode{maxProperties: -1, maxItems: -1, maxLength: -1}
+	c.nodes[ptr] = n
+
+	if b, ok := raw.(bool); ok {
+		n.always = &b
+		return n, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("schema at %q must be an object or boolean", ptr)
+	}
+
+	var err error
+	sub := func(key string) (*schemaNode, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		return c.compile(v, ptr+"/"+escapePointer(key))
+	}
+	subList := func(key string) ([]*schemaNode, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%s at %q must be an array", key, ptr)
+		}
+		nodes := make([]*schemaNode, len(list))
+		for i, item := range list {
+			if nodes[i], err = c.compile(item, ptr+"/"+key+"/"+strconv.Itoa(i)); err != nil {
+				return nil, err
+			}
+		}
+		return nodes, nil
+	}
+	number := func(key string) (*float64, error) {
+		v, ok := m[key]
+		if !ok {
+			return nil, nil
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s at %q must be a number", key, ptr)
+		}
+		return &f, nil
+	}
+	count := func(key string, def int) (int, error) {
+		f, err := number(key)
+		if err != nil || f == nil {
+			return def, err
+		}
+		if *f < 0 || *f != math.Trunc(*f) {
+			return 0, fmt.Errorf("%s at %q must be a non-negative integer", key, ptr)
+		}
+		return int(*f), nil
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		if ref != "#" && !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("unsupported $ref %q at %q: only local references are supported", ref, ptr)
+		}
+		target, err := resolvePointer(c.doc, ref[1:])
+		if err != nil {
+			return nil, fmt.Errorf("$ref %q at %q: %w", ref, ptr, err)
+		}
+		if n.refTo, err = c.compile(target, ref[1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		n.types = []string{t}
+	case []any:
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("type at %q must be a string or array of strings", ptr)
+			}
+			n.types = append(n.types, s)
+		}
+	default:
+		return nil, fmt.Errorf("type at %q must be a string or array of strings", ptr)
+	}
+	for _, t := range n.types {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return nil, fmt.Errorf("unknown type %q at %q", t, ptr)
+		}
+	}
+
+	if v, ok := m["enum"]; ok {
+		if n.enum, ok = v.([]any); !ok {
+			return nil, fmt.Errorf("enum at %q must be an array", ptr)
+		}
+	}
+	n.constVal, n.hasConst = m["const"]
+
+	if v, ok := m["properties"]; ok {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("properties at %q must be an object", ptr)
+		}
+		n.properties = make(map[string]*schemaNode, len(props))
+		for name, raw := range props {
+			if n.properties[name], err = c.compile(raw, ptr+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if v, ok := m["patternProperties"]; ok {
+		props, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("patternProperties at %q must be an object", ptr)
+		}
+		for pattern, raw := range props {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("patternProperties at %q: %w", ptr, err)
+			}
+			node, err := c.compile(raw, ptr+"/patternProperties/"+escapePointer(pattern))
+			if err != nil {
+				return nil, err
+			}
+			n.patternProperties = append(n.patternProperties, patternSchema{re: re, schema: node})
+		}
+	}
+	if n.additional, err = sub("additionalProperties"); err != nil {
+		return nil, err
+	}
+	if v, ok := m["required"]; ok {
+		list, ok := v.([]any)
+		if !ok {
+			return nil, fmt.Errorf("required at %q must be an array", ptr)
+		}
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("required at %q must contain strings", ptr)
+			}
+			n.required = append(n.required, s)
+		}
+	}
+	if n.minProperties, err = count("minProperties", 0); err != nil {
+		return nil, err
+	}
+	if n.maxProperties, err = count("maxProperties", -1); err != nil {
+		return nil, err
+	}
+
+	if n.prefixItems, err = subList("prefixItems"); err != nil {
+		return nil, err
+	}
+	if n.items, err = sub("items"); err != nil {
+		return nil, err
+	}
+	if n.minItems, err = count("minItems", 0); err != nil {
+		return nil, err
+	}
+	if n.maxItems, err = count("maxItems", -1); err != nil {
+		return nil, err
+	}
+	n.uniqueItems, _ = m["uniqueItems"].(bool)
+
+	if n.minLength, err = count("minLength", 0); err != nil {
+		return nil, err
+	}
+	if n.maxLength, err = count("maxLength", -1); err != nil {
+		return nil, err
+	}
+	if v, ok := m["pattern"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("pattern at %q must be a string", ptr)
+		}
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return nil, fmt.Errorf("pattern at %q: %w", ptr, err)
+		}
+	}
+
+	for key, dst := range map[string]**float64{
+		"minimum":          &n.minimum,
+		"maximum":          &n.maximum,
+		"exclusiveMinimum": &n.exclusiveMinimum,
+		"exclusiveMaximum": &n.exclusiveMaximum,
+		"multipleOf":       &n.multipleOf,
+	} {
+		if *dst, err = number(key); err != nil {
+			return nil, err
+		}
+	}
+	if n.multipleOf != nil && *n.multipleOf <= 0 {
+		return nil, fmt.Errorf("multipleOf at %q must be greater than 0", ptr)
+	}
+
+	if n.allOf, err = subList("allOf"); err != nil {
+		return nil, err
+	}
+	if n.anyOf, err = subList("anyOf"); err != nil {
+		return nil, err
+	}
+	if n.oneOf, err = subList("oneOf"); err != nil {
+		return nil, err
+	}
+	if n.not, err = sub("not"); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// checkCycles 检查不经过属性或数组元素的循环 ($ref, allOf, anyOf, oneOf, not),
+// 这样的循环在校验同一个值时无法终止
+func (c *schemaCompiler) checkCycles() error {
+	ptrs := make(map[*schemaNode]string, len(c.nodes))
+	for ptr, n := range c.nodes {
+		ptrs[n] = ptr
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[*schemaNode]int, len(c.nodes))
+	var visit func(n *schemaNode) error
+	visit = func(n *schemaNode) error {
+		switch state[n] {
+		case visiting:
+			return fmt.Errorf("cyclic $ref at %q never descends into the instance", ptrs[n])
+		case done:
+			return nil
+		}
+		state[n] = visiting
+		next := slices.Concat(n.allOf, n.anyOf, n.oneOf)
+		if n.refTo != nil {
+			next = append(next, n.refTo)
+		}
+		if n.not != nil {
+			next = append(next, n.not)
+		}
+		for _, m := range next {
+			if err := visit(m); err != nil {
+				return err
+			}
+		}
+		state[n] = done
+		return nil
+	}
+	for _, n := range c.nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验实例, 将不符合的位置追加到 violations
+func (n *schemaNode) validate(v any, path string, violations *[]SchemaViolation) {
+	report := func(keyword, format string, args ...any) {
+		*violations = append(*violations, SchemaViolation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.always != nil {
+		if !*n.always {
+			report("false", "no value is allowed here")
+		}
+		return
+	}
+	if n.refTo != nil {
+		n.refTo.validate(v, path, violations)
+	}
+
+	if len(n.types) > 0 && !slices.ContainsFunc(n.types, func(t string) bool { return jsonTypeMatches(t, v) }) {
+		report("type", "expected %s, got %s", strings.Join(n.types, " or "), jsonTypeOf(v))
+		return
+	}
+	if n.enum != nil && !slices.ContainsFunc(n.enum, func(e any) bool { return reflect.DeepEqual(e, v) }) {
+		report("enum", "value %s is not one of the allowed values", jsonPreview(v))
+	}
+	if n.hasConst && !reflect.DeepEqual(n.constVal, v) {
+		report("const", "value %s does not equal %s", jsonPreview(v), jsonPreview(n.constVal))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		n.validateObject(val, path, violations, report)
+	case []any:
+		n.validateArray(val, path, violations, report)
+	case string:
+		length := utf8.RuneCountInString(val)
+		if length < n.minLength {
+			report("minLength", "length %d is less than %d", length, n.minLength)
+		}
+		if n.maxLength >= 0 && length > n.maxLength {
+			report("maxLength", "length %d is greater than %d", length, n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(val) {
+			report("pattern", "%q does not match pattern %q", val, n.pattern.String())
+		}
+	case float64:
+		if n.minimum != nil && val < *n.minimum {
+			report("minimum", "%v is less than %v", val, *n.minimum)
+		}
+		if n.maximum != nil && val > *n.maximum {
+			report("maximum", "%v is greater than %v", val, *n.maximum)
+		}
+		if n.exclusiveMinimum != nil && val <= *n.exclusiveMinimum {
+			report("exclusiveMinimum", "%v is not greater than %v", val, *n.exclusiveMinimum)
+		}
+		if n.exclusiveMaximum != nil && val >= *n.exclusiveMaximum {
+			report("exclusiveMaximum", "%v is not less than %v", val, *n.exclusiveMaximum)
+		}
+		if n.multipleOf != nil {
+			if q := val / *n.multipleOf; math.Abs(q-math.Round(q)) > 1e-9 {
+				report("multipleOf", "%v is not a multiple of %v", val, *n.multipleOf)
+			}
+		}
+	}
+
+	for _, s := range n.allOf {
+		s.validate(v, path, violations)
+	}
+	if len(n.anyOf) > 0 && countMatches(n.anyOf, v) == 0 {
+		report("anyOf", "value does not match any of the schemas")
+	}
+	if len(n.oneOf) > 0 {
+		if matched := countMatches(n.oneOf, v); matched != 1 {
+			report("oneOf", "value matches %d schemas, want exactly 1", matched)
+		}
+	}
+	if n.not != nil && countMatches([]*schemaNode{n.not}, v) == 1 {
+		report("not", "value must not match the schema")
+	}
+}
+
+func (n *schemaNode) validateObject(obj map[string]any, path string, violations *[]SchemaViolation, report func(string, string, ...any)) {
+	for _, name := range n.required {
+		if _, ok := obj[name]; !ok {
+			report("required", "missing required property %q", name)
+		}
+	}
+	if len(obj) < n.minProperties {
+		report("minProperties", "has %d properties, want at least %d", len(obj), n.minProperties)
+	}
+	if n.maxProperties >= 0 && len(obj) > n.maxProperties {
+		report("maxProperties", "has %d properties, want at most %d", len(obj), n.maxProperties)
+	}
+
+	// 按属性名排序, 使错误列表的顺序稳定
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := obj[name]
+		childPath := path + "/" + escapePointer(name)
+		matched := false
+		if s, ok := n.properties[name]; ok {
+			matched = true
+			s.validate(value, childPath, violations)
+		}
+		for _, ps := range n.patternProperties {
+			if ps.re.MatchString(name) {
+				matched = true
+				ps.schema.validate(value, childPath, violations)
+			}
+		}
+		if !matched && n.additional != nil {
+			if n.additional.always != nil && !*n.additional.always {
+				*violations = append(*violations, SchemaViolation{Path: childPath, Keyword: "additionalProperties", Message: "additional property is not allowed"})
+				continue
+			}
+			n.additional.validate(value, childPath, violations)
+		}
+	}
+}
+
+func (n *schemaNode) validateArray(arr []any, path string, violations *[]SchemaViolation, report func(string, string, ...any)) {
+	if len(arr) < n.minItems {
+		report("minItems", "has %d items, want at least %d", len(arr), n.minItems)
+	}
+	if n.maxItems >= 0 && len(arr) > n.maxItems {
+		report("maxItems", "has %d items, want at most %d", len(arr), n.maxItems)
+	}
+	if n.uniqueItems {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					report("uniqueItems", "items %d and %d are equal", i, j)
+					break outer
+				}
+			}
+		}
+	}
+	for i, item := range arr {
+		childPath := path + "/" + strconv.Itoa(i)
+		switch {
+		case i < len(n.prefixItems):
+			n.prefixItems[i].validate(item, childPath, violations)
+		case n.items != nil:
+			n.items.validate(item, childPath, violations)
+		}
+	}
+}
+
+// countMatches 返回实例通过校验的子 Schema 数量
+func countMatches(schemas []*schemaNode, v any) int {
+	matched := 0
+	for _, s := range schemas {
+		var violations []SchemaViolation
+		s.validate(v, "", &violations)
+		if len(violations) == 0 {
+			matched++
+		}
+	}
+	return matched
+}
+
+func jsonTypeMatches(t string, v any) bool {
+	switch t {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	default:
+		return jsonTypeOf(v) == t
+	}
+}
+
+func jsonTypeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// jsonPreview 返回用于错误信息的值预览
+func jsonPreview(v any) string {
+	data, err := defaultJSON.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	const maxPreviewLen = 64
+	if len(data) > maxPreviewLen {
+		return string(data[:maxPreviewLen]) + "..."
+	}
+	return string(data)
+}
+
+// resolvePointer 在 JSON 文档中解析 JSON Pointer (RFC 6901)
+func resolvePointer(doc any, ptr string) (any, error) {
+	if ptr == "" {
+		return doc, nil
+	}
+	cur := doc
+	for _, token := range strings.Split(ptr[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("pointer %q not found", ptr)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("pointer %q not found", ptr)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("pointer %q not found", ptr)
+		}
+	}
+	return cur, nil
+}
+
+// escapePointer 转义 JSON Pointer 中的特殊字符
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
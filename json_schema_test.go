@@ -0,0 +1,152 @@
+package httpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const userSchema = `{
+	"type": "object",
+	"required": ["id", "name"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"name": {"type": "string", "minLength": 1},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+		"manager": {"$ref": "#/$defs/ref"}
+	},
+	"$defs": {
+		"ref": {"anyOf": [{"type": "null"}, {"$ref": "#"}]}
+	}
+}`
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := CompileJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatalf("CompileJSONSchema() error = %v", err)
+	}
+
+	valid := `{"id": 1, "name": "a", "email": "a@b.c", "role": "admin", "tags": ["x"], "manager": {"id": 2, "name": "b", "manager": null}}`
+	if err := schema.Validate([]byte(valid)); err != nil {
+		t.Fatalf("Validate(valid) error = %v", err)
+	}
+
+	tests := []struct {
+		body    string
+		path    string
+		keyword string
+	}{
+		{`[]`, "", "type"},
+		{`{"name": "a"}`, "", "required"},
+		{`{"id": 1.5, "name": "a"}`, "/id", "type"},
+		{`{"id": 0, "name": "a"}`, "/id", "minimum"},
+		{`{"id": 1, "name": ""}`, "/name", "minLength"},
+		{`{"id": 1, "name": "a", "email": "nope"}`, "/email", "pattern"},
+		{`{"id": 1, "name": "a", "role": "root"}`, "/role", "enum"},
+		{`{"id": 1, "name": "a", "tags": ["x", "x"]}`, "/tags", "uniqueItems"},
+		{`{"id": 1, "name": "a", "tags": [1]}`, "/tags/0", "type"},
+		{`{"id": 1, "name": "a", "extra": true}`, "/extra", "additionalProperties"},
+		{`{"id": 1, "name": "a", "manager": {"id": 2}}`, "/manager", "anyOf"},
+	}
+	for _, tt := range tests {
+		err := schema.Validate([]byte(tt.body))
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) || !errors.Is(err, ErrSchemaValidation) {
+			t.Fatalf("Validate(%s) error = %v, want SchemaError", tt.body, err)
+		}
+		if len(schemaErr.Violations) != 1 {
+			t.Fatalf("Validate(%s) violations = %+v, want 1", tt.body, schemaErr.Violations)
+		}
+		if v := schemaErr.Violations[0]; v.Path != tt.path || v.Keyword != tt.keyword {
+			t.Fatalf("Validate(%s) violation = %+v, want %s at %q", tt.body, v, tt.keyword, tt.path)
+		}
+	}
+}
+
+func TestJSONSchemaListsAllViolations(t *testing.T) {
+	schema, err := CompileJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatalf("CompileJSONSchema() error = %v", err)
+	}
+	err = schema.ValidateValue(map[string]any{"id": -1, "email": "x"})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("ValidateValue() error = %v, want SchemaError", err)
+	}
+	if got := len(schemaErr.Violations); got != 3 {
+		t.Fatalf("violations = %+v, want 3 (required, pattern, minimum)", schemaErr.Violations)
+	}
+}
+
+func TestCompileJSONSchemaInvalid(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": "bogus"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/$defs/missing"}`,
+		`{"$ref": "https://example.com/schema.json"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"allOf": [{"$ref": "#/$defs/a"}]}}, "$ref": "#/$defs/a"}`,
+		`{"anyOf": [{"$ref": "#"}]}`,
+		`"not a schema"`,
+	} {
+		if _, err := CompileJSONSchema([]byte(schema)); err == nil {
+			t.Fatalf("CompileJSONSchema(%s) error = nil, want error", schema)
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	body := `{"id": 1, "name": "a"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	schema, err := CompileJSONSchema([]byte(userSchema))
+	if err != nil {
+		t.Fatalf("CompileJSONSchema() error = %v", err)
+	}
+	client := New()
+
+	var user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := client.GET(srv.URL).ValidateResponse(schema).DecodeJSON(&user); err != nil {
+		t.Fatalf("DecodeJSON() error = %v", err)
+	}
+	if user.ID != 1 || user.Name != "a" {
+		t.Fatalf("user = %+v, want {1 a}", user)
+	}
+
+	body = `{"id": "1"}`
+	_, err = client.GET(srv.URL).ValidateResponse(schema).Bytes()
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Violations) != 2 {
+		t.Fatalf("Bytes() error = %v, want 2 violations", err)
+	}
+
+	body = ""
+	if _, err := client.GET(srv.URL).ValidateResponse(schema).Bytes(); err != nil {
+		t.Fatalf("Bytes() on empty body error = %v, want nil", err)
+	}
+}
+
+func TestCompileJSONSchemaRecursiveThroughProperties(t *testing.T) {
+	schema, err := CompileJSONSchema([]byte(`{"type": "object", "properties": {"next": {"$ref": "#"}}}`))
+	if err != nil {
+		t.Fatalf("CompileJSONSchema() error = %v", err)
+	}
+	if err := schema.Validate([]byte(`{"next": {"next": {}}}`)); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := schema.Validate([]byte(`{"next": {"next": 1}}`)); err == nil {
+		t.Fatal("Validate() error = nil, want violation")
+	}
+}
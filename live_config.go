@@ -0,0 +1,185 @@
+package httpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// liveConfig 是可在运行时原子替换的配置快照
+// 快照一经发布即不可修改, 修改时复制一份再整体替换 (copy-on-write)
+type liveConfig struct {
+	retryOpts   RetryOptions
+	rateLimiter *rateLimiter
+	timeout     time.Duration
+
+	proxy     func(*http.Request) (*url.URL, error)                             // HTTP/HTTPS 代理
+	proxyDial func(ctx context.Context, network, addr string) (net.Conn, error) // SOCKS5 代理拨号, nil 表示直连
+	proxyURL  string                                                            // 代理地址, 为空表示环境变量代理, 用于判断代理是否变化
+
+	chain http.RoundTripper // 按本快照预先构建的调用链, 由 updateLive 维护
+}
+
+// settings 返回当前的配置快照
+func (c *Client) settings() *liveConfig {
+	return c.live.Load()
+}
+
+// updateLive 复制当前快照, 应用 fn 后原子替换
+func (c *Client) updateLive(fn func(l *liveConfig)) {
+	c.liveMu.Lock()
+	defer c.liveMu.Unlock()
+	next := *c.live.Load()
+	fn(&next)
+	next.chain = c.buildChain(&next)
+	c.live.Store(&next)
+}
+
+// rebuildChain 在限速, 重试等快照之外的设置 (例如日志函数) 变化后重新构建调用链
+func (c *Client) rebuildChain() {
+	c.updateLive(func(*liveConfig) {})
+}
+
+// ApplyConfig 在运行中的客户端上原子替换重试, 限速, 超时与代理配置, 不会重建连接池; 代理变化时关闭空闲连接
+// 这些字段按 cfg 的完整内容生效: Retry 为 nil 时恢复默认重试策略, RateLimit 为 nil 时取消限速,
+// Timeout 为 0 时取消超时, Proxy 为空时使用环境变量代理. 其余字段 (例如 DNS, TLS) 需要重建客户端
+func (c *Client) ApplyConfig(cfg Config) error {
+	retryOpts := defaultRetryOptions()
+	if cfg.Retry != nil {
+		var err error
+		if retryOpts, err = cfg.Retry.retryOptions(); err != nil {
+			return err
+		}
+	}
+
+	proxy := http.ProxyFromEnvironment
+	var proxyDial func(ctx context.Context, network, addr string) (net.Conn, error)
+	if cfg.Proxy != "" {
+		u, err := parseProxyURL(cfg.Proxy)
+		if err != nil {
+			return err
+		}
+		if isSocks5Scheme(u.Scheme) {
+			if proxyDial, err = c.socks5DialFunc(u); err != nil {
+				return err
+			}
+			proxy = nil
+		} else {
+			proxy = http.ProxyURL(u)
+		}
+	}
+
+	var limit RateLimit
+	if cfg.RateLimit != nil {
+		limit = *cfg.RateLimit
+	}
+
+	var proxyChanged bool
+	c.updateLive(func(l *liveConfig) {
+		l.retryOpts = retryOpts
+		l.timeout = time.Duration(cfg.Timeout)
+		proxyChanged = l.proxyURL != cfg.Proxy
+		l.proxy = proxy
+		l.proxyDial = proxyDial
+		l.proxyURL = cfg.Proxy
+		// 限速参数未变化时保留现有令牌桶, 避免重载配置时突发
+		if l.rateLimiter == nil || l.rateLimiter.limit != limit {
+			l.rateLimiter = newRateLimiter(limit)
+		}
+	})
+	if proxyChanged {
+		// 空闲连接经由旧代理建立, 关闭后新请求按新代理重新连接; 进行中的请求不受影响
+		c.closeIdleConnections()
+	}
+	return nil
+}
+
+// closeIdleConnections 关闭 Transport 及全部分片 Transport 的空闲连接
+func (c *Client) closeIdleConnections() {
+	c.transport.CloseIdleConnections()
+	if c.overrides != nil {
+		c.overrides.CloseIdleConnections()
+	}
+	if c.shards != nil {
+		c.shards.CloseIdleConnections()
+	}
+}
+
+// installLiveHooks 让 Transport 的代理与拨号在每次建立连接时读取最新的配置快照
+// 在所有 Option 应用完成后调用一次, 之后不再修改 Transport 字段
+func (c *Client) installLiveHooks() {
+	proxy := c.transport.Proxy
+	c.updateLive(func(l *liveConfig) {
+		l.proxy = proxy
+	})
+	c.transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		if p := c.settings().proxy; p != nil {
+			return p(req)
+		}
+		return nil, nil
+	}
+
+	// WithTransport 传入的 DialContext 作为基础拨号, WithDialContext 优先
+	if c.dialContext == nil && c.transport.DialContext != nil {
+		c.dialContext = c.transport.DialContext
+	}
+	dial := withRequestDialTimeout(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if dial := c.settings().proxyDial; dial != nil {
+			return dial(ctx, network, addr)
+		}
+		return c.directDial(ctx, network, addr)
+	})
+	if pp := c.proxyProtocol; pp != nil {
+		next := dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := next(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if err := pp.writeHeader(ctx, conn); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return conn, nil
+		}
+	}
+	c.transport.DialContext = dial
+	if c.connHooks != nil {
+		c.transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return c.hookDial(ctx, network, addr, dial)
+		}
+	}
+}
+
+// timeoutRoundTripper 是一个内部中间件, 为请求 (含所有重试与响应体读取) 施加客户端超时
+func (c *Client) timeoutRoundTripper(timeout time.Duration, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		resp, err := next.RoundTrip(req.WithContext(ctx))
+		if resp != nil {
+			resp.Request = req
+		}
+		// 101 协议升级的响应体是可写的连接, 不做包装
+		if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			cancel()
+			return resp, err
+		}
+		resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	})
+}
+
+// cancelOnCloseBody 在响应体关闭时释放超时 Context
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,170 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithTimeoutIsEnforced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := New(WithTimeout(50*time.Millisecond), WithRetryOptions(RetryOptions{}))
+	_, err := client.GET(server.URL).Text()
+	if !errors.Is(err, ErrRequestTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Text() error = %v, want timeout", err)
+	}
+}
+
+func TestApplyConfigSwapsProxyWithoutRebuildingTransport(t *testing.T) {
+	newProxy := func(hits *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			_, _ = w.Write([]byte("via proxy"))
+		}))
+	}
+	var hitsA, hitsB atomic.Int32
+	proxyA, proxyB := newProxy(&hitsA), newProxy(&hitsB)
+	defer proxyA.Close()
+	defer proxyB.Close()
+
+	client := New(WithHTTPProxy(proxyA.URL))
+	transport := client.transport
+
+	if _, err := client.GET("http://upstream.invalid/").Text(); err != nil {
+		t.Fatalf("Text() via proxy A error = %v", err)
+	}
+	if err := client.ApplyConfig(Config{Proxy: proxyB.URL}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	if _, err := client.GET("http://upstream.invalid/").Text(); err != nil {
+		t.Fatalf("Text() via proxy B error = %v", err)
+	}
+
+	if hitsA.Load() != 1 || hitsB.Load() != 1 {
+		t.Fatalf("proxy hits = %d/%d, want 1/1", hitsA.Load(), hitsB.Load())
+	}
+	if client.transport != transport {
+		t.Fatal("ApplyConfig() replaced the transport")
+	}
+}
+
+func TestApplyConfigClosesIdleConnectionsOnProxyChange(t *testing.T) {
+	closed := make(chan struct{}, 4)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := New(WithHTTPProxy(server.URL))
+	if _, err := client.GET("http://upstream.invalid/").Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+
+	// 代理未变化时保留空闲连接
+	if err := client.ApplyConfig(Config{Proxy: server.URL}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	select {
+	case <-closed:
+		t.Fatal("idle connection closed although the proxy did not change")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := client.ApplyConfig(Config{}); err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("idle connection through the old proxy was not closed")
+	}
+}
+
+func TestApplyConfigSwapsRetryAndRateLimit(t *testing.T) {
+	client := New(WithRateLimit(RateLimit{RequestsPerSecond: 5, Burst: 1}))
+	limiter := client.settings().rateLimiter
+
+	err := client.ApplyConfig(Config{
+		Retry:     &RetryConfig{MaxAttempts: 7},
+		RateLimit: &RateLimit{RequestsPerSecond: 5, Burst: 1},
+		Timeout:   Duration(time.Second),
+	})
+	if err != nil {
+		t.Fatalf("ApplyConfig() error = %v", err)
+	}
+	settings := client.settings()
+	if settings.retryOpts.MaxAttempts != 7 {
+		t.Fatalf("MaxAttempts = %d, want 7", settings.retryOpts.MaxAttempts)
+	}
+	if settings.timeout != time.Second {
+		t.Fatalf("timeout = %v, want %v", settings.timeout, time.Second)
+	}
+	if settings.rateLimiter != limiter {
+		t.Fatal("unchanged rate limit replaced the existing limiter")
+	}
+
+	if err := client.ApplyConfig(Config{}); err != nil {
+		t.Fatalf("ApplyConfig(empty) error = %v", err)
+	}
+	if settings := client.settings(); settings.rateLimiter != nil || settings.timeout != 0 || settings.retryOpts.MaxAttempts != defaultRetryOptions().MaxAttempts {
+		t.Fatalf("settings = %+v, want defaults", settings)
+	}
+}
+
+func TestApplyConfigRejectsInvalidConfig(t *testing.T) {
+	client := New(WithTimeout(time.Second))
+	before := client.settings()
+
+	if err := client.ApplyConfig(Config{Proxy: "ftp://proxy"}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("ApplyConfig() error = %v, want ErrInvalidConfig", err)
+	}
+	if client.settings() != before {
+		t.Fatal("invalid config modified the live settings")
+	}
+}
+
+func TestApplyConfigConcurrentWithRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := New()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				if _, err := client.GET(server.URL).Text(); err != nil {
+					t.Errorf("Text() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for i := range 20 {
+		cfg := Config{Retry: &RetryConfig{MaxAttempts: i%3 + 1}, Timeout: Duration(time.Duration(i+1) * time.Second)}
+		if err := client.ApplyConfig(cfg); err != nil {
+			t.Fatalf("ApplyConfig() error = %v", err)
+		}
+	}
+	wg.Wait()
+}
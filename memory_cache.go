@@ -0,0 +1,138 @@
+package httpc
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCacheStore 的默认容量
+const (
+	defaultMemoryCacheEntries = 1024
+	defaultMemoryCacheBytes   = 32 << 20 // 32MB
+)
+
+// MemoryCacheOptions 内存缓存配置
+type MemoryCacheOptions struct {
+	MaxEntries int           // 最多保留的条目数, 0 使用默认值 (1024), 负数表示不限制
+	MaxBytes   int64         // 所有条目的值合计的最大字节数, 0 使用默认值 (32MB), 负数表示不限制
+	TTL        time.Duration // Set 的 ttl 为 0 时使用的默认过期时间, 0 表示不过期
+	Clock      Clock         // 判断过期的时间源, nil 使用系统时间
+}
+
+// MemoryCacheStore 是进程内的 CacheStore, 超过条目数或字节数上限时淘汰最久未使用的条目,
+// 适合缓存少量热点响应. 可并发使用
+type MemoryCacheStore struct {
+	mu        sync.Mutex
+	opts      MemoryCacheOptions
+	entries   map[string]*list.Element
+	lru       *list.List // 最近使用的在前
+	used      int64
+	evictions int64
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // 零值表示不过期
+}
+
+// NewMemoryCacheStore 创建内存缓存
+func NewMemoryCacheStore(opts MemoryCacheOptions) *MemoryCacheStore {
+	if opts.MaxEntries == 0 {
+		opts.MaxEntries = defaultMemoryCacheEntries
+	}
+	if opts.MaxBytes == 0 {
+		opts.MaxBytes = defaultMemoryCacheBytes
+	}
+	if opts.Clock == nil {
+		opts.Clock = realClock{}
+	}
+	return &MemoryCacheStore{
+		opts:    opts,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get 实现了 CacheStore 接口, 过期的条目视为不存在并被删除
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && !s.opts.Clock.Now().Before(entry.expires) {
+		s.remove(elem)
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set 实现了 CacheStore 接口, ttl 为 0 时使用 MemoryCacheOptions.TTL; 单个值超过 MaxBytes 时不保存
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if ttl == 0 {
+		ttl = s.opts.TTL
+	}
+	entry := &memoryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = s.opts.Clock.Now().Add(ttl)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	if s.opts.MaxBytes > 0 && int64(len(value)) > s.opts.MaxBytes {
+		return
+	}
+	s.entries[key] = s.lru.PushFront(entry)
+	s.used += int64(len(value))
+
+	for (s.opts.MaxEntries > 0 && s.lru.Len() > s.opts.MaxEntries) ||
+		(s.opts.MaxBytes > 0 && s.used > s.opts.MaxBytes) {
+		s.remove(s.lru.Back())
+		s.evictions++
+	}
+}
+
+// Delete 实现了 CacheStore 接口
+func (s *MemoryCacheStore) Delete(ctx context.Context, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+}
+
+// Len 返回当前的条目数 (可能包含尚未被访问清理的过期条目)
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// Bytes 返回当前所有条目的值合计的字节数
+func (s *MemoryCacheStore) Bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used
+}
+
+// Evictions 返回因容量上限被淘汰的条目数
+func (s *MemoryCacheStore) Evictions() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.evictions
+}
+
+func (s *MemoryCacheStore) remove(elem *list.Element) {
+	entry := s.lru.Remove(elem).(*memoryCacheEntry)
+	delete(s.entries, entry.key)
+	s.used -= int64(len(entry.value))
+}
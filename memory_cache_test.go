@@ -0,0 +1,83 @@
+package httpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheStoreLRU(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore(MemoryCacheOptions{MaxEntries: 2, MaxBytes: -1})
+
+	store.Set(ctx, "a", []byte("1"), 0)
+	store.Set(ctx, "b", []byte("2"), 0)
+	store.Get(ctx, "a") // a 变为最近使用
+	store.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok := store.Get(ctx, "b"); ok {
+		t.Fatal("b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := store.Get(ctx, key); !ok {
+			t.Fatalf("%s was evicted, want retained", key)
+		}
+	}
+	if store.Evictions() != 1 {
+		t.Fatalf("Evictions() = %d, want 1", store.Evictions())
+	}
+}
+
+func TestMemoryCacheStoreMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryCacheStore(MemoryCacheOptions{MaxEntries: -1, MaxBytes: 10})
+
+	store.Set(ctx, "a", make([]byte, 4), 0)
+	store.Set(ctx, "b", make([]byte, 4), 0)
+	store.Set(ctx, "c", make([]byte, 4), 0)
+	if store.Len() != 2 || store.Bytes() != 8 {
+		t.Fatalf("Len() = %d, Bytes() = %d, want 2, 8", store.Len(), store.Bytes())
+	}
+	if _, ok := store.Get(ctx, "a"); ok {
+		t.Fatal("a was not evicted")
+	}
+
+	// 单个值超过上限时不保存, 并替换掉旧值
+	store.Set(ctx, "b", make([]byte, 11), 0)
+	if _, ok := store.Get(ctx, "b"); ok {
+		t.Fatal("oversized value was stored")
+	}
+	if store.Bytes() != 4 {
+		t.Fatalf("Bytes() = %d, want 4", store.Bytes())
+	}
+}
+
+func TestMemoryCacheStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.Unix(0, 0))
+	store := NewMemoryCacheStore(MemoryCacheOptions{TTL: time.Minute, Clock: clock})
+
+	store.Set(ctx, "default", []byte("x"), 0)
+	store.Set(ctx, "short", []byte("y"), time.Second)
+
+	clock.Advance(time.Second)
+	if _, ok := store.Get(ctx, "short"); ok {
+		t.Fatal("short entry did not expire")
+	}
+	if _, ok := store.Get(ctx, "default"); !ok {
+		t.Fatal("default entry expired early")
+	}
+	clock.Advance(time.Minute)
+	if _, ok := store.Get(ctx, "default"); ok {
+		t.Fatal("default entry did not expire")
+	}
+	if store.Len() != 0 {
+		t.Fatalf("Len() = %d, want 0", store.Len())
+	}
+
+	store.Set(ctx, "gone", []byte("z"), 0)
+	store.Delete(ctx, "gone")
+	if _, ok := store.Get(ctx, "gone"); ok {
+		t.Fatal("deleted entry still present")
+	}
+}
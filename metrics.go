@@ -0,0 +1,242 @@
+package httpc
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Metrics 是客户端累计计数器的快照
+type Metrics struct {
+	Requests  int64 // 通过 Do 发出的请求数 (不含重试)
+	Retries   int64 // 重试次数
+	Errors    int64 // 最终返回错误的请求数
+	CacheHits int64 // 响应缓存命中次数
+}
+
+// metricCounters 是 Metrics 的并发安全版本
+type metricCounters struct {
+	requests  atomic.Int64
+	retries   atomic.Int64
+	errors    atomic.Int64
+	cacheHits atomic.Int64
+}
+
+func (m *metricCounters) snapshot() Metrics {
+	return Metrics{
+		Requests:  m.requests.Load(),
+		Retries:   m.retries.Load(),
+		Errors:    m.errors.Load(),
+		CacheHits: m.cacheHits.Load(),
+	}
+}
+
+// labelCounters 是单个请求标签的计数器
+type labelCounters struct {
+	metricCounters
+	lastUsed atomic.Int64 // 最近一次使用的序号, 用于淘汰
+}
+
+// maxMetricLabels 是按标签统计保留的标签数上限, 超过时淘汰最久未使用的标签
+const maxMetricLabels = 1024
+
+// clientMetrics 是客户端的计数器, 以及由 Label 请求选项产生的按标签计数器
+type clientMetrics struct {
+	metricCounters
+
+	mu      sync.RWMutex
+	labels  map[string]*labelCounters
+	tick    atomic.Int64
+	evicted Metrics // 已淘汰标签的累计计数, 在 LabelMetrics 中以空字符串为键
+}
+
+// labeled 返回请求标签对应的计数器, 请求未设置标签时返回 nil
+func (m *clientMetrics) labeled(ctx context.Context) *labelCounters {
+	o, ok := contextOptions(ctx)
+	if !ok || o.label == "" {
+		return nil
+	}
+	m.mu.RLock()
+	l, ok := m.labels[o.label]
+	m.mu.RUnlock()
+	if ok {
+		l.lastUsed.Store(m.tick.Add(1))
+		return l
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok = m.labels[o.label]; ok {
+		l.lastUsed.Store(m.tick.Add(1))
+		return l
+	}
+	if m.labels == nil {
+		m.labels = make(map[string]*labelCounters)
+	}
+	if len(m.labels) >= maxMetricLabels {
+		m.evictLocked()
+	}
+	l = &labelCounters{}
+	l.lastUsed.Store(m.tick.Add(1))
+	m.labels[o.label] = l
+	return l
+}
+
+// evictLocked 淘汰最久未使用的标签, 其计数并入 evicted
+func (m *clientMetrics) evictLocked() {
+	var victim string
+	var victimUsed int64
+	for label, l := range m.labels {
+		if used := l.lastUsed.Load(); victim == "" || used < victimUsed {
+			victim, victimUsed = label, used
+		}
+	}
+	if l := m.labels[victim]; l != nil {
+		s := l.snapshot()
+		m.evicted.Requests += s.Requests
+		m.evicted.Retries += s.Retries
+		m.evicted.Errors += s.Errors
+		m.evicted.CacheHits += s.CacheHits
+		delete(m.labels, victim)
+	}
+}
+
+// Metrics 返回客户端累计计数器的快照
+func (c *Client) Metrics() Metrics {
+	return c.metrics.snapshot()
+}
+
+// LabelMetrics 返回按请求标签 (Label) 划分的计数器快照.
+// 最多保留 1024 个标签, 超过时淘汰最久未使用的标签, 其计数累计在空字符串键下
+func (c *Client) LabelMetrics() map[string]Metrics {
+	c.metrics.mu.RLock()
+	defer c.metrics.mu.RUnlock()
+	labels := make(map[string]Metrics, len(c.metrics.labels)+1)
+	for label, l := range c.metrics.labels {
+		labels[label] = l.snapshot()
+	}
+	if c.metrics.evicted != (Metrics{}) {
+		labels[""] = c.metrics.evicted
+	}
+	return labels
+}
+
+// expvarClients 记录每个 expvar 名称当前对应的客户端. expvar 不支持取消发布, 每个名称只发布一次读取注册表的 expvar.Func,
+// 客户端通过 UnpublishExpvar 从注册表移除后不再被全局引用, 该名称随后输出 null
+var (
+	expvarMu      sync.Mutex
+	expvarClients = make(map[string]*Client)
+	expvarOwned   = make(map[string]bool) // 由本包发布的名称
+)
+
+// WithExpvar 将客户端的计数器 (请求, 重试, 错误, 缓存命中, 流量) 与按主机的连接池及流量统计发布到 expvar 的 name 下,
+// 已暴露 /debug/vars 的服务无需额外依赖即可观测客户端. 多个客户端使用同一名称时, 以最后创建的为准;
+// name 已被其他 expvar 变量占用时跳过发布, 需要得到错误时使用 PublishExpvar
+func WithExpvar(name string) Option {
+	return func(c *Client) {
+		c.poolStats.enabled.Store(true)
+		_ = c.publishExpvar(name)
+	}
+}
+
+// PublishExpvar 在运行中的客户端上发布 expvar, 语义同 WithExpvar; name 已被其他 expvar 变量占用时返回错误.
+// 连接池统计在发布后开始收集
+func (c *Client) PublishExpvar(name string) error {
+	if err := c.publishExpvar(name); err != nil {
+		return err
+	}
+	if !c.poolStats.enabled.Swap(true) {
+		c.rebuildChain()
+	}
+	return nil
+}
+
+// UnpublishExpvar 将客户端从 expvar 注册表中移除, 使其不再被全局引用; 名称已被其他客户端接管时不做任何事
+func (c *Client) UnpublishExpvar() {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if c.expvarName != "" && expvarClients[c.expvarName] == c {
+		delete(expvarClients, c.expvarName)
+	}
+	c.expvarName = ""
+}
+
+func (c *Client) publishExpvar(name string) error {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	if !expvarOwned[name] {
+		if expvar.Get(name) != nil {
+			return fmt.Errorf("httpc: expvar %q is already published", name)
+		}
+		expvarOwned[name] = true
+		expvar.Publish(name, expvar.Func(func() any {
+			expvarMu.Lock()
+			client := expvarClients[name]
+			expvarMu.Unlock()
+			if client == nil {
+				return nil
+			}
+			return client.expvarSnapshot()
+		}))
+	}
+	if c.expvarName != "" && c.expvarName != name && expvarClients[c.expvarName] == c {
+		delete(expvarClients, c.expvarName)
+	}
+	expvarClients[name] = c
+	c.expvarName = name
+	return nil
+}
+
+// expvarSnapshot 返回发布到 expvar 的 JSON 结构
+func (c *Client) expvarSnapshot() map[string]any {
+	m := c.Metrics()
+	pool := make(map[string]map[string]int64)
+	for host, stats := range c.PoolStats() {
+		pool[host] = map[string]int64{
+			"active":      stats.Active,
+			"idle":        stats.Idle,
+			"new_dials":   stats.NewDials,
+			"dial_errors": stats.DialErrors,
+			"reused":      stats.Reused,
+		}
+	}
+	total, hosts := c.TrafficStats()
+	traffic := make(map[string]map[string]int64, len(hosts))
+	for host, stats := range hosts {
+		traffic[host] = map[string]int64{
+			"bytes_sent":     stats.BytesSent,
+			"bytes_received": stats.BytesReceived,
+		}
+	}
+	labels := make(map[string]map[string]int64)
+	for label, lm := range c.LabelMetrics() {
+		labels[label] = map[string]int64{
+			"requests":   lm.Requests,
+			"retries":    lm.Retries,
+			"errors":     lm.Errors,
+			"cache_hits": lm.CacheHits,
+		}
+	}
+	buffers := c.BufferPoolStats()
+	return map[string]any{
+		"requests":       m.Requests,
+		"retries":        m.Retries,
+		"errors":         m.Errors,
+		"cache_hits":     m.CacheHits,
+		"bytes_sent":     total.BytesSent,
+		"bytes_received": total.BytesReceived,
+		"pool":           pool,
+		"traffic":        traffic,
+		"labels":         labels,
+		"buffer_pool": map[string]int64{
+			"gets":           buffers.Gets,
+			"hits":           buffers.Hits,
+			"drops":          buffers.Drops,
+			"retained":       buffers.Retained,
+			"bytes_retained": buffers.BytesRetained,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+package httpc
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientMetricsAndExpvar(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	// 同名再次发布不会 panic, 以最后创建的客户端为准
+	_ = New(WithExpvar("httpc_test_metrics"))
+	client := New(
+		WithExpvar("httpc_test_metrics"),
+		WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}),
+	)
+
+	if _, err := client.GET(srv.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if _, err := client.GET("http://" + closedAddr(t)).Text(); err == nil {
+		t.Fatal("request to closed port succeeded")
+	}
+
+	m := client.Metrics()
+	if m.Requests != 2 || m.Errors != 1 || m.Retries < 1 {
+		t.Fatalf("Metrics = %+v, want Requests=2 Errors=1 Retries>=1", m)
+	}
+
+	var published struct {
+		Requests int64                       `json:"requests"`
+		Errors   int64                       `json:"errors"`
+		Pool     map[string]map[string]int64 `json:"pool"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("httpc_test_metrics").String()), &published); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if published.Requests != 2 || published.Errors != 1 {
+		t.Fatalf("published = %+v, want Requests=2 Errors=1", published)
+	}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if got := published.Pool[host]["new_dials"]; got < 1 {
+		t.Fatalf("pool[%s].new_dials = %d, want >= 1", host, got)
+	}
+}
+
+func TestPublishExpvarRejectsForeignName(t *testing.T) {
+	expvar.NewInt("httpc_test_foreign")
+
+	client := New(WithExpvar("httpc_test_foreign")) // 跳过, 不会 panic
+	if err := client.PublishExpvar("httpc_test_foreign"); err == nil {
+		t.Fatal("PublishExpvar() error = nil, want name clash error")
+	}
+}
+
+func TestUnpublishExpvar(t *testing.T) {
+	first := New(WithExpvar("httpc_test_unpublish"))
+	second := New()
+	if err := second.PublishExpvar("httpc_test_unpublish"); err != nil {
+		t.Fatalf("PublishExpvar() error = %v", err)
+	}
+
+	// 名称已被 second 接管, first 移除自身不影响输出
+	first.UnpublishExpvar()
+	if got := expvar.Get("httpc_test_unpublish").String(); got == "null" {
+		t.Fatal("expvar output = null after the previous owner unpublished")
+	}
+
+	second.UnpublishExpvar()
+	if got := expvar.Get("httpc_test_unpublish").String(); got != "null" {
+		t.Fatalf("expvar output = %s, want null", got)
+	}
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if _, ok := expvarClients["httpc_test_unpublish"]; ok {
+		t.Fatal("client still registered after UnpublishExpvar")
+	}
+}
+
+func TestLabelMetricsEvictsLeastRecentlyUsed(t *testing.T) {
+	client := New()
+	label := func(name string) *labelCounters {
+		return client.metrics.labeled(ContextWithOptions(context.Background(), Label(name)))
+	}
+	for i := range maxMetricLabels {
+		label(fmt.Sprintf("l%d", i)).requests.Add(1)
+	}
+	label("l0").requests.Add(1) // l0 最近使用过, 淘汰 l1
+	label("new").requests.Add(1)
+
+	labels := client.LabelMetrics()
+	if len(labels) != maxMetricLabels+1 {
+		t.Fatalf("len(labels) = %d, want %d labels plus the evicted total", len(labels), maxMetricLabels+1)
+	}
+	if _, ok := labels["l1"]; ok {
+		t.Fatal("least recently used label was not evicted")
+	}
+	if labels["l0"].Requests != 2 || labels[""].Requests != 1 {
+		t.Fatalf("l0 = %+v, evicted = %+v, want 2 and 1 requests", labels["l0"], labels[""])
+	}
+}
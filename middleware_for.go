@@ -0,0 +1,56 @@
+package httpc
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// RequestMatcher 判断请求是否需要经过某个中间件
+type RequestMatcher func(req *http.Request) bool
+
+// MatchHost 返回按主机 glob 匹配的 RequestMatcher (语法同 path.Match, 不区分大小写).
+// 模式包含端口时与 host:port 比较, 否则只比较主机名; "*.example.com" 不匹配 example.com 本身, 无效的模式不匹配任何请求
+func MatchHost(patterns ...string) RequestMatcher {
+	lowered := make([]string, len(patterns))
+	for i, p := range patterns {
+		lowered[i] = strings.ToLower(p)
+	}
+	return func(req *http.Request) bool {
+		host := strings.ToLower(req.URL.Hostname())
+		hostPort := strings.ToLower(req.URL.Host)
+		for _, p := range lowered {
+			target := host
+			if strings.Contains(p, ":") {
+				target = hostPort
+			}
+			if ok, err := path.Match(p, target); err == nil && ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithMiddlewareFor 添加只作用于匹配请求的中间件, 不匹配的请求直接交给下一层.
+// 与 WithMiddleware 共享同一条中间件链, 顺序按添加顺序, 例如仅对某个 API 签名或仅对测试环境输出详细日志
+func WithMiddlewareFor(match RequestMatcher, middleware ...MiddlewareFunc) Option {
+	return func(c *Client) {
+		for _, mw := range middleware {
+			c.middlewares = append(c.middlewares, conditionalMiddleware(match, mw))
+		}
+	}
+}
+
+// conditionalMiddleware 将中间件包装为仅对 match 返回 true 的请求生效
+func conditionalMiddleware(match RequestMatcher, mw MiddlewareFunc) MiddlewareFunc {
+	return func(next http.RoundTripper) http.RoundTripper {
+		wrapped := mw(next)
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if match(req) {
+				return wrapped.RoundTrip(req)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
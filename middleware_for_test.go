@@ -0,0 +1,65 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		url      string
+		want     bool
+	}{
+		{[]string{"api.example.com"}, "https://API.example.com/v1", true},
+		{[]string{"*.example.com"}, "https://a.example.com/", true},
+		{[]string{"*.example.com"}, "https://example.com/", false},
+		{[]string{"*.example.com", "example.com"}, "https://example.com:8443/", true},
+		{[]string{"example.com:8443"}, "https://example.com:8443/", true},
+		{[]string{"example.com:8443"}, "https://example.com/", false},
+		{[]string{"[bad"}, "https://example.com/", false},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+		if got := MatchHost(tt.patterns...)(req); got != tt.want {
+			t.Fatalf("MatchHost(%q)(%s) = %v, want %v", tt.patterns, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestWithMiddlewareFor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("X-Signed")))
+	}))
+	defer srv.Close()
+	localhostURL := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	var all, staging atomic.Int32
+	sign := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Signed", "yes")
+			return next.RoundTrip(req)
+		})
+	}
+	client := New(
+		WithMiddleware(countingMiddleware(&all)),
+		WithMiddlewareFor(MatchHost("localhost"), sign, countingMiddleware(&staging)),
+	)
+
+	got, err := client.GET(localhostURL).Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if got != "yes" {
+		t.Fatalf("matched request X-Signed = %q, want %q", got, "yes")
+	}
+	if got, err = client.GET(srv.URL).Text(); err != nil || got != "" {
+		t.Fatalf("unmatched request X-Signed = %q (err %v), want empty", got, err)
+	}
+	if all.Load() != 2 || staging.Load() != 1 {
+		t.Fatalf("counts = %d/%d, want 2/1", all.Load(), staging.Load())
+	}
+}
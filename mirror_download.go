@@ -0,0 +1,319 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+)
+
+// 多源下载默认配置
+const (
+	defaultMirrorChunkSize   = 4 << 20 // 4MB
+	defaultMirrorMaxFailures = 2
+	maxMirrorConcurrency     = 8
+)
+
+// 多源下载错误定义
+var (
+	ErrAllMirrorsFailed = errors.New("httpc: all mirrors failed")
+	ErrChecksumMismatch = errors.New("httpc: checksum mismatch")
+)
+
+// MirrorDownloadOptions 多源下载配置
+type MirrorDownloadOptions struct {
+	ChunkSize    int64         // 每个 Range 分块的大小, 默认 4MB
+	Concurrency  int           // 并发分块数, 默认等于镜像数量 (最多 8)
+	MaxFailures  int           // 镜像累计失败多少次后被剔除, 默认 2
+	ChunkTimeout time.Duration // 单个分块的超时时间, 超时视为该镜像过慢并计入失败; 0 表示不限制
+
+	Hash     func() hash.Hash // 校验使用的哈希算法, 例如 sha256.New
+	Checksum []byte           // 期望的摘要值, 与 Hash 同时设置时在完成后校验
+
+	OnProgress func(downloaded, total int64) // 进度回调 (可能被并发调用)
+}
+
+// mirrorSet 维护仍然可用的镜像, 并以轮询方式分配
+type mirrorSet struct {
+	mu          sync.Mutex
+	urls        []string
+	failures    map[string]int
+	maxFailures int
+	next        atomic.Uint64
+}
+
+func (m *mirrorSet) pick() (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.urls) == 0 {
+		return "", false
+	}
+	return m.urls[m.next.Add(1)%uint64(len(m.urls))], true
+}
+
+// fail 记录一次失败, 失败次数达到上限时剔除该镜像
+func (m *mirrorSet) fail(mirror string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[mirror]++
+	if m.failures[mirror] < m.maxFailures {
+		return
+	}
+	for i, u := range m.urls {
+		if u == mirror {
+			m.urls = append(m.urls[:i], m.urls[i+1:]...)
+			break
+		}
+	}
+}
+
+// DownloadFromMirrors 从多个镜像并发下载同一文件到 filePath
+// 若镜像支持 Range, 文件被切分为分块并轮询分配给各镜像; 失败或超时的分块会换镜像重试,
+// 多次失败的镜像会被剔除. 镜像不支持 Range 时回退为从首个可用镜像整体下载.
+// 设置了 Hash 与 Checksum 时, 完成后校验摘要, 不匹配返回 ErrChecksumMismatch
+func (c *Client) DownloadFromMirrors(ctx context.Context, mirrors []string, filePath string, opts MirrorDownloadOptions) error {
+	if len(mirrors) == 0 {
+		return fmt.Errorf("%w: no mirrors given", ErrAllMirrorsFailed)
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultMirrorChunkSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = min(len(mirrors), maxMirrorConcurrency)
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = defaultMirrorMaxFailures
+	}
+
+	set := &mirrorSet{
+		urls:        append([]string(nil), mirrors...),
+		failures:    make(map[string]int),
+		maxFailures: opts.MaxFailures,
+	}
+
+	size, ranged, err := c.probeMirrors(ctx, set)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".httpc-download-*")
+	if err != nil {
+		return fmt.Errorf("httpc: create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if ranged {
+		err = c.downloadRanges(ctx, set, tmp, size, opts)
+	} else {
+		err = c.downloadWhole(ctx, set, tmp, opts)
+	}
+	if err == nil && opts.Hash != nil && opts.Checksum != nil {
+		err = verifyChecksum(tmp, opts.Hash(), opts.Checksum)
+	}
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpName, filePath)
+}
+
+// probeMirrors 依次对镜像发送 HEAD 请求, 获取文件大小以及是否支持 Range
+func (c *Client) probeMirrors(ctx context.Context, set *mirrorSet) (int64, bool, error) {
+	var lastErr error
+	for _, mirror := range append([]string(nil), set.urls...) {
+		resp, err := c.HEAD(mirror).WithContext(ctx).Execute()
+		if err != nil {
+			closeResponse(resp)
+			lastErr = err
+			set.fail(mirror)
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			lastErr = c.errorResponse(resp)
+			resp.Body.Close()
+			set.fail(mirror)
+			continue
+		}
+		resp.Body.Close()
+		ranged := resp.Header.Get("Accept-Ranges") == "bytes" && resp.ContentLength > 0
+		return resp.ContentLength, ranged, nil
+	}
+	return 0, false, fmt.Errorf("%w: %w", ErrAllMirrorsFailed, lastErr)
+}
+
+// downloadRanges 将文件切分为分块并分配给镜像并发下载
+func (c *Client) downloadRanges(ctx context.Context, set *mirrorSet, dst *os.File, size int64, opts MirrorDownloadOptions) error {
+	if err := dst.Truncate(size); err != nil {
+		return err
+	}
+
+	type chunk struct{ start, end int64 } // end 为闭区间
+	chunks := make(chan chunk, size/opts.ChunkSize+1)
+	var remaining atomic.Int64
+	for start := int64(0); start < size; start += opts.ChunkSize {
+		chunks <- chunk{start: start, end: min(start+opts.ChunkSize, size) - 1}
+		remaining.Add(1)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var downloaded atomic.Int64
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var ch chunk
+				select {
+				case <-ctx.Done():
+					return
+				case ch = <-chunks:
+				}
+
+				mirror, ok := set.pick()
+				if !ok {
+					cancel(ErrAllMirrorsFailed)
+					return
+				}
+				n, err := c.fetchRange(ctx, mirror, dst, ch.start, ch.end, opts.ChunkTimeout)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					set.fail(mirror)
+					chunks <- ch // 换镜像重试
+					continue
+				}
+				total := downloaded.Add(n)
+				if opts.OnProgress != nil {
+					opts.OnProgress(total, size)
+				}
+				if remaining.Add(-1) == 0 {
+					cancel(nil)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if remaining.Load() == 0 {
+		return nil
+	}
+	if err := context.Cause(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// fetchRange 从镜像下载 [start, end] 字节并写入 dst 对应位置
+func (c *Client) fetchRange(ctx context.Context, mirror string, dst io.WriterAt, start, end int64, timeout time.Duration) (int64, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := c.GET(mirror).
+		WithContext(ctx).
+		SetHeader("Range", "bytes="+strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)).
+		Execute()
+	if err != nil {
+		closeResponse(resp)
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		if resp.StatusCode >= 400 {
+			return 0, c.errorResponse(resp)
+		}
+		return 0, fmt.Errorf("httpc: mirror %s ignored Range request (status %d)", mirror, resp.StatusCode)
+	}
+
+	want := end - start + 1
+	buf := c.bufferPool.Get()
+	defer c.bufferPool.Put(buf)
+	n, err := iox.Copy(buf, io.LimitReader(resp.Body, want))
+	if err != nil {
+		return 0, err
+	}
+	if n != want {
+		return 0, fmt.Errorf("httpc: mirror %s returned %d bytes, want %d", mirror, n, want)
+	}
+	if _, err := dst.WriteAt(buf.Bytes(), start); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// downloadWhole 在镜像不支持 Range 时按顺序尝试整体下载
+func (c *Client) downloadWhole(ctx context.Context, set *mirrorSet, dst *os.File, opts MirrorDownloadOptions) error {
+	var lastErr error
+	for _, mirror := range append([]string(nil), set.urls...) {
+		if _, err := dst.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err := dst.Truncate(0); err != nil {
+			return err
+		}
+
+		resp, err := c.GET(mirror).WithContext(ctx).Execute()
+		if err != nil {
+			closeResponse(resp)
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			lastErr = c.errorResponse(resp)
+			resp.Body.Close()
+			continue
+		}
+		n, err := iox.Copy(dst, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(n, n)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrAllMirrorsFailed, lastErr)
+}
+
+func verifyChecksum(file *os.File, h hash.Hash, want []byte) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := iox.Copy(h, file); err != nil {
+		return err
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: got %x, want %x", ErrChecksumMismatch, got, want)
+	}
+	return nil
+}
+
+// closeResponse 关闭与错误一同返回的响应 (按状态码重试耗尽时), resp 为 nil 时不做任何事
+func closeResponse(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
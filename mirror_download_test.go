@@ -0,0 +1,114 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientDownloadFromMirrorsDropsFailingMirror(t *testing.T) {
+	payload := bytes.Repeat([]byte("mirror-data-"), 100)
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer good.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(payload))
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	sum := sha256.Sum256(payload)
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	err := New(WithRetryOptions(RetryOptions{})).DownloadFromMirrors(context.Background(),
+		[]string{broken.URL, good.URL}, dst, MirrorDownloadOptions{
+			ChunkSize: 100,
+			Hash:      sha256.New,
+			Checksum:  sum[:],
+		})
+	if err != nil {
+		t.Fatalf("DownloadFromMirrors() error = %v", err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(data, payload) {
+		t.Fatalf("downloaded %d bytes, want %d identical bytes", len(data), len(payload))
+	}
+}
+
+func TestClientDownloadFromMirrorsVerifiesChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader([]byte("tampered")))
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256([]byte("original"))
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	err := New().DownloadFromMirrors(context.Background(), []string{server.URL}, dst, MirrorDownloadOptions{
+		Hash:     sha256.New,
+		Checksum: sum[:],
+	})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("DownloadFromMirrors() error = %v, want ErrChecksumMismatch", err)
+	}
+	if _, statErr := os.Stat(dst); !os.IsNotExist(statErr) {
+		t.Fatalf("destination exists after checksum mismatch: %v", statErr)
+	}
+}
+
+func TestClientDownloadFromMirrorsFailsWhenAllMirrorsFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := New().DownloadFromMirrors(context.Background(), []string{server.URL}, filepath.Join(t.TempDir(), "f"), MirrorDownloadOptions{})
+	if !errors.Is(err, ErrAllMirrorsFailed) {
+		t.Fatalf("DownloadFromMirrors() error = %v, want ErrAllMirrorsFailed", err)
+	}
+}
+
+func TestClientDownloadFromMirrorsClosesBodiesWhenRetriesExhausted(t *testing.T) {
+	payload := bytes.Repeat([]byte("mirror-data-"), 100)
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(payload))
+	}))
+	defer good.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer broken.Close()
+
+	var open atomic.Int32
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}),
+		WithMiddleware(bodyCloseCounter(&open)),
+	)
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(payload) // 不支持 Range, 走整体下载
+	}))
+	defer plain.Close()
+
+	dst := filepath.Join(t.TempDir(), "file.bin")
+	for _, mirror := range []string{good.URL, plain.URL} {
+		if err := client.DownloadFromMirrors(context.Background(), []string{broken.URL, mirror}, dst, MirrorDownloadOptions{ChunkSize: 100}); err != nil {
+			t.Fatalf("DownloadFromMirrors(%s) error = %v", mirror, err)
+		}
+	}
+	if n := open.Load(); n != 0 {
+		t.Fatalf("%d response bodies left open", n)
+	}
+}
@@ -0,0 +1,62 @@
+package httpc
+
+import (
+	"iter"
+	"net/http"
+)
+
+// PageHeaderSetter 由页面类型 (的指针) 实现时, Paginate 在调用 next 之前传入该页的响应头,
+// 用于读取位于响应头中的游标或偏移量
+type PageHeaderSetter interface {
+	SetPageHeader(header http.Header)
+}
+
+// Paginate 依次请求并解码每一页, 通过迭代器返回.
+// 每页按 DecodeAuto 的规则解码为 T, 然后调用 next 根据该页 (游标, 偏移量等) 构建下一页的请求;
+// next 返回 ok 为 false 时结束. 请求或解码失败时返回零值与错误并结束, 提前退出循环不会发出后续请求
+//
+//	for page, err := range httpc.Paginate(client.GET(url), func(p ListPage) (*httpc.RequestBuilder, bool) {
+//		return client.GET(url).SetQueryParam("cursor", p.NextCursor), p.NextCursor != ""
+//	}) {
+//		...
+//	}
+func Paginate[T any](rb *RequestBuilder, next func(page T) (*RequestBuilder, bool)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for rb != nil {
+			page, err := fetchPage[T](rb)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(page, nil) {
+				return
+			}
+
+			var ok bool
+			if rb, ok = next(page); !ok {
+				return
+			}
+		}
+	}
+}
+
+// fetchPage 执行单页请求并解码
+func fetchPage[T any](rb *RequestBuilder) (T, error) {
+	var page T
+	resp, err := rb.Execute()
+	if err != nil {
+		return page, err
+	}
+	defer resp.Body.Close()
+
+	if err := rb.client.decodeAutoResponse(resp, rb.accept, &page); err != nil {
+		return page, err
+	}
+	if setter, ok := any(page).(PageHeaderSetter); ok {
+		setter.SetPageHeader(resp.Header)
+	} else if setter, ok := any(&page).(PageHeaderSetter); ok {
+		setter.SetPageHeader(resp.Header)
+	}
+	return page, nil
+}
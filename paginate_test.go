@@ -0,0 +1,118 @@
+package httpc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+type cursorPage struct {
+	Items      []int  `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+type headerPage struct {
+	Items []int `json:"items"`
+	next  string
+}
+
+func (p *headerPage) SetPageHeader(header http.Header) {
+	p.next = header.Get("X-Next-Offset")
+}
+
+func newPagedServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		next := ""
+		if offset < 4 {
+			next = strconv.Itoa(offset + 2)
+			w.Header().Set("X-Next-Offset", next)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"items":[%d,%d],"next_cursor":%q}`, offset, offset+1, next)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPaginateBodyCursor(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPagedServer(t, &requests)
+	client := New()
+
+	var items []int
+	for page, err := range Paginate(client.GET(srv.URL), func(p cursorPage) (*RequestBuilder, bool) {
+		return client.GET(srv.URL).SetQueryParam("offset", p.NextCursor), p.NextCursor != ""
+	}) {
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		items = append(items, page.Items...)
+	}
+	if got := fmt.Sprint(items); got != "[0 1 2 3 4 5]" {
+		t.Fatalf("items = %s, want [0 1 2 3 4 5]", got)
+	}
+}
+
+func TestPaginateHeaderCursor(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPagedServer(t, &requests)
+	client := New()
+
+	pages := 0
+	for _, err := range Paginate(client.GET(srv.URL), func(p *headerPage) (*RequestBuilder, bool) {
+		return client.GET(srv.URL).SetQueryParam("offset", p.next), p.next != ""
+	}) {
+		if err != nil {
+			t.Fatalf("Paginate() error = %v", err)
+		}
+		pages++
+	}
+	if pages != 3 {
+		t.Fatalf("pages = %d, want 3", pages)
+	}
+}
+
+func TestPaginateStopsEarly(t *testing.T) {
+	var requests atomic.Int32
+	srv := newPagedServer(t, &requests)
+	client := New()
+
+	for range Paginate(client.GET(srv.URL), func(p cursorPage) (*RequestBuilder, bool) {
+		return client.GET(srv.URL).SetQueryParam("offset", p.NextCursor), p.NextCursor != ""
+	}) {
+		break
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("requests = %d, want 1", got)
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer srv.Close()
+	client := New()
+
+	calls := 0
+	for _, err := range Paginate(client.GET(srv.URL), func(p cursorPage) (*RequestBuilder, bool) {
+		t.Fatal("next called after error")
+		return nil, false
+	}) {
+		calls++
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusGone {
+			t.Fatalf("err = %v, want HTTPError 410", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("iterations = %d, want 1", calls)
+	}
+}
@@ -0,0 +1,212 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+)
+
+// HostPoolStats 描述单个主机的连接池统计
+// Idle 与 Active 基于 httptrace 事件估算: HTTP/2 下 Active 近似为进行中的流数量,
+// 且被 Transport 因超时关闭的空闲连接不会从 Idle 中扣除
+type HostPoolStats struct {
+	Active     int64 // 正在被请求占用的连接数
+	Idle       int64 // 归还到空闲池的连接数
+	NewDials   int64 // 成功新建的连接数 (累计)
+	DialErrors int64 // 建立连接失败的次数 (累计)
+	Reused     int64 // 复用已有连接的请求数 (累计)
+}
+
+// hostPoolCounters 是 HostPoolStats 的并发安全版本
+type hostPoolCounters struct {
+	active     atomic.Int64
+	idle       atomic.Int64
+	newDials   atomic.Int64
+	dialErrors atomic.Int64
+	reused     atomic.Int64
+
+	bytesSent     atomic.Int64 // 请求体字节数, 见 TrafficStats
+	bytesReceived atomic.Int64 // 响应体字节数, 见 TrafficStats
+
+	lastUsed atomic.Int64 // 最近一次使用的序号, 用于淘汰
+}
+
+func (h *hostPoolCounters) snapshot() HostPoolStats {
+	return HostPoolStats{
+		Active:     h.active.Load(),
+		Idle:       max(h.idle.Load(), 0),
+		NewDials:   h.newDials.Load(),
+		DialErrors: h.dialErrors.Load(),
+		Reused:     h.reused.Load(),
+	}
+}
+
+// maxStatsHosts 是按主机统计保留的主机数上限, 超过时淘汰最久未使用的主机
+const maxStatsHosts = 1024
+
+// poolStats 按主机聚合连接池与流量统计
+type poolStats struct {
+	enabled atomic.Bool // 是否收集连接池事件, 由 WithPoolStats 或 WithExpvar 开启
+
+	mu      sync.RWMutex
+	hosts   map[string]*hostPoolCounters
+	tick    atomic.Int64
+	evicted TrafficStats // 已淘汰主机的累计流量, 保证总量不因淘汰而减少
+}
+
+func (p *poolStats) host(host string) *hostPoolCounters {
+	p.mu.RLock()
+	h, ok := p.hosts[host]
+	p.mu.RUnlock()
+	if ok {
+		h.lastUsed.Store(p.tick.Add(1))
+		return h
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h, ok = p.hosts[host]; ok {
+		h.lastUsed.Store(p.tick.Add(1))
+		return h
+	}
+	if p.hosts == nil {
+		p.hosts = make(map[string]*hostPoolCounters)
+	}
+	if len(p.hosts) >= maxStatsHosts {
+		p.evictLocked()
+	}
+	h = &hostPoolCounters{}
+	h.lastUsed.Store(p.tick.Add(1))
+	p.hosts[host] = h
+	return h
+}
+
+// evictLocked 淘汰最久未使用的主机, 优先淘汰没有活跃与空闲连接的主机
+func (p *poolStats) evictLocked() {
+	var victim string
+	var victimUsed int64
+	victimBusy := true
+	for host, h := range p.hosts {
+		busy := h.active.Load() > 0 || h.idle.Load() > 0
+		used := h.lastUsed.Load()
+		if victim == "" || (victimBusy && !busy) || (busy == victimBusy && used < victimUsed) {
+			victim, victimUsed, victimBusy = host, used, busy
+		}
+	}
+	if h := p.hosts[victim]; h != nil {
+		p.evicted.BytesSent += h.bytesSent.Load()
+		p.evicted.BytesReceived += h.bytesReceived.Load()
+		delete(p.hosts, victim)
+	}
+}
+
+// WithPoolStats 开启按主机的连接池统计 (通过 httptrace 收集连接事件), 结果通过 PoolStats 读取.
+// 未开启时不追踪连接事件, PoolStats 返回空; WithExpvar 会自动开启
+func WithPoolStats() Option {
+	return func(c *Client) {
+		c.poolStats.enabled.Store(true)
+	}
+}
+
+// PoolStats 返回按主机 (host:port) 聚合的连接池统计快照, 需要 WithPoolStats.
+// 经由 HTTP 代理的 http:// 请求共享到代理的连接, 按代理地址统计
+func (c *Client) PoolStats() map[string]HostPoolStats {
+	c.poolStats.mu.RLock()
+	defer c.poolStats.mu.RUnlock()
+
+	stats := make(map[string]HostPoolStats, len(c.poolStats.hosts))
+	if !c.poolStats.enabled.Load() {
+		return stats
+	}
+	for host, counters := range c.poolStats.hosts {
+		if s := counters.snapshot(); s != (HostPoolStats{}) {
+			stats[host] = s
+		}
+	}
+	return stats
+}
+
+// poolKey 返回请求所用连接池的统计键: 经由 HTTP 代理转发的 http:// 请求共享到代理的连接, 按代理地址统计;
+// 其他请求 (直连, CONNECT 隧道, SOCKS5) 的连接属于目标主机
+func (c *Client) poolKey(req *http.Request) string {
+	if req.URL.Scheme == "http" {
+		if proxy := c.settings().proxy; proxy != nil {
+			if u, err := proxy(req); err == nil && u != nil && u.Scheme != "socks5" && u.Scheme != "socks5h" {
+				return u.Host
+			}
+		}
+	}
+	return req.URL.Host
+}
+
+// poolStatsRoundTripper 是一个内部中间件, 通过 httptrace 收集连接获取/建立/归还事件
+func (c *Client) poolStatsRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		counters := c.poolStats.host(c.poolKey(req))
+		var gotConn atomic.Bool
+
+		trace := &httptrace.ClientTrace{
+			GotConn: func(info httptrace.GotConnInfo) {
+				gotConn.Store(true)
+				counters.active.Add(1)
+				if info.Reused {
+					counters.reused.Add(1)
+				}
+				if info.WasIdle {
+					counters.idle.Add(-1)
+				}
+			},
+			ConnectDone: func(network, addr string, err error) {
+				if err != nil {
+					counters.dialErrors.Add(1)
+					return
+				}
+				counters.newDials.Add(1)
+			},
+			PutIdleConn: func(err error) {
+				if err == nil {
+					counters.idle.Add(1)
+				}
+			},
+		}
+		tracedReq := req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+		resp, err := next.RoundTrip(tracedReq)
+		if resp != nil {
+			resp.Request = req // 对调用方隐藏内部追踪用的请求副本
+		}
+		release := sync.OnceFunc(func() {
+			if gotConn.Load() {
+				counters.active.Add(-1)
+			}
+		})
+		if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			release()
+			return resp, err
+		}
+		resp.Body = &releaseOnDoneBody{ReadCloser: resp.Body, release: release}
+		return resp, nil
+	})
+}
+
+// releaseOnDoneBody 在响应体读到 EOF 或关闭时释放连接占用计数
+type releaseOnDoneBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseOnDoneBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *releaseOnDoneBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
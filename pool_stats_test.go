@@ -0,0 +1,84 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestClientPoolStatsTracksDialsAndReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := New(WithPoolStats())
+	for range 3 {
+		if _, err := client.GET(server.URL).Text(); err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+	}
+
+	u, _ := url.Parse(server.URL)
+	stats, ok := client.PoolStats()[u.Host]
+	if !ok {
+		t.Fatalf("PoolStats() missing host %s", u.Host)
+	}
+	if stats.NewDials != 1 {
+		t.Fatalf("NewDials = %d, want 1", stats.NewDials)
+	}
+	if stats.Reused != 2 {
+		t.Fatalf("Reused = %d, want 2", stats.Reused)
+	}
+	if stats.Active != 0 {
+		t.Fatalf("Active = %d, want 0 after bodies are closed", stats.Active)
+	}
+	if stats.Idle != 1 {
+		t.Fatalf("Idle = %d, want 1", stats.Idle)
+	}
+}
+
+func TestClientPoolStatsDisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client := New()
+	if _, err := client.GET(server.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if stats := client.PoolStats(); len(stats) != 0 {
+		t.Fatalf("PoolStats() = %+v, want empty without WithPoolStats", stats)
+	}
+}
+
+func TestPoolStatsEvictsLeastRecentlyUsed(t *testing.T) {
+	p := &poolStats{}
+	p.host("keep").bytesSent.Add(1)
+	busy := p.host("busy")
+	busy.active.Add(1)
+	for i := range maxStatsHosts - 2 {
+		p.host("h" + strconv.Itoa(i)).bytesSent.Add(10)
+	}
+	p.host("keep") // 刷新使用时间
+
+	p.host("new")
+	if len(p.hosts) != maxStatsHosts {
+		t.Fatalf("len(hosts) = %d, want %d", len(p.hosts), maxStatsHosts)
+	}
+	for _, host := range []string{"keep", "busy", "new"} {
+		if _, ok := p.hosts[host]; !ok {
+			t.Fatalf("host %q evicted, want the oldest idle host evicted", host)
+		}
+	}
+	if _, ok := p.hosts["h0"]; ok {
+		t.Fatalf("host h0 kept, want it evicted")
+	}
+	if p.evicted.BytesSent != 10 {
+		t.Fatalf("evicted.BytesSent = %d, want 10", p.evicted.BytesSent)
+	}
+}
@@ -0,0 +1,101 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+)
+
+// ErrUnknownProfile 表示 WithProfile 引用了未通过 DefineProfile 定义的配置组
+var ErrUnknownProfile = errors.New("httpc: unknown profile")
+
+// ProfileOptions 命名配置组, 用于将访问同一服务的常用设置打包复用
+type ProfileOptions struct {
+	Headers   map[string]string // 默认请求头, 请求上显式设置的同名 Header 优先
+	Timeout   time.Duration     // 整个请求 (含重试) 的超时, 0 使用客户端配置, 负数表示不超时
+	Retry     *RetryOptions     // 重试策略, nil 使用客户端配置
+	RateLimit *RateLimit        // 独立的限速 (令牌桶在使用同一配置组的请求间共享), nil 使用客户端配置, RequestsPerSecond <= 0 表示不限速
+}
+
+// profile 是已定义的配置组, 定义后不可修改
+type profile struct {
+	opts        ProfileOptions
+	rateLimiter *rateLimiter
+}
+
+type profileKey struct{}
+
+// DefineProfile 定义 (或替换) 命名配置组, 可与进行中的请求并发调用.
+// 替换时若限速参数未变化则保留现有令牌桶, 已开始的请求继续使用旧配置
+func (c *Client) DefineProfile(name string, opts ProfileOptions) {
+	opts.Headers = maps.Clone(opts.Headers)
+	if opts.Retry != nil {
+		retry := *opts.Retry
+		opts.Retry = &retry
+	}
+	p := &profile{opts: opts}
+
+	c.profilesMu.Lock()
+	defer c.profilesMu.Unlock()
+	if opts.RateLimit != nil {
+		if old, ok := c.profiles[name]; ok && old.rateLimiter != nil && old.rateLimiter.limit == *opts.RateLimit {
+			p.rateLimiter = old.rateLimiter
+		} else {
+			p.rateLimiter = newRateLimiter(*opts.RateLimit)
+		}
+	}
+	if c.profiles == nil {
+		c.profiles = make(map[string]*profile)
+	}
+	c.profiles[name] = p
+}
+
+// WithProfile 让本次请求使用命名配置组, 在 Build 时解析, 未定义的配置组返回 ErrUnknownProfile
+func (rb *RequestBuilder) WithProfile(name string) *RequestBuilder {
+	rb.profile = name
+	return rb
+}
+
+// lookupProfile 返回命名配置组
+func (c *Client) lookupProfile(name string) (*profile, error) {
+	c.profilesMu.RLock()
+	p, ok := c.profiles[name]
+	c.profilesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProfile, name)
+	}
+	return p, nil
+}
+
+// applyHeaders 设置请求上尚未设置的默认请求头
+func (p *profile) applyHeaders(header http.Header) {
+	for key, value := range p.opts.Headers {
+		if header.Get(key) == "" {
+			header.Set(key, value)
+		}
+	}
+}
+
+// apply 返回应用配置组后的配置快照副本
+func (p *profile) apply(settings *liveConfig) *liveConfig {
+	l := *settings
+	if p.opts.Retry != nil {
+		l.retryOpts = *p.opts.Retry
+	}
+	if p.opts.Timeout != 0 {
+		l.timeout = max(p.opts.Timeout, 0)
+	}
+	if p.opts.RateLimit != nil {
+		l.rateLimiter = p.rateLimiter
+	}
+	return &l
+}
+
+// requestProfile 返回请求使用的配置组
+func requestProfile(ctx context.Context) *profile {
+	p, _ := ctx.Value(profileKey{}).(*profile)
+	return p
+}
@@ -0,0 +1,117 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestProfileHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Accept") + "|" + r.Header.Get("User-Agent")))
+	}))
+	defer srv.Close()
+
+	client := New()
+	client.DefineProfile("github", ProfileOptions{Headers: map[string]string{
+		"Accept":     "application/vnd.github+json",
+		"User-Agent": "octo-app",
+	}})
+
+	got, err := client.GET(srv.URL).WithProfile("github").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if want := "application/vnd.github+json|octo-app"; got != want {
+		t.Fatalf("headers = %q, want %q", got, want)
+	}
+
+	// 请求上显式设置的 Header 优先
+	got, err = client.GET(srv.URL).WithProfile("github").SetHeader("Accept", "text/plain").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if want := "text/plain|octo-app"; got != want {
+		t.Fatalf("headers = %q, want %q", got, want)
+	}
+}
+
+func TestProfileRetryAndTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	client.DefineProfile("flaky", ProfileOptions{
+		Retry: &RetryOptions{MaxAttempts: 3, RetryStatuses: []int{http.StatusServiceUnavailable}},
+	})
+	client.DefineProfile("fast", ProfileOptions{Timeout: 20 * time.Millisecond})
+
+	resp, err := client.GET(srv.URL).WithProfile("flaky").Execute()
+	if err == nil {
+		resp.Body.Close()
+	}
+	if got := attempts.Load(); got != 4 {
+		t.Fatalf("attempts = %d, want 4", got)
+	}
+
+	attempts.Store(0)
+	if _, err := client.GET(srv.URL).Bytes(); err == nil {
+		t.Fatal("Bytes() error = nil, want 503")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("attempts without profile = %d, want 1", got)
+	}
+
+	_, err = client.GET(srv.URL + "/slow").WithProfile("fast").Bytes()
+	if !errors.Is(err, ErrRequestTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want timeout", err)
+	}
+}
+
+func TestProfileRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	client := New(WithClock(clock))
+	client.DefineProfile("limited", ProfileOptions{RateLimit: &RateLimit{RequestsPerSecond: 1, Burst: 1}})
+
+	if _, err := client.GET(srv.URL).WithProfile("limited").Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	// 未使用配置组的请求不受限速影响
+	if _, err := client.GET(srv.URL).Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	// 重新定义且限速参数不变时保留令牌桶
+	client.DefineProfile("limited", ProfileOptions{RateLimit: &RateLimit{RequestsPerSecond: 1, Burst: 1}})
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GET(srv.URL).WithProfile("limited").Bytes()
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+}
+
+func TestUnknownProfile(t *testing.T) {
+	client := New()
+	if _, err := client.GET("http://example.com").WithProfile("missing").Build(); !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("Build() error = %v, want ErrUnknownProfile", err)
+	}
+}
@@ -0,0 +1,113 @@
+package httpc
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// ProxyProtocolVersion 是 HAProxy PROXY 协议版本
+type ProxyProtocolVersion int
+
+const (
+	ProxyProtocolV1 ProxyProtocolVersion = 1 // 文本格式
+	ProxyProtocolV2 ProxyProtocolVersion = 2 // 二进制格式
+)
+
+// proxyProtocol 是 WithProxyProtocol 的配置
+type proxyProtocol struct {
+	version ProxyProtocolVersion
+	source  net.Addr
+}
+
+// WithProxyProtocol 在每条新建连接上首先写入 PROXY 协议头, 向要求该协议的后端或负载均衡器告知原始客户端地址,
+// 适用于 httpc 作为中继使用的场景. source 为原始客户端地址 (通常为 *net.TCPAddr), nil 时使用连接的本地地址;
+// 目标地址取连接的远端地址. 源地址与目标地址族不同或无法解析时发送 UNKNOWN (v1) 或 UNSPEC (v2) 头.
+// 使用 HTTP 代理时协议头发送给代理服务器
+func WithProxyProtocol(version ProxyProtocolVersion, source net.Addr) Option {
+	return func(c *Client) {
+		if version != ProxyProtocolV1 && version != ProxyProtocolV2 {
+			return
+		}
+		c.proxyProtocol = &proxyProtocol{version: version, source: source}
+	}
+}
+
+// proxyProtocolV2Signature 是 v2 协议头的固定前缀
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// writeHeader 在连接上写入协议头, 遵循 ctx 的截止时间
+func (p *proxyProtocol) writeHeader(ctx context.Context, conn net.Conn) error {
+	source := p.source
+	if source == nil {
+		source = conn.LocalAddr()
+	}
+	header := p.header(source, conn.RemoteAddr())
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+		defer conn.SetWriteDeadline(time.Time{})
+	}
+	if _, err := conn.Write(header); err != nil {
+		return fmt.Errorf("httpc: failed to write PROXY protocol header: %w", err)
+	}
+	return nil
+}
+
+// header 编码协议头
+func (p *proxyProtocol) header(source, dest net.Addr) []byte {
+	src, srcOK := addrPort(source)
+	dst, dstOK := addrPort(dest)
+	known := srcOK && dstOK && src.Addr().Is4() == dst.Addr().Is4()
+
+	if p.version == ProxyProtocolV1 {
+		if !known {
+			return []byte("PROXY UNKNOWN\r\n")
+		}
+		family := "TCP6"
+		if src.Addr().Is4() {
+			family = "TCP4"
+		}
+		return []byte("PROXY " + family + " " + src.Addr().String() + " " + dst.Addr().String() + " " +
+			strconv.Itoa(int(src.Port())) + " " + strconv.Itoa(int(dst.Port())) + "\r\n")
+	}
+
+	header := append([]byte(nil), proxyProtocolV2Signature...)
+	header = append(header, 0x21) // 版本 2, PROXY 命令
+	if !known {
+		return append(header, 0x00, 0x00, 0x00) // UNSPEC, 无地址
+	}
+	if src.Addr().Is4() {
+		header = append(header, 0x11, 0x00, 12) // TCP over IPv4
+	} else {
+		header = append(header, 0x21, 0x00, 36) // TCP over IPv6
+	}
+	header = append(header, src.Addr().AsSlice()...)
+	header = append(header, dst.Addr().AsSlice()...)
+	header = binary.BigEndian.AppendUint16(header, src.Port())
+	return binary.BigEndian.AppendUint16(header, dst.Port())
+}
+
+// addrPort 将 TCP 地址转换为 netip.AddrPort, IPv4 映射的 IPv6 地址按 IPv4 处理, 去掉 IPv6 zone
+func addrPort(addr net.Addr) (netip.AddrPort, bool) {
+	if addr == nil {
+		return netip.AddrPort{}, false
+	}
+	var ap netip.AddrPort
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		ap = tcp.AddrPort()
+	} else {
+		var err error
+		if ap, err = netip.ParseAddrPort(addr.String()); err != nil {
+			return netip.AddrPort{}, false
+		}
+	}
+	if !ap.Addr().IsValid() {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(ap.Addr().Unmap().WithZone(""), ap.Port()), true
+}
@@ -0,0 +1,94 @@
+package httpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestProxyProtocolHeader(t *testing.T) {
+	src4 := &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 51000}
+	dst4 := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 443}
+	src6 := &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 51000, Zone: "eth0"}
+	dst6 := &net.TCPAddr{IP: net.ParseIP("2001:db8::2"), Port: 80}
+
+	tests := []struct {
+		name      string
+		version   ProxyProtocolVersion
+		src, dst  net.Addr
+		want      string
+		wantBytes []byte
+	}{
+		{name: "v1 tcp4", version: ProxyProtocolV1, src: src4, dst: dst4, want: "PROXY TCP4 203.0.113.7 10.0.0.1 51000 443\r\n"},
+		{name: "v1 tcp6", version: ProxyProtocolV1, src: src6, dst: dst6, want: "PROXY TCP6 2001:db8::1 2001:db8::2 51000 80\r\n"},
+		{name: "v1 mixed", version: ProxyProtocolV1, src: src4, dst: dst6, want: "PROXY UNKNOWN\r\n"},
+		{name: "v1 unix", version: ProxyProtocolV1, src: &net.UnixAddr{Name: "/tmp/s", Net: "unix"}, dst: dst4, want: "PROXY UNKNOWN\r\n"},
+		{
+			name: "v2 tcp4", version: ProxyProtocolV2, src: src4, dst: dst4,
+			wantBytes: append([]byte("\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c"), 203, 0, 113, 7, 10, 0, 0, 1, 0xc7, 0x38, 0x01, 0xbb),
+		},
+		{name: "v2 mixed", version: ProxyProtocolV2, src: src6, dst: dst4, wantBytes: []byte("\r\n\r\n\x00\r\nQUIT\n\x21\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := (&proxyProtocol{version: tt.version}).header(tt.src, tt.dst)
+			want := tt.wantBytes
+			if want == nil {
+				want = []byte(tt.want)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("header = %q, want %q", got, want)
+			}
+		})
+	}
+
+	v6 := (&proxyProtocol{version: ProxyProtocolV2}).header(src6, dst6)
+	if len(v6) != 16+36 || v6[13] != 0x21 || binary.BigEndian.Uint16(v6[14:16]) != 36 {
+		t.Fatalf("v2 tcp6 header = %x, want 52 bytes with family 0x21 and length 36", v6)
+	}
+}
+
+func TestWithProxyProtocol(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	headers := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		br := bufio.NewReader(conn)
+		line, _ := br.ReadString('\n')
+		headers <- line
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+		io.Copy(io.Discard, req.Body)
+		io.WriteString(conn, "HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+	}()
+
+	source := &net.TCPAddr{IP: net.ParseIP("198.51.100.9"), Port: 4000}
+	client := New(WithProxyProtocol(ProxyProtocolV1, source))
+	body, err := client.GET("http://" + ln.Addr().String() + "/").Text()
+	if err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+	if body != "ok" {
+		t.Fatalf("body = %q, want %q", body, "ok")
+	}
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	want := "PROXY TCP4 198.51.100.9 127.0.0.1 4000 " + port + "\r\n"
+	if got := <-headers; got != want {
+		t.Fatalf("header = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package httpc
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// QueryArrayStyle 多值参数与 map 参数的编码风格
+type QueryArrayStyle int
+
+const (
+	QueryArrayRepeat  QueryArrayStyle = iota // a=1&a=2; map 展开为 k1=v1&k2=v2 (默认)
+	QueryArrayComma                          // a=1,2; map 编码为 m=k1,v1,k2,v2
+	QueryArrayPipe                           // a=1|2; map 编码为 m=k1|v1|k2|v2
+	QueryArrayBracket                        // a[]=1&a[]=2; map 编码为 m[k1]=v1&m[k2]=v2
+)
+
+// queryMapParam 是 SetQueryMap 设置的 map 参数
+type queryMapParam struct {
+	key    string
+	values map[string]string
+}
+
+// WithQueryArrayStyle 设置客户端默认的多值参数编码风格
+func WithQueryArrayStyle(style QueryArrayStyle) Option {
+	return func(c *Client) {
+		c.queryArrayStyle = style
+	}
+}
+
+// SetQueryArrayStyle 设置本次请求的多值参数与 map 参数编码风格
+func (rb *RequestBuilder) SetQueryArrayStyle(style QueryArrayStyle) *RequestBuilder {
+	rb.queryArrayStyle = style
+	return rb
+}
+
+// SetQueryValues 设置多值参数, 按编码风格输出; QueryArrayBracket 风格下即使只有一个值也输出 key[]
+func (rb *RequestBuilder) SetQueryValues(key string, values ...string) *RequestBuilder {
+	rb.query[key] = append([]string(nil), values...)
+	if rb.queryArrays == nil {
+		rb.queryArrays = make(map[string]bool)
+	}
+	rb.queryArrays[key] = true
+	return rb
+}
+
+// SetQueryMap 设置 map 参数, 按编码风格输出, 子键按字典序排列
+func (rb *RequestBuilder) SetQueryMap(key string, values map[string]string) *RequestBuilder {
+	rb.queryMaps = slices.DeleteFunc(rb.queryMaps, func(p queryMapParam) bool { return p.key == key })
+	rb.queryMaps = append(rb.queryMaps, queryMapParam{key: key, values: values})
+	return rb
+}
+
+// encodeQuery 按编码风格编码 Query 参数, 参数按键排序, map 参数追加在后
+func (rb *RequestBuilder) encodeQuery() string {
+	var sb strings.Builder
+	write := func(key, value string) {
+		if sb.Len() > 0 {
+			sb.WriteByte('&')
+		}
+		sb.WriteString(key)
+		sb.WriteByte('=')
+		sb.WriteString(value)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(rb.query)) {
+		rb.queryArrayStyle.encode(key, rb.query[key], rb.queryArrays[key], write)
+	}
+	for _, param := range rb.queryMaps {
+		subKeys := slices.Sorted(maps.Keys(param.values))
+		switch rb.queryArrayStyle {
+		case QueryArrayComma, QueryArrayPipe:
+			pairs := make([]string, 0, 2*len(subKeys))
+			for _, k := range subKeys {
+				pairs = append(pairs, k, param.values[k])
+			}
+			rb.queryArrayStyle.encode(param.key, pairs, false, write)
+		case QueryArrayBracket:
+			for _, k := range subKeys {
+				write(url.QueryEscape(param.key)+"["+url.QueryEscape(k)+"]", url.QueryEscape(param.values[k]))
+			}
+		default:
+			for _, k := range subKeys {
+				write(url.QueryEscape(k), url.QueryEscape(param.values[k]))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// encode 按风格输出单个多值参数, 分隔符不做转义; array 表示参数由 SetQueryValues 设置
+func (s QueryArrayStyle) encode(key string, values []string, array bool, write func(key, value string)) {
+	escapedKey := url.QueryEscape(key)
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = url.QueryEscape(v)
+	}
+
+	switch {
+	case s == QueryArrayComma && len(values) > 0:
+		write(escapedKey, strings.Join(escaped, ","))
+	case s == QueryArrayPipe && len(values) > 0:
+		write(escapedKey, strings.Join(escaped, "|"))
+	case s == QueryArrayBracket && (array || len(values) > 1):
+		for _, v := range escaped {
+			write(escapedKey+"[]", v)
+		}
+	default:
+		for _, v := range escaped {
+			write(escapedKey, v)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package httpc
+
+import "testing"
+
+func TestQueryArrayStyles(t *testing.T) {
+	tests := []struct {
+		style QueryArrayStyle
+		want  string
+	}{
+		{QueryArrayRepeat, "ids=1&ids=a+b&one=x&k=1&s=2"},
+		{QueryArrayComma, "ids=1,a+b&one=x&m=k,1,s,2"},
+		{QueryArrayPipe, "ids=1|a+b&one=x&m=k|1|s|2"},
+		{QueryArrayBracket, "ids[]=1&ids[]=a+b&one=x&m[k]=1&m[s]=2"},
+	}
+
+	client := New()
+	for _, tt := range tests {
+		req, err := client.GET("https://example.com/list").
+			SetQueryArrayStyle(tt.style).
+			SetQueryValues("ids", "1", "a b").
+			SetQueryParam("one", "x").
+			SetQueryMap("m", map[string]string{"s": "2", "k": "1"}).
+			Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if req.URL.RawQuery != tt.want {
+			t.Fatalf("style %d: RawQuery = %q, want %q", tt.style, req.URL.RawQuery, tt.want)
+		}
+	}
+}
+
+func TestQueryArrayStyleClientDefault(t *testing.T) {
+	client := New(WithQueryArrayStyle(QueryArrayComma))
+
+	req, err := client.GET("https://example.com/list?keep=a,b").
+		AddQueryParam("tag", "go").
+		AddQueryParam("tag", "x,y").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if want := "keep=a,b&tag=go,x%2Cy"; req.URL.RawQuery != want {
+		t.Fatalf("RawQuery = %q, want %q", req.URL.RawQuery, want)
+	}
+
+	req, err = client.GET("https://example.com/list").
+		SetQueryArrayStyle(QueryArrayRepeat).
+		SetQueryValues("tag", "go", "rust").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if want := "tag=go&tag=rust"; req.URL.RawQuery != want {
+		t.Fatalf("RawQuery = %q, want %q", req.URL.RawQuery, want)
+	}
+}
+
+func TestQueryArrayBracketSingleValue(t *testing.T) {
+	req, err := New().GET("https://example.com/list").
+		SetQueryArrayStyle(QueryArrayBracket).
+		SetQueryValues("ids", "1").
+		SetQueryParam("one", "x").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if want := "ids[]=1&one=x"; req.URL.RawQuery != want {
+		t.Fatalf("RawQuery = %q, want %q", req.URL.RawQuery, want)
+	}
+}
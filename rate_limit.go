@@ -0,0 +1,149 @@
+package httpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit 请求速率限制配置 (令牌桶)
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second" toml:"requests_per_second"` // 每秒允许的请求数, <=0 表示不限制
+	Burst             int     `json:"burst" toml:"burst"`                             // 允许的突发请求数, <=0 时为 1
+	PerHost           bool    `json:"per_host" toml:"per_host"`                       // 为 true 时每个主机独立计数, 主机数超过 1024 时清理已补满的令牌桶
+}
+
+// WithRateLimit 限制客户端发出请求的速率, 每次重试同样消耗令牌
+func WithRateLimit(limit RateLimit) Option {
+	return func(c *Client) {
+		c.updateLive(func(l *liveConfig) {
+			l.rateLimiter = newRateLimiter(limit)
+		})
+	}
+}
+
+// tokenBucket 是一个简单的令牌桶, 令牌可以透支为负数以实现排队等待
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve 取走一个令牌, 返回需要等待的时间
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// full 判断令牌桶在 now 时是否已补满, 补满的令牌桶与新建的等价
+func (b *tokenBucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.last.IsZero() || b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
+
+// cancel 归还一个未使用的令牌
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens = min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// rateLimiter 管理全局或按主机划分的令牌桶
+type rateLimiter struct {
+	limit  RateLimit
+	global *tokenBucket
+
+	mu    sync.Mutex
+	hosts map[string]*tokenBucket
+}
+
+// newRateLimiter 创建限速器, 未启用限速时返回 nil
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	l := &rateLimiter{limit: limit}
+	if limit.PerHost {
+		l.hosts = make(map[string]*tokenBucket)
+	} else {
+		l.global = newTokenBucket(limit.RequestsPerSecond, limit.Burst)
+	}
+	return l
+}
+
+// maxRateLimitHosts 是按主机限速时保留的令牌桶数量, 超过时清理已补满的令牌桶
+const maxRateLimitHosts = 1024
+
+func (l *rateLimiter) bucket(now time.Time, host string) *tokenBucket {
+	if l.global != nil {
+		return l.global
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.hosts[host]
+	if !ok {
+		if len(l.hosts) >= maxRateLimitHosts {
+			for h, bucket := range l.hosts {
+				if bucket.full(now) {
+					delete(l.hosts, h)
+				}
+			}
+		}
+		b = newTokenBucket(l.limit.RequestsPerSecond, l.limit.Burst)
+		l.hosts[host] = b
+	}
+	return b
+}
+
+// wait 阻塞直到获得令牌或 ctx 结束
+func (l *rateLimiter) wait(ctx context.Context, clock Clock, host string) error {
+	return l.bucket(clock.Now(), host).wait(ctx, clock)
+}
+
+// wait 阻塞直到获得令牌或 ctx 结束, ctx 结束时归还令牌
+func (b *tokenBucket) wait(ctx context.Context, clock Clock) error {
+	delay := b.reserve(clock.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	case <-clock.After(delay):
+		return nil
+	}
+}
+
+// rateLimitRoundTripper 是一个内部中间件, 在每次发送请求前等待限速令牌
+func (c *Client) rateLimitRoundTripper(limiter *rateLimiter, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := limiter.wait(req.Context(), c.clock, req.URL.Host); err != nil {
+			return nil, fmt.Errorf("httpc: rate limit wait: %w", err)
+		}
+		return next.RoundTrip(req)
+	})
+}
@@ -0,0 +1,83 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := newTokenBucket(10, 2)
+	now := time.Now()
+
+	for i := range 2 {
+		if d := b.reserve(now); d != 0 {
+			t.Fatalf("reserve %d within burst: wait = %v, want 0", i, d)
+		}
+	}
+	if d := b.reserve(now); d != 100*time.Millisecond {
+		t.Fatalf("reserve beyond burst: wait = %v, want %v", d, 100*time.Millisecond)
+	}
+	if d := b.reserve(now.Add(100 * time.Millisecond)); d != 100*time.Millisecond {
+		t.Fatalf("reserve after refill: wait = %v, want %v", d, 100*time.Millisecond)
+	}
+}
+
+func TestWithRateLimitPerHost(t *testing.T) {
+	client := New(WithRateLimit(RateLimit{RequestsPerSecond: 1, Burst: 1, PerHost: true}))
+	limiter := client.settings().rateLimiter
+
+	now := time.Now()
+
+	if limiter.bucket(now, "a.example.com") == limiter.bucket(now, "b.example.com") {
+		t.Fatal("per-host limiter shares a bucket between hosts")
+	}
+	if limiter.bucket(now, "a.example.com") != limiter.bucket(now, "a.example.com") {
+		t.Fatal("per-host limiter returned different buckets for the same host")
+	}
+}
+
+func TestRateLimitPerHostPrunesFullBuckets(t *testing.T) {
+	limiter := newRateLimiter(RateLimit{RequestsPerSecond: 1, Burst: 1, PerHost: true})
+	now := time.Now()
+
+	limiter.bucket(now, "busy").reserve(now)
+	for i := range maxRateLimitHosts - 1 {
+		limiter.bucket(now, strconv.Itoa(i))
+	}
+	limiter.bucket(now, "new")
+	if len(limiter.hosts) != 2 {
+		t.Fatalf("len(hosts) = %d, want 2 (the drained bucket and the new one)", len(limiter.hosts))
+	}
+	if _, ok := limiter.hosts["busy"]; !ok {
+		t.Fatal("drained bucket was pruned")
+	}
+}
+
+func TestRateLimitWaitHonorsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := New(
+		WithRateLimit(RateLimit{RequestsPerSecond: 0.1, Burst: 1}),
+		WithRetryOptions(RetryOptions{}),
+	)
+	if _, err := client.GET(server.URL).Text(); err != nil {
+		t.Fatalf("first request error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GET(server.URL).WithContext(ctx).Text()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second request error = %v, want context deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("second request waited %v, want it to stop at the context deadline", elapsed)
+	}
+}
@@ -10,19 +10,19 @@ import (
 	"maps"
 	"net/http"
 	"net/url"
-
-	"github.com/go-json-experiment/json"
+	"strings"
 )
 
 // NewRequestBuilder 创建 RequestBuilder 实例
 func (c *Client) NewRequestBuilder(method, urlStr string) *RequestBuilder {
 	return &RequestBuilder{
-		client:  c,
-		method:  method,
-		url:     urlStr,
-		header:  make(http.Header),
-		query:   make(url.Values),
-		context: context.Background(), // 默认使用 Background Context
+		client:          c,
+		method:          method,
+		url:             urlStr,
+		header:          make(http.Header),
+		query:           make(url.Values),
+		queryArrayStyle: c.queryArrayStyle,
+		context:         context.Background(), // 默认使用 Background Context
 	}
 }
 
@@ -90,6 +90,7 @@ func (rb *RequestBuilder) SetHeaders(headers map[string]string) *RequestBuilder
 // SetQueryParam 设置 Query 参数
 func (rb *RequestBuilder) SetQueryParam(key, value string) *RequestBuilder {
 	rb.query.Set(key, value)
+	delete(rb.queryArrays, key)
 	return rb
 }
 
@@ -103,26 +104,60 @@ func (rb *RequestBuilder) AddQueryParam(key, value string) *RequestBuilder {
 func (rb *RequestBuilder) SetQueryParams(params map[string]string) *RequestBuilder {
 	for key, value := range params {
 		rb.query.Set(key, value)
+		delete(rb.queryArrays, key)
 	}
 	return rb
 }
 
+// SetRawQuery 原样设置查询串 (不含 '?'), 替换 URL 中已有的查询串且不做重新编码或排序
+// 适用于签名敏感的 API 与预签名 URL; SetQueryParam 等添加的参数会编码后追加在其后
+func (rb *RequestBuilder) SetRawQuery(rawQuery string) *RequestBuilder {
+	rb.rawQuery = &rawQuery
+	return rb
+}
+
+// AddRawQueryParam 原样追加 "key=value" 查询参数, key 与 value 需由调用方自行编码
+func (rb *RequestBuilder) AddRawQueryParam(key, value string) *RequestBuilder {
+	rb.rawQueryParams = append(rb.rawQueryParams, key+"="+value)
+	return rb
+}
+
 // SetBody 设置 Body (io.Reader)
 func (rb *RequestBuilder) SetBody(body io.Reader) *RequestBuilder {
-	rb.body = body
+	rb.setBody(body)
 	return rb
 }
 
+// setBody 替换请求体, 并清除上一个请求体遗留的长度与重放信息
+func (rb *RequestBuilder) setBody(body io.Reader) {
+	rb.body = body
+	rb.contentLength = 0
+	rb.getBody = nil
+	rb.openBody = nil
+}
+
 // SetRawBody 设置 Body ([]byte)
 func (rb *RequestBuilder) SetRawBody(body []byte) *RequestBuilder {
-	rb.body = bytes.NewReader(body)
+	rb.setBody(bytes.NewReader(body))
 	return rb
 }
 
 // SetJSONBody 设置 JSON Body
+// 默认实现通过管道边编码边发送, 编码错误在发送时返回; 不支持流式编码的 JSONEngine 会立即编码
 func (rb *RequestBuilder) SetJSONBody(body any) (*RequestBuilder, error) {
+	engine, ok := rb.client.jsonEngine.(jsonStreamEngine)
+	if !ok {
+		data, err := rb.client.jsonEngine.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode json body error: %w", err)
+		}
+		rb.setBody(bytes.NewReader(data))
+		rb.header.Set("Content-Type", "application/json")
+		return rb, nil
+	}
+
 	pr, pw := io.Pipe()
-	rb.body = pr
+	rb.setBody(pr)
 	rb.header.Set("Content-Type", "application/json")
 
 	go func() {
@@ -131,7 +166,7 @@ func (rb *RequestBuilder) SetJSONBody(body any) (*RequestBuilder, error) {
 			pw.CloseWithError(err)
 		}()
 
-		err = json.MarshalWrite(pw, body)
+		err = engine.MarshalWrite(pw, body)
 	}()
 	return rb, nil
 }
@@ -144,7 +179,7 @@ func (rb *RequestBuilder) SetXMLBody(body any) (*RequestBuilder, error) {
 	if err := xml.NewEncoder(buf).Encode(body); err != nil {
 		return nil, fmt.Errorf("encode xml body error: %w", err)
 	}
-	rb.body = bytes.NewReader(buf.Bytes())
+	rb.setBody(bytes.NewReader(buf.Bytes()))
 	rb.header.Set("Content-Type", "application/xml")
 	return rb, nil
 }
@@ -158,43 +193,115 @@ func (rb *RequestBuilder) SetGOBBody(body any) (*RequestBuilder, error) {
 	if err := gob.NewEncoder(buf).Encode(body); err != nil {
 		return nil, fmt.Errorf("encode gob body error: %w", err)
 	}
-	rb.body = bytes.NewReader(buf.Bytes())
+	rb.setBody(bytes.NewReader(buf.Bytes()))
 	rb.header.Set("Content-Type", "application/octet-stream") // 设置合适的 Content-Type
 	return rb, nil
 }
 
 // Build 构建 http.Request
 func (rb *RequestBuilder) Build() (*http.Request, error) {
+	return rb.build(rb.context)
+}
 
+// build 以 ctx 为基础构建 http.Request, 不修改 RequestBuilder 保存的 Context
+func (rb *RequestBuilder) build(ctx context.Context) (*http.Request, error) {
 	reqURL, err := url.Parse(rb.url)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s, error: %v", ErrInvalidURL, rb.url, err)
 	}
-	if len(rb.query) > 0 {
-		q := reqURL.Query()
-		for k, v := range rb.query {
-			for _, val := range v {
-				q.Add(k, val)
-			}
+	reqURL.RawQuery = rb.buildRawQuery(reqURL.RawQuery)
+	if rb.connectOverride != (connectOverride{}) {
+		ctx = context.WithValue(ctx, connectOverrideKey{}, rb.connectOverride)
+	}
+	if rb.timeouts != (requestTimeouts{}) {
+		ctx = context.WithValue(ctx, requestTimeoutsKey{}, rb.timeouts)
+	}
+	var prof *profile
+	if rb.profile != "" {
+		if prof, err = rb.client.lookupProfile(rb.profile); err != nil {
+			return nil, err
 		}
-		reqURL.RawQuery = q.Encode()
+		ctx = context.WithValue(ctx, profileKey{}, prof)
 	}
-	req, err := http.NewRequestWithContext(rb.context, rb.method, reqURL.String(), rb.body)
+	body := rb.body
+	var opened io.Closer
+	open := rb.getBody
+	if open == nil {
+		open = rb.openBody
+	}
+	if body == nil && open != nil {
+		// 延迟打开的请求体 (SetFileBody, SetMultipartBody), 在构建请求时才打开
+		rc, err := open()
+		if err != nil {
+			return nil, fmt.Errorf("open request body error: %w", err)
+		}
+		body, opened = rc, rc
+	}
+	req, err := http.NewRequestWithContext(ctx, rb.method, reqURL.String(), body)
 	if err != nil {
+		if opened != nil {
+			opened.Close()
+		}
 		return nil, err
 	}
+	if rb.host != "" {
+		req.Host = rb.host
+	}
+	if rb.contentLength > 0 {
+		req.ContentLength = rb.contentLength
+	}
+	if rb.getBody != nil {
+		req.GetBody = rb.getBody
+	}
 	maps.Copy(req.Header, rb.header)
+	if prof != nil {
+		prof.applyHeaders(req.Header)
+	}
 	if !rb.noDefaultHeaders && req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", rb.client.userAgent)
 	}
 	return req, nil
 }
 
+// buildRawQuery 拼接最终的查询串: URL 自带 (或 SetRawQuery 设置) 的查询串保持原样,
+// 其后依次追加编码后的 Query 参数与 AddRawQueryParam 的原样片段
+func (rb *RequestBuilder) buildRawQuery(base string) string {
+	if rb.rawQuery != nil {
+		base = *rb.rawQuery
+	}
+	if len(rb.query) == 0 && len(rb.queryMaps) == 0 && len(rb.rawQueryParams) == 0 {
+		return base
+	}
+
+	parts := make([]string, 0, 2+len(rb.rawQueryParams))
+	if base != "" {
+		parts = append(parts, base)
+	}
+	if len(rb.query) > 0 || len(rb.queryMaps) > 0 {
+		parts = append(parts, rb.encodeQuery())
+	}
+	parts = append(parts, rb.rawQueryParams...)
+	return strings.Join(parts, "&")
+}
+
 // Execute 执行请求并返回 http.Response
 func (rb *RequestBuilder) Execute() (*http.Response, error) {
-	req, err := rb.Build()
+	return rb.execute(rb.context)
+}
+
+// execute 以 ctx 为基础构建并执行请求
+func (rb *RequestBuilder) execute(ctx context.Context) (*http.Response, error) {
+	req, err := rb.build(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return rb.client.Do(req)
+	resp, err := rb.client.Do(req)
+	if err != nil || rb.schema == nil {
+		return resp, err
+	}
+	if err := rb.client.validateResponse(rb.schema, resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
 }
@@ -0,0 +1,79 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// RequestOption 是通过 Context 携带的单次请求选项, 由 ContextWithOptions 设置.
+// 只能拿到 ctx 的代码 (例如通过 StandardClient 使用客户端的第三方库) 也可以借此调整单个请求
+type RequestOption func(*requestOptions)
+
+// requestOptionsKey 是请求选项在 Context 中的键
+type requestOptionsKey struct{}
+
+// requestOptions 是 Context 携带的请求选项
+type requestOptions struct {
+	noRetry  bool
+	priority int // RFC 9218 紧急度加 1, 0 表示未设置
+	label    string
+}
+
+// NoRetry 使本次请求不重试, 不影响客户端的其他请求
+func NoRetry() RequestOption {
+	return func(o *requestOptions) {
+		o.noRetry = true
+	}
+}
+
+// Priority 设置 RFC 9218 紧急度 (0 最高, 7 最低, 超出范围时截断), 以 Priority 请求头发送;
+// 请求已设置 Priority 头时不覆盖
+func Priority(urgency int) RequestOption {
+	return func(o *requestOptions) {
+		o.priority = min(max(urgency, 0), 7) + 1
+	}
+}
+
+// Label 为本次请求设置指标标签, 计数同时计入 LabelMetrics 中该标签下
+func Label(label string) RequestOption {
+	return func(o *requestOptions) {
+		o.label = label
+	}
+}
+
+// ContextWithOptions 返回携带请求选项的 Context, 经由该 Context 发出的请求在 Client.Do 中应用这些选项.
+// ctx 已携带选项时在其基础上追加
+func ContextWithOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	o, _ := ctx.Value(requestOptionsKey{}).(requestOptions)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, o)
+}
+
+// contextOptions 返回 ctx 携带的请求选项
+func contextOptions(ctx context.Context) (requestOptions, bool) {
+	o, ok := ctx.Value(requestOptionsKey{}).(requestOptions)
+	return o, ok
+}
+
+// apply 返回应用请求选项后的配置快照副本
+func (o requestOptions) apply(settings *liveConfig) *liveConfig {
+	if !o.noRetry {
+		return settings
+	}
+	l := *settings
+	l.retryOpts.MaxAttempts = 0
+	return &l
+}
+
+// applyHeaders 设置请求选项对应的请求头, 需要修改时返回请求的副本
+func (o requestOptions) applyHeaders(req *http.Request) *http.Request {
+	if o.priority == 0 || req.Header.Get("Priority") != "" {
+		return req
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Priority", "u="+strconv.Itoa(o.priority-1))
+	return req
+}
@@ -0,0 +1,96 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContextWithOptionsNoRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, RetryStatuses: []int{http.StatusServiceUnavailable}}))
+	ctx := ContextWithOptions(context.Background(), NoRetry())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.StandardClient().Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("calls = %d, want 1", got)
+	}
+
+	calls.Store(0)
+	_, _ = client.GET(srv.URL).Bytes()
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("calls without NoRetry = %d, want 3", got)
+	}
+}
+
+func TestContextWithOptionsPriority(t *testing.T) {
+	var priority atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		priority.Store(r.Header.Get("Priority"))
+	}))
+	defer srv.Close()
+
+	client := New()
+	ctx := ContextWithOptions(context.Background(), Priority(9))
+	if _, err := client.GET(srv.URL).WithContext(ctx).Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if got := priority.Load(); got != "u=7" {
+		t.Fatalf("Priority = %q, want %q", got, "u=7")
+	}
+
+	if _, err := client.GET(srv.URL).WithContext(ctx).SetHeader("Priority", "u=1, i").Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if got := priority.Load(); got != "u=1, i" {
+		t.Fatalf("Priority = %q, want explicit header kept", got)
+	}
+}
+
+func TestContextWithOptionsLabel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	billing := ContextWithOptions(context.Background(), Label("billing"))
+	// 追加选项保留已有的标签
+	billing = ContextWithOptions(billing, NoRetry())
+	for range 2 {
+		if _, err := client.GET(srv.URL).WithContext(billing).Bytes(); err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+	}
+	if _, err := client.GET("http://" + closedAddr(t)).WithContext(billing).Bytes(); err == nil {
+		t.Fatal("request to closed port succeeded")
+	}
+	if _, err := client.GET(srv.URL).Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	labels := client.LabelMetrics()
+	if len(labels) != 1 {
+		t.Fatalf("labels = %v, want only billing", labels)
+	}
+	if m := labels["billing"]; m.Requests != 3 || m.Errors != 1 {
+		t.Fatalf("billing = %+v, want 3 requests, 1 error", m)
+	}
+	if m := client.Metrics(); m.Requests != 4 {
+		t.Fatalf("Requests = %d, want 4", m.Requests)
+	}
+}
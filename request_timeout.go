@@ -0,0 +1,217 @@
+package httpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// 单个请求的分段超时错误, 均被归类为超时错误 (ErrorClassTimeout)
+var (
+	ErrResponseHeaderTimeout = errors.New("httpc: timeout awaiting response headers")
+	ErrBodyReadTimeout       = errors.New("httpc: timeout reading response body")
+	ErrDialTimeout           = errors.New("httpc: timeout dialing")
+	ErrTLSHandshakeTimeout   = errors.New("httpc: timeout during TLS handshake")
+)
+
+// Timeouts 是单个请求的分段超时, 零值字段沿用客户端配置
+type Timeouts struct {
+	Dial           time.Duration // 建立连接 (含 DNS 解析), 与客户端的拨号超时同时生效
+	TLSHandshake   time.Duration // TLS 握手, 与客户端的握手超时同时生效
+	ResponseHeader time.Duration // 发出请求到收到响应头, 每次尝试单独计时
+	Total          time.Duration // 整个请求 (含重试与读取响应体), 替换客户端超时; 负数表示不超时
+}
+
+// requestTimeoutsKey 是请求级分段超时在 Context 中的键
+type requestTimeoutsKey struct{}
+
+// requestTimeouts 是请求级的分段超时
+type requestTimeouts struct {
+	header       time.Duration // 发出请求到收到响应头
+	bodyRead     time.Duration // 单次读取响应体等待数据的时间
+	dial         time.Duration // 建立连接
+	tlsHandshake time.Duration // TLS 握手
+	total        time.Duration // 整个请求, 替换客户端超时
+}
+
+// WithTimeouts 为本次请求设置分段超时, 不影响同一客户端上的其他请求.
+// 连接可能被复用, 拨号与握手超时只在本次请求新建连接时生效
+func (rb *RequestBuilder) WithTimeouts(t Timeouts) *RequestBuilder {
+	if t.Dial != 0 {
+		rb.timeouts.dial = t.Dial
+	}
+	if t.TLSHandshake != 0 {
+		rb.timeouts.tlsHandshake = t.TLSHandshake
+	}
+	if t.ResponseHeader != 0 {
+		rb.timeouts.header = t.ResponseHeader
+	}
+	if t.Total != 0 {
+		rb.timeouts.total = t.Total
+	}
+	return rb
+}
+
+// apply 返回以请求级总超时替换客户端超时后的配置快照副本
+func (t requestTimeouts) apply(settings *liveConfig) *liveConfig {
+	if t.total == 0 {
+		return settings
+	}
+	l := *settings
+	l.timeout = max(t.total, 0)
+	return &l
+}
+
+// withRequestDialTimeout 为拨号施加发起拨号的请求设置的拨号超时
+func withRequestDialTimeout(dial dialFunc) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		timeouts, _ := ctx.Value(requestTimeoutsKey{}).(requestTimeouts)
+		if timeouts.dial <= 0 {
+			return dial(ctx, network, addr)
+		}
+		ctx, cancel := context.WithTimeoutCause(ctx, timeouts.dial, ErrDialTimeout)
+		defer cancel()
+		conn, err := dial(ctx, network, addr)
+		if err != nil && errors.Is(context.Cause(ctx), ErrDialTimeout) {
+			err = fmt.Errorf("%w: %v", ErrDialTimeout, err)
+		}
+		return conn, err
+	}
+}
+
+// phaseTimer 在 httptrace 报告的阶段开始时计时, 阶段结束或请求返回时停止
+type phaseTimer struct {
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+func (p *phaseTimer) start(d time.Duration, fire func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.stopped && p.timer == nil {
+		p.timer = time.AfterFunc(d, fire)
+	}
+}
+
+func (p *phaseTimer) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stopped = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// WithResponseHeaderTimeout 设置本次请求等待响应头的超时 (每次尝试单独计时),
+// 不限制之后读取响应体的时间
+func (rb *RequestBuilder) WithResponseHeaderTimeout(d time.Duration) *RequestBuilder {
+	rb.timeouts.header = d
+	return rb
+}
+
+// WithBodyReadTimeout 设置读取响应体时等待数据的超时: 每次 Read 都会重新计时,
+// 只要数据持续到达, 流式响应可以持续任意长时间
+func (rb *RequestBuilder) WithBodyReadTimeout(d time.Duration) *RequestBuilder {
+	rb.timeouts.bodyRead = d
+	return rb
+}
+
+// requestTimeoutRoundTripper 是一个内部中间件, 位于重试之内, 执行请求级的分段超时
+func (c *Client) requestTimeoutRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		timeouts, ok := req.Context().Value(requestTimeoutsKey{}).(requestTimeouts)
+		if !ok {
+			return next.RoundTrip(req)
+		}
+
+		ctx, cancel := context.WithCancelCause(req.Context())
+		var headerTimer *time.Timer
+		if timeouts.header > 0 {
+			headerTimer = time.AfterFunc(timeouts.header, func() { cancel(ErrResponseHeaderTimeout) })
+		}
+		var handshake phaseTimer
+		if timeouts.tlsHandshake > 0 {
+			ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+				TLSHandshakeStart: func() {
+					handshake.start(timeouts.tlsHandshake, func() { cancel(ErrTLSHandshakeTimeout) })
+				},
+				TLSHandshakeDone: func(tls.ConnectionState, error) { handshake.stop() },
+			})
+		}
+
+		resp, err := next.RoundTrip(req.WithContext(ctx))
+		if headerTimer != nil {
+			headerTimer.Stop()
+		}
+		handshake.stop()
+		if resp != nil {
+			resp.Request = req
+		}
+		if err != nil {
+			if cause := context.Cause(ctx); errors.Is(cause, ErrResponseHeaderTimeout) || errors.Is(cause, ErrTLSHandshakeTimeout) {
+				err = fmt.Errorf("%w: %v", cause, err)
+			}
+			cancel(nil)
+			return resp, err
+		}
+		// 101 协议升级的响应体是可写的连接, 不做包装
+		if resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			cancel(nil)
+			return resp, nil
+		}
+
+		if timeouts.bodyRead <= 0 {
+			resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: func() { cancel(nil) }}
+			return resp, nil
+		}
+		body := &readTimeoutBody{ReadCloser: resp.Body, ctx: ctx, cancel: cancel, timeout: timeouts.bodyRead}
+		body.timer = time.AfterFunc(time.Hour, func() { cancel(ErrBodyReadTimeout) })
+		body.timer.Stop()
+		resp.Body = body
+		return resp, nil
+	})
+}
+
+// readTimeoutBody 在每次 Read 期间计时, 超时后取消请求 Context 并返回 ErrBodyReadTimeout
+type readTimeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+
+	mu    sync.Mutex // 串行化计时器的重置与停止
+	timer *time.Timer
+}
+
+func (b *readTimeoutBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	b.timer.Reset(b.timeout)
+	b.mu.Unlock()
+
+	n, err := b.ReadCloser.Read(p)
+
+	b.mu.Lock()
+	b.timer.Stop()
+	b.mu.Unlock()
+	if err != nil && errors.Is(context.Cause(b.ctx), ErrBodyReadTimeout) {
+		err = ErrBodyReadTimeout
+	}
+	return n, err
+}
+
+func (b *readTimeoutBody) Close() error {
+	b.mu.Lock()
+	b.timer.Stop()
+	b.mu.Unlock()
+	err := b.ReadCloser.Close()
+	b.cancel(nil)
+	return err
+}
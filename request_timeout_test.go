@@ -0,0 +1,155 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	start := time.Now()
+	_, err := client.GET(srv.URL).WithResponseHeaderTimeout(50 * time.Millisecond).Execute()
+	if !errors.Is(err, ErrResponseHeaderTimeout) {
+		t.Fatalf("error = %v, want %v", err, ErrResponseHeaderTimeout)
+	}
+	if Classify(err) != ErrorClassTimeout {
+		t.Fatalf("Classify() = %v, want %v", Classify(err), ErrorClassTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("elapsed = %v, want about 50ms", elapsed)
+	}
+}
+
+func TestRequestBodyReadTimeoutAllowsSlowStream(t *testing.T) {
+	stall := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		// 每 20ms 发送一块, 总时长超过单次读取超时
+		for range 5 {
+			_, _ = w.Write([]byte("chunk"))
+			flusher.Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		if r.URL.Query().Get("stall") != "" {
+			select {
+			case <-stall:
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer srv.Close()
+	defer close(stall)
+
+	client := New()
+	body, err := client.GET(srv.URL).
+		WithResponseHeaderTimeout(time.Second).
+		WithBodyReadTimeout(60 * time.Millisecond).
+		Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if len(body) != 25 {
+		t.Fatalf("len(body) = %d, want 25", len(body))
+	}
+
+	resp, err := client.GET(srv.URL + "?stall=1").WithBodyReadTimeout(60 * time.Millisecond).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, ErrBodyReadTimeout) {
+		t.Fatalf("ReadAll() error = %v, want %v", err, ErrBodyReadTimeout)
+	}
+}
+
+func TestWithTimeoutsDial(t *testing.T) {
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+		WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+	)
+	start := time.Now()
+	_, err := client.GET("http://example.invalid/").WithTimeouts(Timeouts{Dial: 50 * time.Millisecond}).Execute()
+	if !errors.Is(err, ErrDialTimeout) {
+		t.Fatalf("error = %v, want %v", err, ErrDialTimeout)
+	}
+	if Classify(err) != ErrorClassTimeout {
+		t.Fatalf("Classify() = %v, want %v", Classify(err), ErrorClassTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("elapsed = %v, want about 50ms", elapsed)
+	}
+}
+
+func TestWithTimeoutsTLSHandshake(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		// 接受连接但从不响应 TLS 握手
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	start := time.Now()
+	_, err = client.GET("https://" + ln.Addr().String()).WithTimeouts(Timeouts{TLSHandshake: 50 * time.Millisecond}).Execute()
+	if !errors.Is(err, ErrTLSHandshakeTimeout) {
+		t.Fatalf("error = %v, want %v", err, ErrTLSHandshakeTimeout)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("elapsed = %v, want about 50ms", elapsed)
+	}
+}
+
+func TestWithTimeoutsTotal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(150 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	client := New(WithTimeout(50*time.Millisecond), WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	if _, err := client.GET(srv.URL).Bytes(); err == nil {
+		t.Fatal("client timeout: error = nil, want timeout")
+	}
+	if _, err := client.GET(srv.URL).WithTimeouts(Timeouts{Total: time.Second}).Bytes(); err != nil {
+		t.Fatalf("Total = 1s: error = %v", err)
+	}
+	if _, err := client.GET(srv.URL).WithTimeouts(Timeouts{Total: -1}).Bytes(); err != nil {
+		t.Fatalf("Total = -1: error = %v", err)
+	}
+
+	client = New(WithRetryOptions(RetryOptions{MaxAttempts: 0}))
+	_, err := client.GET(srv.URL).WithTimeouts(Timeouts{Total: 50 * time.Millisecond}).Bytes()
+	if !errors.Is(err, ErrRequestTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Total = 50ms: error = %v, want timeout", err)
+	}
+}
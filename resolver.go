@@ -2,70 +2,113 @@ package httpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"time"
 )
 
-// customDialer 包装了标准的 net.Dialer, 以实现一个支持轮询和回退的自定义DNS解析流程
-// 它将被用于替换 http.Transport 中默认的 DialContext 方法
+// Resolver 将主机名解析为 IP 地址列表, 可用于接入服务注册中心, 一致性哈希解析或测试用的固定解析
+type Resolver interface {
+	LookupIP(ctx context.Context, host string) ([]net.IP, error)
+}
+
+// ResolverFunc 是一个适配器, 允许使用普通函数作为 Resolver
+type ResolverFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+// LookupIP 实现了 Resolver 接口
+func (f ResolverFunc) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	return f(ctx, host)
+}
+
+// WithResolver 设置自定义解析器, 解析出的地址依次尝试 (见多 IP 故障转移).
+// 解析失败时请求失败, 不回退到系统 DNS; nil 恢复默认解析
+func WithResolver(resolver Resolver) Option {
+	return func(c *Client) {
+		if resolver == nil {
+			c.resolver = nil
+			return
+		}
+		c.resolver = &customDialer{
+			resolver: resolver,
+			dial:     c.baseDial,
+			timeout:  c.dialTimeout,
+			now:      time.Now,
+		}
+	}
+}
+
+// customDialer 包装了基础拨号函数, 以实现一个支持轮询和回退的自定义DNS解析流程
+// 它位于基础拨号与代理拨号之间, 见 dial.go
 type customDialer struct {
-	defaultDialer *net.Dialer   // 用于建立TCP/UDP连接, 并在自定义DNS失败时作为回退选项
-	dnsServers    []string      // 自定义DNS服务器地址列表 (格式 "ip:port")
-	dnsTimeout    time.Duration // 单次DNS查询的超时时间
+	resolver Resolver             // 解析器
+	fallback bool                 // 解析失败时是否回退到基础拨号, 由系统处理DNS解析
+	dial     dialFunc             // 用于建立TCP/UDP连接, 并在回退时使用
+	timeout  func() time.Duration // 整体拨号超时, 用于在多个IP之间分配时间
+	now      func() time.Time     // 时间源, 用于计算每个IP的拨号截止时间; 截止时间交给 context 按真实时间比较, 不使用 Client 的 Clock
 }
 
 // DialContext 是实现核心逻辑的地方它拦截了所有的拨号请求
-// 流程: 尝试用自定义DNS解析 -> 如果成功, 则连接到解析出的IP -> 如果失败, 则回退到默认拨号器处理
+// 流程: 尝试用自定义解析器解析 -> 如果成功, 则连接到解析出的IP -> 如果失败, 则回退到默认拨号器处理 (fallback 为 true 时)
 func (d *customDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
 	// 1. 从地址中分离出 host 和 port (例如, 从 "example.com:443" 中提取 "example.com")
 	host, port, err := net.SplitHostPort(address)
 	if err != nil {
 		// 如果分离失败 (例如, 地址格式不标准), 直接回退到默认拨号器, 保证兼容性
-		return d.defaultDialer.DialContext(ctx, network, address)
+		return d.dial(ctx, network, address)
+	}
+
+	// IP 地址无需解析
+	if net.ParseIP(host) != nil {
+		return d.dial(ctx, network, address)
 	}
 
-	// 2. 尝试使用自定义DNS服务器列表来解析域名
-	ips, resolveErr := d.resolveWithCustomDNS(ctx, host)
+	// 2. 尝试使用自定义解析器解析域名
+	ips, resolveErr := d.resolver.LookupIP(ctx, host)
 
 	// 3. 处理解析结果
 	if resolveErr != nil {
+		if !d.fallback {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: resolveErr}
+		}
 		// 回退: 使用原始的 dialer 和 address, 让系统处理DNS解析和连接
-		return d.defaultDialer.DialContext(ctx, network, address)
+		return d.dial(ctx, network, address)
 	}
+	ips = filterIPs(network, ips)
 
-	// 如果自定义解析成功, `ips` 列表中会有一个或多个IP地址
-	// 4. 尝试连接到所有解析出的IP地址, 直到成功为止
-	var firstDialErr error
-	for _, ip := range ips {
-		// 将解析出的IP和原始端口组合成新的拨号地址
-		dialAddr := net.JoinHostPort(ip.String(), port)
-
-		// 使用默认拨号器连接到这个具体的IP地址
-		conn, dialErr := d.defaultDialer.DialContext(ctx, network, dialAddr)
-		if dialErr == nil {
-			// 连接成功, 立即返回
-			return conn, nil
-		}
-
-		// 如果连接失败, 保存第一个遇到的错误, 以便在所有尝试都失败后返回
-		if firstDialErr == nil {
-			firstDialErr = dialErr
-		}
+	// 4. 依次尝试解析出的IP地址, 剩余的拨号时间平分给尚未尝试的地址
+	conn, err := dialIPs(ctx, d.now, d.dial, network, port, ips, d.timeout())
+	if errors.Is(err, errNoAddresses) {
+		// 解析成功但返回了一个空的IP列表 (或没有符合网络类型的地址)
+		return nil, fmt.Errorf("httpc: custom DNS resolved host %s but no IP addresses were found", host)
 	}
+	return conn, err
+}
 
-	// 5. 如果循环结束仍未成功连接, 返回保存的第一个错误
-	if firstDialErr == nil {
-		// 这种情况很罕见, 意味着解析成功但返回了一个空的IP列表
-		return nil, fmt.Errorf("httpc: custom DNS resolved host %s but no IP addresses were found", host)
+// filterIPs 按拨号网络 (tcp4/tcp6) 过滤地址
+func filterIPs(network string, ips []net.IP) []net.IP {
+	family := ipNetwork(network)
+	if family == "ip" {
+		return ips
+	}
+	filtered := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		if (ip.To4() != nil) == (family == "ip4") {
+			filtered = append(filtered, ip)
+		}
 	}
+	return filtered
+}
 
-	return nil, firstDialErr
+// dnsServerResolver 使用指定的DNS服务器列表解析主机名, 供 WithDNSResolver 使用
+type dnsServerResolver struct {
+	servers []string      // 自定义DNS服务器地址列表 (格式 "ip:port")
+	timeout time.Duration // 单次DNS查询的超时时间
 }
 
-// resolveWithCustomDNS 使用自定义的DNS服务器列表来解析主机名
+// LookupIP 使用自定义的DNS服务器列表来解析主机名
 // 它会按顺序尝试列表中的每个DNS服务器, 直到有一个成功返回结果
-func (d *customDialer) resolveWithCustomDNS(ctx context.Context, host string) ([]net.IP, error) {
+func (d *dnsServerResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
 	// 创建一个临时的 net.Resolver 实例, 其拨号逻辑被我们重写
 	resolver := &net.Resolver{
 		// 必须设置为 true, Go才会使用我们自定义的 Dial 函数
@@ -73,11 +116,11 @@ func (d *customDialer) resolveWithCustomDNS(ctx context.Context, host string) ([
 		// 自定义拨号函数, 用于连接到DNS服务器本身
 		Dial: func(dialCtx context.Context, network, address string) (net.Conn, error) {
 			// 这个内部拨号器仅用于连接DNS服务器, 使用我们配置的超时时间
-			dnsDialer := net.Dialer{Timeout: d.dnsTimeout}
+			dnsDialer := net.Dialer{Timeout: d.timeout}
 
 			var lastErr error
 			// 遍历所有提供的DNS服务器地址
-			for _, server := range d.dnsServers {
+			for _, server := range d.servers {
 				// 尝试连接到DNS服务器
 				conn, err := dnsDialer.DialContext(dialCtx, network, server)
 				if err == nil {
@@ -1,6 +1,7 @@
 package httpc
 
 import (
+	"bytes"
 	"encoding/gob"
 	"encoding/xml"
 	"errors"
@@ -8,11 +9,96 @@ import (
 	"io"
 	"net/http"
 
-	"github.com/go-json-experiment/json"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
 
 	"github.com/WJQSERVER-STUDIO/go-utils/iox"
 )
 
+// Response 是对 http.Response 的封装, 附带本次请求的传输统计与尝试记录
+type Response struct {
+	*http.Response
+	stats    *transferStatsHolder
+	attempts *AttemptLog
+}
+
+// TransferStats 返回最后一次请求尝试的传输统计
+// 响应体读取完毕或关闭前, Duration 与 BytesReceived 为当前进度
+func (r *Response) TransferStats() TransferStats {
+	if r == nil || r.stats == nil {
+		return TransferStats{}
+	}
+	if tracker := r.stats.get(); tracker != nil {
+		return tracker.snapshot()
+	}
+	return TransferStats{}
+}
+
+// Attempts 返回本次请求的尝试记录, 包括每次尝试的状态码, 错误与重试等待
+func (r *Response) Attempts() AttemptLog {
+	if r == nil || r.attempts == nil {
+		return AttemptLog{}
+	}
+	return *r.attempts
+}
+
+// Peek 返回响应体从当前读取位置开始的至多 n 个字节, 但不消耗它们: 之后读取 Body 仍会先得到这些字节.
+// 可用于在决定如何解码或转发之前嗅探内容类型或魔数. 响应体不足 n 字节时返回全部剩余内容且错误为 nil;
+// 返回的切片不应被修改. n 为负数时返回错误, 没有响应体时返回空切片
+func (r *Response) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("httpc: negative peek size %d", n)
+	}
+	if r.Body == nil {
+		return nil, nil
+	}
+	body, ok := r.Body.(*peekBody)
+	if !ok {
+		body = &peekBody{ReadCloser: r.Body}
+		r.Body = body
+	}
+	if missing := n - len(body.buf); missing > 0 {
+		more := make([]byte, missing)
+		m, err := io.ReadFull(body.ReadCloser, more)
+		body.buf = append(body.buf, more[:m]...)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return body.buf, err
+		}
+	}
+	return body.buf[:min(n, len(body.buf))], nil
+}
+
+// peekBody 在原始响应体之前放回 Peek 预读的数据
+type peekBody struct {
+	io.ReadCloser
+	buf []byte // 已预读但尚未被读取的数据
+}
+
+func (b *peekBody) Read(p []byte) (int, error) {
+	if len(b.buf) > 0 {
+		n := copy(p, b.buf)
+		b.buf = b.buf[n:]
+		return n, nil
+	}
+	return b.ReadCloser.Read(p)
+}
+
+// Send 执行请求并返回带有传输统计与尝试记录的 Response
+// 调用方负责关闭 resp.Body
+func (rb *RequestBuilder) Send() (*Response, error) {
+	holder := &transferStatsHolder{}
+	ctx := withTransferStatsHolder(rb.context, holder)
+	log := attemptLogFrom(ctx)
+	if log == nil {
+		ctx, log = ContextWithAttemptLog(ctx)
+	}
+	resp, err := rb.execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{Response: resp, stats: holder, attempts: log}, nil
+}
+
 // --- 响应处理方法 (使用 RequestBuilder 重构) ---
 
 // DecodeJSON 解析 JSON 响应
@@ -79,17 +165,15 @@ func (c *Client) decodeJSONResponse(resp *http.Response, obj any) error {
 		return c.errorResponse(resp)
 	}
 
-	/*
-		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
-			return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
-		}
-	*/
-
-	err := json.UnmarshalRead(resp.Body, obj)
+	buf, err := c.readPooled(resp)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+		return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
 	}
+	defer c.bufferPool.Put(buf)
 
+	if err := c.jsonEngine.Unmarshal(buf.Bytes(), obj); err != nil {
+		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
+	}
 	return nil
 }
 
@@ -97,12 +181,28 @@ func (c *Client) decodeXMLResponse(resp *http.Response, v any) error {
 	if resp.StatusCode >= 400 {
 		return c.errorResponse(resp)
 	}
-	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+	if err := c.newXMLDecoder(resp.Body).Decode(v); err != nil {
 		return fmt.Errorf("%w: %v", ErrDecodeResponse, err)
 	}
 	return nil
 }
 
+// newXMLDecoder 创建应用了客户端字符集转换器的 XML 解码器
+func (c *Client) newXMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = c.xmlCharsetReader
+	return decoder
+}
+
+// defaultXMLCharsetReader 基于 WHATWG 编码索引将 XML 声明的字符集转换为 UTF-8
+func defaultXMLCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("httpc: unsupported XML charset %q: %w", charset, err)
+	}
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
+
 func (c *Client) decodeGOBResponse(resp *http.Response, v any) error {
 	if resp.StatusCode >= 400 {
 		return c.errorResponse(resp)
@@ -122,20 +222,70 @@ func (c *Client) decodeTextResponse(resp *http.Response) (string, error) {
 		return "", c.errorResponse(resp)
 	}
 
-	bodyBytes, err := iox.ReadAll(resp.Body)
+	buf, err := c.readPooled(resp)
 	if err != nil {
-		return "", fmt.Errorf("%w: %s", err, ErrDecodeResponse)
+		return "", fmt.Errorf("%w: %w", ErrDecodeResponse, err)
 	}
-	return string(bodyBytes), nil
+	defer c.bufferPool.Put(buf)
+	return buf.String(), nil
 }
 
 func (c *Client) decodeBytesResponse(resp *http.Response) ([]byte, error) {
 	if resp.StatusCode >= 400 {
 		return nil, c.errorResponse(resp)
 	}
-	bodyBytes, err := iox.ReadAll(resp.Body)
+	bodyBytes, err := c.readBody(resp)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", err, ErrDecodeResponse)
+		return nil, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
 	}
 	return bodyBytes, nil
 }
+
+// maxBodyPresize 是按 Content-Length 预分配的上限, 防止异常的 Content-Length 导致过量分配
+const maxBodyPresize = 64 << 20
+
+// readBody 读取整个响应体并返回由调用方持有的切片
+// Content-Length 已知时按其精确分配一次, 否则先读入池化 buffer 再复制一次
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	if n := resp.ContentLength; n >= 0 && n <= maxBodyPresize {
+		// 多分配 1 字节用于确认响应体确实在 Content-Length 处结束
+		body := make([]byte, n, n+1)
+		if _, err := io.ReadFull(resp.Body, body); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBodyRead, err)
+		}
+		extra, err := resp.Body.Read(body[n : n+1])
+		if extra == 0 && (err == nil || err == io.EOF) {
+			return body, nil
+		}
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%w: %w", ErrBodyRead, err)
+		}
+		rest, err := iox.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBodyRead, err)
+		}
+		return append(body[:n+1], rest...), nil
+	}
+
+	buf, err := c.readPooled(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer c.bufferPool.Put(buf)
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// readPooled 将整个响应体读入按 Content-Length 选取的池化 buffer, 调用方负责放回
+func (c *Client) readPooled(resp *http.Response) (*bytes.Buffer, error) {
+	size := 0
+	if n := resp.ContentLength; n > 0 && n <= maxBodyPresize {
+		// ReadFrom 在确认 EOF 前需要 MinRead 的剩余空间
+		size = int(n) + bytes.MinRead
+	}
+	buf := c.getBuffer(size)
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		c.bufferPool.Put(buf)
+		return nil, fmt.Errorf("%w: %w", ErrBodyRead, err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,204 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newBodyResponse 构造一个响应体为 body 的响应, contentLength 为 -1 时表示未知长度
+func newBodyResponse(body io.Reader, contentLength int64) *http.Response {
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(body),
+		ContentLength: contentLength,
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	client := New()
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+	}{
+		{name: "known length", body: "hello world", contentLength: 11},
+		{name: "unknown length", body: strings.Repeat("x", 100<<10), contentLength: -1},
+		{name: "empty", body: "", contentLength: 0},
+		// 中间件替换了响应体但未更新 Content-Length 时仍读取完整内容
+		{name: "longer than content length", body: "hello world", contentLength: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.readBody(newBodyResponse(strings.NewReader(tt.body), tt.contentLength))
+			if err != nil {
+				t.Fatalf("readBody() error = %v", err)
+			}
+			if string(got) != tt.body {
+				t.Fatalf("readBody() = %d bytes, want %d bytes", len(got), len(tt.body))
+			}
+		})
+	}
+
+	_, err := client.readBody(newBodyResponse(strings.NewReader("short"), 10))
+	if !errors.Is(err, io.ErrUnexpectedEOF) || !errors.Is(err, ErrBodyRead) {
+		t.Fatalf("readBody() error = %v, want ErrBodyRead wrapping %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestResponsePeek(t *testing.T) {
+	resp := &Response{Response: newBodyResponse(strings.NewReader("\x89PNG\r\n\x1a\nimage data"), -1)}
+
+	head, err := resp.Peek(4)
+	if err != nil {
+		t.Fatalf("Peek() error = %v", err)
+	}
+	if string(head) != "\x89PNG" {
+		t.Fatalf("Peek(4) = %q, want %q", head, "\x89PNG")
+	}
+	// 再次预读更多字节, 已预读的部分不会丢失
+	head, err = resp.Peek(8)
+	if err != nil {
+		t.Fatalf("Peek() error = %v", err)
+	}
+	if string(head) != "\x89PNG\r\n\x1a\n" {
+		t.Fatalf("Peek(8) = %q, want %q", head, "\x89PNG\r\n\x1a\n")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if string(body) != "\x89PNG\r\n\x1a\nimage data" {
+		t.Fatalf("body = %q, want the full body", body)
+	}
+
+	short := &Response{Response: newBodyResponse(strings.NewReader("hi"), -1)}
+	if head, err := short.Peek(512); err != nil || string(head) != "hi" {
+		t.Fatalf("Peek(512) = %q, %v, want %q, nil", head, err, "hi")
+	}
+}
+
+func TestResponsePeekInvalidInput(t *testing.T) {
+	resp := &Response{Response: newBodyResponse(strings.NewReader("data"), -1)}
+	if _, err := resp.Peek(-1); err == nil {
+		t.Fatal("Peek(-1) error = nil, want error")
+	}
+	if head, err := resp.Peek(2); err != nil || string(head) != "da" {
+		t.Fatalf("Peek(2) after invalid size = %q, %v, want %q, nil", head, err, "da")
+	}
+
+	noBody := &Response{Response: &http.Response{}}
+	if head, err := noBody.Peek(4); err != nil || len(head) != 0 {
+		t.Fatalf("Peek(4) on nil Body = %q, %v, want empty, nil", head, err)
+	}
+}
+
+func TestErrorResponsePreview(t *testing.T) {
+	client := New()
+	resp := newBodyResponse(strings.NewReader(strings.Repeat("e", 4<<10)), -1)
+	resp.StatusCode = http.StatusBadGateway
+	resp.Header.Set("X-Request-Id", "abc")
+
+	err := client.errorResponse(resp)
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("errorResponse() = %T, want *HTTPError", err)
+	}
+	if len(httpErr.Body) != 1<<10 {
+		t.Fatalf("len(Body) = %d, want %d", len(httpErr.Body), 1<<10)
+	}
+	if got := httpErr.Header.Get("X-Request-Id"); got != "abc" {
+		t.Fatalf("Header X-Request-Id = %q, want %q", got, "abc")
+	}
+}
+
+func benchmarkPayload() []byte {
+	return []byte(`{"id":1,"name":"` + strings.Repeat("x", 16<<10) + `","tags":["a","b","c"]}`)
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	client := New()
+	payload := benchmarkPayload()
+	reader := bytes.NewReader(payload)
+	resp := newBodyResponse(reader, int64(len(payload)))
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	for b.Loop() {
+		reader.Reset(payload)
+		if _, err := client.decodeBytesResponse(resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeText(b *testing.B) {
+	client := New()
+	payload := benchmarkPayload()
+	reader := bytes.NewReader(payload)
+	resp := newBodyResponse(reader, -1)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	for b.Loop() {
+		reader.Reset(payload)
+		if _, err := client.decodeTextResponse(resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	client := New()
+	payload := benchmarkPayload()
+	reader := bytes.NewReader(payload)
+	resp := newBodyResponse(reader, int64(len(payload)))
+	var v struct {
+		ID   int      `json:"id"`
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(payload)))
+	for b.Loop() {
+		reader.Reset(payload)
+		if err := client.decodeJSONResponse(resp, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkErrorResponse(b *testing.B) {
+	client := New()
+	payload := []byte(`{"error":"upstream unavailable"}`)
+	reader := bytes.NewReader(payload)
+	resp := newBodyResponse(reader, int64(len(payload)))
+	resp.StatusCode = http.StatusServiceUnavailable
+	b.ReportAllocs()
+	for b.Loop() {
+		reader.Reset(payload)
+		_ = client.errorResponse(resp)
+	}
+}
+
+func TestReadFailureWrapsDecodeErrorAndCause(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "10")
+		_, _ = io.WriteString(w, "short")
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{}))
+	_, err := client.GET(srv.URL).Text()
+	if !errors.Is(err, ErrDecodeResponse) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("Text() error = %v, want ErrDecodeResponse wrapping %v", err, io.ErrUnexpectedEOF)
+	}
+	if !strings.HasPrefix(err.Error(), ErrDecodeResponse.Error()) {
+		t.Fatalf("Text() error = %q, want it to start with %q", err, ErrDecodeResponse)
+	}
+}
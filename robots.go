@@ -0,0 +1,397 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRobotsTTL     = 24 * time.Hour
+	robotsUnreachableTTL = time.Minute // robots.txt 不可访问时的缓存时长, 避免每个请求都重新抓取
+	maxRobotsSize        = 500 << 10   // RFC 9309 要求至少解析 500KiB
+	maxRobotsRedirects   = 5
+	robotsPath           = "/robots.txt"
+	maxRobotsHosts       = 1024 // 缓存的主机数量上限
+)
+
+// ErrRobotsDisallowed 表示请求的路径被 robots.txt 禁止
+var ErrRobotsDisallowed = errors.New("httpc: disallowed by robots.txt")
+
+// RobotsError 描述被 robots.txt 拦截的请求, errors.Is(err, ErrRobotsDisallowed) 为 true
+type RobotsError struct {
+	URL  string // 被拦截的请求 URL
+	Rule string // 命中的 Disallow 规则, robots.txt 不可访问 (5xx 或网络错误) 时为空
+}
+
+func (e *RobotsError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("httpc: %s disallowed: robots.txt unreachable", e.URL)
+	}
+	return fmt.Sprintf("httpc: %s disallowed by robots.txt rule %q", e.URL, e.Rule)
+}
+
+func (e *RobotsError) Unwrap() error { return ErrRobotsDisallowed }
+
+// RobotsOptions robots.txt 配置
+type RobotsOptions struct {
+	UserAgent     string        // 匹配 robots.txt 分组使用的产品名, 为空时取客户端 User-Agent 的第一个词
+	TTL           time.Duration // robots.txt 的缓存时长, 0 使用默认值 (24h)
+	MaxCrawlDelay time.Duration // Crawl-delay 的上限, 0 表示不限制, 负数表示忽略 Crawl-delay
+}
+
+// WithRobots 为爬虫场景启用 robots.txt: 按主机抓取并缓存 robots.txt, 禁止访问的路径返回 *RobotsError,
+// Crawl-delay 作为该主机的限速 (与 WithRateLimit 叠加). 规则按 RFC 9309 匹配 (最长匹配, 同长度 Allow 优先);
+// robots.txt 返回 4xx 时允许访问全部路径, 返回 5xx 或无法访问时禁止访问全部路径
+func WithRobots(opts RobotsOptions) Option {
+	return func(c *Client) {
+		if opts.TTL <= 0 {
+			opts.TTL = defaultRobotsTTL
+		}
+		c.robots = &robotsChecker{opts: opts, hosts: make(map[string]*robotsEntry)}
+	}
+}
+
+// robotsRule 是一条 Allow/Disallow 规则
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsRules 是 robots.txt 中与客户端匹配的分组
+type robotsRules struct {
+	disallowAll bool // robots.txt 不可访问
+	rules       []robotsRule
+	crawlDelay  time.Duration
+}
+
+// robotsEntry 是单个主机的 robots.txt 缓存, ready 关闭后其余字段只读
+type robotsEntry struct {
+	ready   chan struct{}
+	rules   *robotsRules // 抓取因请求取消而失败时为 nil
+	expires time.Time
+	delay   time.Duration
+	limiter *rateLimiter // Crawl-delay 限速, nil 表示不限制
+}
+
+// robotsChecker 是 WithRobots 的运行时状态
+type robotsChecker struct {
+	opts RobotsOptions
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+// robotsRoundTripper 是一个内部中间件, 检查请求路径是否被 robots.txt 允许并等待 Crawl-delay
+// robots.txt 通过 next 抓取, 因此同样受限速影响
+func (c *Client) robotsRoundTripper(robots *robotsChecker, next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == robotsPath || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+			return next.RoundTrip(req)
+		}
+
+		entry, err := robots.entry(req.Context(), c.clock, req.URL.Scheme+"://"+req.URL.Host, func(ctx context.Context) (*robotsRules, error) {
+			return c.fetchRobots(ctx, req, next)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		path := requestRobotsPath(req)
+		if allowed, rule := entry.rules.allowed(path); !allowed {
+			return nil, &RobotsError{URL: req.URL.String(), Rule: rule}
+		}
+		if entry.limiter != nil {
+			if err := entry.limiter.wait(req.Context(), c.clock, req.URL.Host); err != nil {
+				return nil, fmt.Errorf("httpc: crawl delay wait: %w", err)
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// entry 返回主机的 robots.txt 缓存, 未缓存或已过期时调用 fetch; 同一主机的并发请求只抓取一次
+func (r *robotsChecker) entry(ctx context.Context, clock Clock, key string, fetch func(context.Context) (*robotsRules, error)) (*robotsEntry, error) {
+	for {
+		r.mu.Lock()
+		e, ok := r.hosts[key]
+		prev := e
+		if ok {
+			select {
+			case <-e.ready:
+				if clock.Now().After(e.expires) {
+					ok = false
+				}
+			default:
+			}
+		} else if len(r.hosts) >= maxRobotsHosts {
+			r.pruneLocked(clock.Now())
+		}
+		if !ok {
+			e = &robotsEntry{ready: make(chan struct{})}
+			r.hosts[key] = e
+			r.mu.Unlock()
+			return r.load(ctx, clock, key, e, prev, fetch)
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.ready:
+		}
+		if e.rules != nil {
+			return e, nil
+		}
+		// 抓取方的请求被取消, 由当前请求重新抓取
+	}
+}
+
+// pruneLocked 删除已过期的条目, 仍超过上限时删除最早过期的条目; 正在抓取的条目不删除
+func (r *robotsChecker) pruneLocked(now time.Time) {
+	var oldest string
+	var oldestExpires time.Time
+	for key, e := range r.hosts {
+		select {
+		case <-e.ready:
+		default:
+			continue
+		}
+		if now.After(e.expires) {
+			delete(r.hosts, key)
+		} else if oldest == "" || e.expires.Before(oldestExpires) {
+			oldest, oldestExpires = key, e.expires
+		}
+	}
+	if len(r.hosts) >= maxRobotsHosts && oldest != "" {
+		delete(r.hosts, oldest)
+	}
+}
+
+// load 抓取并填充缓存条目, prev 为过期的旧条目, Crawl-delay 不变时沿用其限速状态
+func (r *robotsChecker) load(ctx context.Context, clock Clock, key string, e, prev *robotsEntry, fetch func(context.Context) (*robotsRules, error)) (*robotsEntry, error) {
+	defer close(e.ready)
+
+	rules, err := fetch(ctx)
+	if err != nil {
+		r.mu.Lock()
+		if r.hosts[key] == e {
+			delete(r.hosts, key)
+		}
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	ttl := r.opts.TTL
+	if rules.disallowAll {
+		ttl = min(ttl, robotsUnreachableTTL)
+	}
+	delay := rules.crawlDelay
+	if r.opts.MaxCrawlDelay < 0 {
+		delay = 0
+	} else if r.opts.MaxCrawlDelay > 0 {
+		delay = min(delay, r.opts.MaxCrawlDelay)
+	}
+	// Crawl-delay 复用限速器, 每个主机按各自的间隔放行, 与 WithRateLimit 的客户端限速相互独立
+	e.delay = delay
+	if prev != nil && prev.rules != nil && prev.delay == delay {
+		e.limiter = prev.limiter
+	} else if delay > 0 {
+		e.limiter = newRateLimiter(RateLimit{RequestsPerSecond: float64(time.Second) / float64(delay), Burst: 1})
+	}
+	e.expires = clock.Now().Add(ttl)
+	e.rules = rules
+	return e, nil
+}
+
+// fetchRobots 抓取并解析 req 所在主机的 robots.txt, 只有请求被取消时返回错误
+func (c *Client) fetchRobots(ctx context.Context, req *http.Request, next http.RoundTripper) (*robotsRules, error) {
+	agent := c.robots.opts.UserAgent
+	userAgent := req.Header.Get("User-Agent")
+	if userAgent == "" {
+		userAgent = c.userAgent
+	}
+	if agent == "" {
+		agent, _, _ = strings.Cut(userAgent, " ")
+		agent, _, _ = strings.Cut(agent, "/")
+	}
+
+	target := req.URL.Scheme + "://" + req.URL.Host + robotsPath
+	for range maxRobotsRedirects + 1 {
+		robotsReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return &robotsRules{disallowAll: true}, nil
+		}
+		if userAgent != "" {
+			robotsReq.Header.Set("User-Agent", userAgent)
+		}
+
+		resp, err := next.RoundTrip(robotsReq)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return &robotsRules{disallowAll: true}, nil
+		}
+
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != "":
+			resp.Body.Close()
+			loc, err := robotsReq.URL.Parse(resp.Header.Get("Location"))
+			if err != nil {
+				return &robotsRules{disallowAll: true}, nil
+			}
+			target = loc.String()
+			continue
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			data, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+			resp.Body.Close()
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				return &robotsRules{disallowAll: true}, nil
+			}
+			return parseRobots(string(data), agent), nil
+		case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+			resp.Body.Close()
+			return &robotsRules{}, nil
+		default:
+			resp.Body.Close()
+			return &robotsRules{disallowAll: true}, nil
+		}
+	}
+	// 重定向次数过多时按 RFC 9309 视为不可访问
+	return &robotsRules{disallowAll: true}, nil
+}
+
+// parseRobots 解析 robots.txt, 返回与 agent 匹配的分组; 没有匹配的分组时使用 "*" 分组
+func parseRobots(data, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	var specific, wildcard robotsRules
+	var hasSpecific bool
+	var groupAgents []string
+	inRules := false
+
+	for line := range strings.Lines(data) {
+		line, _, _ = strings.Cut(line, "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				groupAgents, inRules = groupAgents[:0], false
+			}
+			name, _, _ := strings.Cut(value, "/")
+			groupAgents = append(groupAgents, strings.ToLower(strings.TrimSpace(name)))
+			continue
+		}
+		if key != "allow" && key != "disallow" && key != "crawl-delay" {
+			continue
+		}
+		inRules = true
+
+		var target *robotsRules
+		for _, name := range groupAgents {
+			if agent != "" && name == agent {
+				target, hasSpecific = &specific, true
+				break
+			}
+			if name == "*" {
+				target = &wildcard
+			}
+		}
+		if target == nil {
+			continue
+		}
+		switch key {
+		case "crawl-delay":
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				target.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			if value != "" {
+				target.rules = append(target.rules, robotsRule{pattern: value, allow: key == "allow"})
+			}
+		}
+	}
+
+	if hasSpecific {
+		return &specific
+	}
+	return &wildcard
+}
+
+// allowed 判断路径是否允许访问, 不允许时返回命中的规则
+func (r *robotsRules) allowed(path string) (bool, string) {
+	if r.disallowAll {
+		return false, ""
+	}
+	var best *robotsRule
+	for i := range r.rules {
+		rule := &r.rules[i]
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if best == nil || len(rule.pattern) > len(best.pattern) ||
+			(len(rule.pattern) == len(best.pattern) && rule.allow && !best.allow) {
+			best = rule
+		}
+	}
+	if best == nil || best.allow {
+		return true, ""
+	}
+	return false, best.pattern
+}
+
+// robotsMatch 匹配 robots.txt 路径模式, 支持 "*" 通配与结尾的 "$" 锚定
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	if len(parts) == 1 {
+		return !anchored || len(path) == len(parts[0])
+	}
+
+	pos := len(parts[0])
+	middle, last := parts[1:len(parts)-1], parts[len(parts)-1]
+	for _, part := range middle {
+		i := strings.Index(path[pos:], part)
+		if i < 0 {
+			return false
+		}
+		pos += i + len(part)
+	}
+	if anchored {
+		return len(path)-len(last) >= pos && strings.HasSuffix(path, last)
+	}
+	return strings.Contains(path[pos:], last)
+}
+
+// requestRobotsPath 返回用于匹配 robots.txt 规则的路径 (含查询参数)
+func requestRobotsPath(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if req.URL.RawQuery != "" {
+		path += "?" + req.URL.RawQuery
+	}
+	return path
+}
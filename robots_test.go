@@ -0,0 +1,193 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	const robots = `
+# comment
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Crawl-delay: 2
+
+User-agent: MyBot/1.0
+User-agent: other
+Disallow: /*.json$
+Disallow: /tmp/ # trailing comment
+Allow: /tmp/keep
+`
+	tests := []struct {
+		agent   string
+		path    string
+		allowed bool
+	}{
+		{"somebot", "/", true},
+		{"somebot", "/private/x", false},
+		{"somebot", "/private/public/x", true},
+		{"mybot", "/private/x", true},
+		{"MyBot", "/data/list.json", false},
+		{"mybot", "/data/list.json?x=1", true},
+		{"mybot", "/tmp/a", false},
+		{"mybot", "/tmp/keep", true},
+	}
+	for _, tt := range tests {
+		rules := parseRobots(robots, tt.agent)
+		if got, _ := rules.allowed(tt.path); got != tt.allowed {
+			t.Fatalf("agent %q path %q allowed = %v, want %v", tt.agent, tt.path, got, tt.allowed)
+		}
+	}
+	if got := parseRobots(robots, "somebot").crawlDelay; got != 2*time.Second {
+		t.Fatalf("crawlDelay = %v, want 2s", got)
+	}
+	if got := parseRobots(robots, "mybot").crawlDelay; got != 0 {
+		t.Fatalf("mybot crawlDelay = %v, want 0", got)
+	}
+}
+
+func TestRobotsMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/a", "/abc", true},
+		{"/a$", "/abc", false},
+		{"/a$", "/a", true},
+		{"/*/b", "/x/y/b/c", true},
+		{"/*.php$", "/index.php", true},
+		{"/*.php$", "/index.php5", false},
+		{"/a*b*c", "/axxbyyc", true},
+		{"/a*b*c", "/axxcyyb", false},
+	}
+	for _, tt := range tests {
+		if got := robotsMatch(tt.pattern, tt.path); got != tt.want {
+			t.Fatalf("robotsMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWithRobots(t *testing.T) {
+	var robotsFetches, pages atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			robotsFetches.Add(1)
+			_, _ = io.WriteString(w, "User-agent: crawler\nDisallow: /admin\n")
+			return
+		}
+		pages.Add(1)
+	}))
+	defer srv.Close()
+
+	client := New(WithUserAgent("crawler/2.0 (+https://example.com/bot)"), WithRobots(RobotsOptions{}))
+	for i := 0; i < 2; i++ {
+		if _, err := client.GET(srv.URL + "/page").Bytes(); err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+	}
+
+	_, err := client.GET(srv.URL + "/admin/users").Bytes()
+	var robotsErr *RobotsError
+	if !errors.As(err, &robotsErr) || !errors.Is(err, ErrRobotsDisallowed) {
+		t.Fatalf("err = %v, want RobotsError", err)
+	}
+	if robotsErr.Rule != "/admin" {
+		t.Fatalf("Rule = %q, want %q", robotsErr.Rule, "/admin")
+	}
+	if got := robotsFetches.Load(); got != 1 {
+		t.Fatalf("robots.txt fetches = %d, want 1", got)
+	}
+	if got := pages.Load(); got != 2 {
+		t.Fatalf("pages = %d, want 2", got)
+	}
+}
+
+func TestWithRobotsUnavailable(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(status)
+		}
+	}))
+	defer srv.Close()
+
+	client := New(WithRetryOptions(RetryOptions{MaxAttempts: 0}), WithRobots(RobotsOptions{}))
+	if _, err := client.GET(srv.URL + "/any").Bytes(); err != nil {
+		t.Fatalf("404 robots.txt: Bytes() error = %v, want allowed", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	client = New(WithRetryOptions(RetryOptions{MaxAttempts: 0}), WithRobots(RobotsOptions{}))
+	if _, err := client.GET(srv.URL + "/any").Bytes(); !errors.Is(err, ErrRobotsDisallowed) {
+		t.Fatalf("503 robots.txt: err = %v, want ErrRobotsDisallowed", err)
+	}
+}
+
+func TestWithRobotsCrawlDelay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = io.WriteString(w, "User-agent: *\nCrawl-delay: 10\n")
+		}
+	}))
+	defer srv.Close()
+
+	clock := NewFakeClock(time.Unix(0, 0))
+	client := New(WithClock(clock), WithRobots(RobotsOptions{}))
+	if _, err := client.GET(srv.URL + "/1").Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GET(srv.URL + "/2").Bytes()
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	select {
+	case err := <-done:
+		t.Fatalf("second request finished before crawl delay: %v", err)
+	default:
+	}
+	clock.Advance(10 * time.Second)
+	if err := <-done; err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+}
+
+func TestRobotsCheckerPrunesHosts(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	r := &robotsChecker{opts: RobotsOptions{TTL: time.Hour}, hosts: make(map[string]*robotsEntry)}
+	fetch := func(context.Context) (*robotsRules, error) { return &robotsRules{}, nil }
+	for i := range maxRobotsHosts {
+		if _, err := r.entry(context.Background(), clock, fmt.Sprintf("h%d", i), fetch); err != nil {
+			t.Fatalf("entry() error = %v", err)
+		}
+		clock.Advance(time.Second)
+	}
+
+	if _, err := r.entry(context.Background(), clock, "new", fetch); err != nil {
+		t.Fatalf("entry() error = %v", err)
+	}
+	if len(r.hosts) != maxRobotsHosts {
+		t.Fatalf("len(hosts) = %d, want %d", len(r.hosts), maxRobotsHosts)
+	}
+	if _, ok := r.hosts["h0"]; ok {
+		t.Fatal("oldest host was not evicted")
+	}
+
+	clock.Advance(2 * time.Hour)
+	if _, err := r.entry(context.Background(), clock, "later", fetch); err != nil {
+		t.Fatalf("entry() error = %v", err)
+	}
+	if len(r.hosts) != 1 {
+		t.Fatalf("len(hosts) = %d, want expired hosts pruned", len(r.hosts))
+	}
+}
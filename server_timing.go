@@ -0,0 +1,125 @@
+package httpc
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerTiming 是 Server-Timing 响应头中的一项服务端指标
+type ServerTiming struct {
+	Name        string
+	Duration    time.Duration // dur 参数 (毫秒) 换算的时长, 未提供时为 0
+	Description string        // desc 参数
+}
+
+// ServerTimingFunc Server-Timing 回调, 在收到带 Server-Timing 头的响应时调用
+type ServerTimingFunc func(req *http.Request, timings []ServerTiming)
+
+// WithOnServerTiming 设置 Server-Timing 回调, 对客户端收到的每个响应 (含每次重试) 生效,
+// 用于将服务端上报的耗时与客户端测得的耗时一同记录到指标或链路追踪中
+func WithOnServerTiming(fn ServerTimingFunc) Option {
+	return func(c *Client) {
+		c.onServerTiming = fn
+	}
+}
+
+// ServerTiming 解析响应头与 trailer (响应体读取完毕后可用) 中的 Server-Timing 指标
+func (r *Response) ServerTiming() []ServerTiming {
+	if r == nil || r.Response == nil {
+		return nil
+	}
+	return append(ParseServerTiming(r.Header), ParseServerTiming(r.Trailer)...)
+}
+
+// ParseServerTiming 解析 header 中的全部 Server-Timing 值, 格式错误的项被跳过.
+// 参数重复时以第一个为准, 未知参数被忽略
+func ParseServerTiming(header http.Header) []ServerTiming {
+	var timings []ServerTiming
+	for _, value := range header.Values("Server-Timing") {
+		for _, entry := range splitQuoted(value, ',') {
+			if t, ok := parseServerTimingEntry(entry); ok {
+				timings = append(timings, t)
+			}
+		}
+	}
+	return timings
+}
+
+// parseServerTimingEntry 解析 "name;dur=12.5;desc=..." 形式的一项
+func parseServerTimingEntry(entry string) (ServerTiming, bool) {
+	params := splitQuoted(entry, ';')
+	t := ServerTiming{Name: strings.TrimSpace(params[0])}
+	if t.Name == "" || strings.ContainsAny(t.Name, " \t\"=") {
+		return ServerTiming{}, false
+	}
+
+	var seenDur, seenDesc bool
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = unquoteHeaderValue(strings.TrimSpace(value))
+		switch {
+		case key == "dur" && !seenDur:
+			seenDur = true
+			if ms, err := strconv.ParseFloat(value, 64); err == nil {
+				t.Duration = time.Duration(ms * float64(time.Millisecond))
+			}
+		case key == "desc" && !seenDesc:
+			seenDesc = true
+			t.Description = value
+		}
+	}
+	return t, true
+}
+
+// splitQuoted 按 sep 拆分 s, 忽略引号字符串中的分隔符
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && quoted:
+			i++
+		case c == '"':
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquoteHeaderValue 去掉 RFC 9110 quoted-string 的引号与转义, 非引号值原样返回
+func unquoteHeaderValue(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// serverTimingRoundTripper 是一个内部中间件, 将每次尝试的响应中的 Server-Timing 指标交给回调
+func (c *Client) serverTimingRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if resp != nil {
+			if timings := ParseServerTiming(resp.Header); len(timings) > 0 {
+				c.onServerTiming(req, timings)
+			}
+		}
+		return resp, err
+	})
+}
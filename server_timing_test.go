@@ -0,0 +1,81 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseServerTiming(t *testing.T) {
+	header := http.Header{}
+	header.Add("Server-Timing", `miss, db;dur=53, app;dur=47.2;desc="render, layout \"main\""`)
+	header.Add("Server-Timing", `cdn-cache;desc=HIT;dur=0.5;dur=9, ;dur=1, bad name;dur=2, total;dur=abc`)
+
+	want := []ServerTiming{
+		{Name: "miss"},
+		{Name: "db", Duration: 53 * time.Millisecond},
+		{Name: "app", Duration: 47200 * time.Microsecond, Description: `render, layout "main"`},
+		{Name: "cdn-cache", Duration: 500 * time.Microsecond, Description: "HIT"},
+		{Name: "total"},
+	}
+	if got := ParseServerTiming(header); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseServerTiming() = %+v, want %+v", got, want)
+	}
+	if got := ParseServerTiming(http.Header{}); got != nil {
+		t.Fatalf("ParseServerTiming(empty) = %+v, want nil", got)
+	}
+}
+
+func TestResponseServerTiming(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server-Timing", "db;dur=12")
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var mu sync.Mutex
+	var forwarded []ServerTiming
+	client := New(WithOnServerTiming(func(req *http.Request, timings []ServerTiming) {
+		mu.Lock()
+		forwarded = append(forwarded, timings...)
+		mu.Unlock()
+	}))
+	resp, err := client.GET(srv.URL).Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := resp.ServerTiming(); len(got) != 1 || got[0].Name != "db" {
+		t.Fatalf("ServerTiming() = %+v, want [db]", got)
+	}
+	resp.Body.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(forwarded) != 1 || forwarded[0].Duration != 12*time.Millisecond {
+		t.Fatalf("forwarded = %+v, want [db 12ms]", forwarded)
+	}
+}
+
+func TestResponseServerTimingTrailer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Trailer", "Server-Timing")
+		w.Write([]byte("ok"))
+		w.Header().Set("Server-Timing", "render;dur=3")
+	}))
+	defer srv.Close()
+
+	resp, err := New().GET(srv.URL).Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := resp.Peek(16); err != nil {
+		t.Fatalf("Peek() error = %v", err)
+	}
+	if got := resp.ServerTiming(); len(got) != 1 || got[0].Name != "render" || got[0].Duration != 3*time.Millisecond {
+		t.Fatalf("ServerTiming() = %+v, want [render 3ms]", got)
+	}
+}
@@ -0,0 +1,12 @@
+package httpc
+
+import "syscall"
+
+// WithSocketOptions 设置在套接字连接前调用的控制函数 (net.Dialer.Control),
+// 可用于设置 TCP_NODELAY, IP_TOS/DSCP, SO_MARK 或绑定网卡等选项以实现流量分类与策略路由.
+// 仅作用于默认的基础拨号器, 使用 WithDialContext 时需自行设置
+func WithSocketOptions(control func(network, address string, c syscall.RawConn) error) Option {
+	return func(c *Client) {
+		c.dialer.Control = control
+	}
+}
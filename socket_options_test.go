@@ -0,0 +1,30 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+func TestWithSocketOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	var calls atomic.Int32
+	control := func(network, address string, c syscall.RawConn) error {
+		calls.Add(1)
+		return c.Control(func(fd uintptr) {})
+	}
+
+	client := New(WithSocketOptions(control), WithDialTimeout(defaultDialTimeout))
+	resp, err := client.GET(srv.URL).Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("control calls = %d, want 1", got)
+	}
+}
@@ -14,6 +14,12 @@ func (c *Client) NewRequest(method, urlStr string, body io.Reader) (*http.Reques
 	return builder.Build()
 }
 
+// StandardClient 返回经由 c.Do 发送请求的 *http.Client, 供只接受标准库客户端的第三方库使用.
+// 重试, 超时, 中间件等仍由本客户端处理; 单个请求可通过 ContextWithOptions 调整
+func (c *Client) StandardClient() *http.Client {
+	return &http.Client{Transport: RoundTripperFunc(c.Do)}
+}
+
 // Get 发送 GET 请求
 func (c *Client) Get(url string) (*http.Response, error) {
 	return c.GET(url).Execute()
@@ -0,0 +1,43 @@
+package httpc
+
+import (
+	"fmt"
+	"io"
+)
+
+// streamChunkSize 是 Stream 读取数据块的最小缓冲区大小, 保证自定义缓冲池返回空 buffer 时仍能读取
+const streamChunkSize = 32 << 10
+
+// Stream 执行请求并对读取到的每个数据块调用 fn, 用于代理转发与渐进式处理.
+// chunk 来自客户端缓冲池, 仅在本次调用期间有效, 需要保留时应自行复制.
+// 状态码 >= 400 时返回 HTTPError 且不调用 fn; fn 返回错误时停止读取并原样返回该错误; 响应体总会被关闭
+func (rb *RequestBuilder) Stream(fn func(chunk []byte) error) error {
+	resp, err := rb.Execute()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return rb.client.errorResponse(resp)
+	}
+
+	buf := rb.client.getBuffer(streamChunkSize)
+	defer rb.client.bufferPool.Put(buf)
+	chunk := buf.AvailableBuffer()
+	chunk = chunk[:cap(chunk)]
+
+	for {
+		n, err := resp.Body.Read(chunk)
+		if n > 0 {
+			if fnErr := fn(chunk[:n]); fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrBodyRead, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package httpc
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	payload := strings.Repeat("0123456789", 10<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	client := New()
+	var got bytes.Buffer
+	chunks := 0
+	err := client.GET(server.URL).Stream(func(chunk []byte) error {
+		chunks++
+		got.Write(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if got.String() != payload {
+		t.Fatalf("streamed %d bytes, want %d", got.Len(), len(payload))
+	}
+	if chunks < 2 {
+		t.Fatalf("chunks = %d, want several", chunks)
+	}
+	if retained := client.BufferPoolStats().Retained; retained != 1 {
+		t.Fatalf("Retained = %d, want 1 after Stream returns", retained)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = client.GET(server.URL).Stream(func(chunk []byte) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("Stream() = %v after %d calls, want %v after 1 call", err, calls, stop)
+	}
+
+	err = client.GET(server.URL + "/missing").Stream(func(chunk []byte) error {
+		t.Fatal("callback invoked for error status")
+		return nil
+	})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("Stream() error = %v, want 404 HTTPError", err)
+	}
+}
+
+// emptyBufferPool 总是返回容量为 0 的 buffer
+type emptyBufferPool struct{}
+
+func (emptyBufferPool) Get() *bytes.Buffer { return new(bytes.Buffer) }
+func (emptyBufferPool) Put(*bytes.Buffer)  {}
+
+func TestStreamWithZeroCapacityPool(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	var got bytes.Buffer
+	err := New(WithBufferPool(emptyBufferPool{})).GET(server.URL).Stream(func(chunk []byte) error {
+		got.Write(chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if got.String() != "hello" {
+		t.Fatalf("streamed %q, want %q", got.String(), "hello")
+	}
+}
@@ -0,0 +1,65 @@
+package httpc
+
+import "net/http"
+
+// TrafficStats 描述请求体与响应体的累计字节数
+// 统计的是 body 字节, 不含请求行, 头部与 TLS 开销; 启用透明解压时响应为解压后的字节数
+type TrafficStats struct {
+	BytesSent     int64 // 已发送的请求体字节数
+	BytesReceived int64 // 已接收的响应体字节数
+}
+
+// TrafficStats 返回客户端累计流量, 以及按主机 (host:port) 的累计流量, 可用于按上游归属出口流量.
+// 按主机的统计最多保留 1024 个主机, 淘汰的主机仍计入总量
+func (c *Client) TrafficStats() (TrafficStats, map[string]TrafficStats) {
+	c.poolStats.mu.RLock()
+	defer c.poolStats.mu.RUnlock()
+
+	total := c.poolStats.evicted
+	hosts := make(map[string]TrafficStats, len(c.poolStats.hosts))
+	for host, counters := range c.poolStats.hosts {
+		stats := TrafficStats{
+			BytesSent:     counters.bytesSent.Load(),
+			BytesReceived: counters.bytesReceived.Load(),
+		}
+		if stats == (TrafficStats{}) {
+			continue
+		}
+		hosts[host] = stats
+		total.BytesSent += stats.BytesSent
+		total.BytesReceived += stats.BytesReceived
+	}
+	return total, hosts
+}
+
+// trafficRoundTripper 是一个内部中间件, 用计数 reader 包装请求体与响应体, 按主机累计字节数
+// 位于重试之内, 每次尝试都会计入
+func (c *Client) trafficRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		counters := c.poolStats.host(req.URL.Host)
+		audit := auditStateFrom(req.Context())
+		if audit != nil {
+			audit.attempts.Add(1)
+		}
+
+		outReq := req
+		if req.Body != nil && req.Body != http.NoBody {
+			outReq = req.WithContext(req.Context()) // 浅拷贝, 避免修改调用方的请求
+			outReq.Body = &countingReadCloser{ReadCloser: req.Body, n: &counters.bytesSent}
+			if audit != nil {
+				outReq.Body = &countingReadCloser{ReadCloser: outReq.Body, n: &audit.sent}
+			}
+		}
+
+		resp, err := next.RoundTrip(outReq)
+		if resp != nil {
+			resp.Request = req
+		}
+		// 101 协议升级的响应体是可写的连接, 不做包装
+		if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			return resp, err
+		}
+		resp.Body = &countingReadCloser{ReadCloser: resp.Body, n: &counters.bytesReceived}
+		return resp, nil
+	})
+}
@@ -0,0 +1,44 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTrafficStatsPerHost(t *testing.T) {
+	newServer := func(reply string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			_, _ = io.WriteString(w, reply)
+		}))
+	}
+	a, b := newServer("0123456789"), newServer("xyz")
+	defer a.Close()
+	defer b.Close()
+
+	client := New()
+	for range 2 {
+		if _, err := client.POST(a.URL).SetRawBody([]byte("hello")).Text(); err != nil {
+			t.Fatalf("Text() error = %v", err)
+		}
+	}
+	if _, err := client.GET(b.URL).Text(); err != nil {
+		t.Fatalf("Text() error = %v", err)
+	}
+
+	total, hosts := client.TrafficStats()
+	if want := (TrafficStats{BytesSent: 10, BytesReceived: 23}); total != want {
+		t.Fatalf("total = %+v, want %+v", total, want)
+	}
+	hostA := strings.TrimPrefix(a.URL, "http://")
+	if want := (TrafficStats{BytesSent: 10, BytesReceived: 20}); hosts[hostA] != want {
+		t.Fatalf("hosts[a] = %+v, want %+v", hosts[hostA], want)
+	}
+	hostB := strings.TrimPrefix(b.URL, "http://")
+	if want := (TrafficStats{BytesReceived: 3}); hosts[hostB] != want {
+		t.Fatalf("hosts[b] = %+v, want %+v", hosts[hostB], want)
+	}
+}
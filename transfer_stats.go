@@ -0,0 +1,171 @@
+package httpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TransferStats 描述单次请求的传输统计
+type TransferStats struct {
+	BytesSent       int64         // 已发送的请求体字节数
+	BytesReceived   int64         // 已接收的响应体字节数
+	TimeToFirstByte time.Duration // 从发送请求到收到响应头的耗时
+	Duration        time.Duration // 从发送请求到响应体读取完毕或关闭的耗时 (未完成时为当前耗时)
+	Completed       bool          // 响应体是否已读取完毕或关闭
+}
+
+// Throughput 返回响应体的有效吞吐量 (字节/秒)
+func (s TransferStats) Throughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.BytesReceived) / s.Duration.Seconds()
+}
+
+// TransferStatsFunc 传输统计回调, 在响应体读取完毕或关闭时调用一次
+type TransferStatsFunc func(req *http.Request, stats TransferStats)
+
+// WithOnTransferStats 设置传输统计回调, 对客户端发出的每次请求 (含每次重试) 生效
+func WithOnTransferStats(fn TransferStatsFunc) Option {
+	return func(c *Client) {
+		c.onTransferStats = fn
+	}
+}
+
+// transferTracker 记录一次请求尝试的传输数据
+type transferTracker struct {
+	start    time.Time
+	sent     atomic.Int64
+	received atomic.Int64
+	ttfb     atomic.Int64
+	duration atomic.Int64
+	done     atomic.Bool
+}
+
+func (t *transferTracker) snapshot() TransferStats {
+	stats := TransferStats{
+		BytesSent:       t.sent.Load(),
+		BytesReceived:   t.received.Load(),
+		TimeToFirstByte: time.Duration(t.ttfb.Load()),
+		Completed:       t.done.Load(),
+	}
+	if stats.Completed {
+		stats.Duration = time.Duration(t.duration.Load())
+	} else {
+		stats.Duration = time.Since(t.start)
+	}
+	return stats
+}
+
+// finish 标记传输完成, 仅首次调用返回 true
+func (t *transferTracker) finish() bool {
+	if t.done.Load() {
+		return false
+	}
+	t.duration.Store(int64(time.Since(t.start)))
+	return t.done.CompareAndSwap(false, true)
+}
+
+// transferStatsHolder 通过 Context 传递, 保存最后一次尝试的 tracker 供 Response 读取
+type transferStatsHolder struct {
+	mu      sync.Mutex
+	tracker *transferTracker
+}
+
+func (h *transferStatsHolder) set(t *transferTracker) {
+	h.mu.Lock()
+	h.tracker = t
+	h.mu.Unlock()
+}
+
+func (h *transferStatsHolder) get() *transferTracker {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.tracker
+}
+
+type transferStatsKey struct{}
+
+func withTransferStatsHolder(ctx context.Context, h *transferStatsHolder) context.Context {
+	return context.WithValue(ctx, transferStatsKey{}, h)
+}
+
+// transferStatsRoundTripper 是一个内部中间件, 位于 transport 之上, 用计数 reader 包装请求体与响应体
+func (c *Client) transferStatsRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		holder, _ := req.Context().Value(transferStatsKey{}).(*transferStatsHolder)
+		if holder == nil && c.onTransferStats == nil {
+			return next.RoundTrip(req)
+		}
+
+		tracker := &transferTracker{start: time.Now()}
+		if holder != nil {
+			holder.set(tracker)
+		}
+		outReq := req
+		if req.Body != nil && req.Body != http.NoBody {
+			outReq = req.WithContext(req.Context()) // 浅拷贝, 避免修改调用方的请求
+			outReq.Body = &countingReadCloser{ReadCloser: req.Body, n: &tracker.sent}
+		}
+
+		resp, err := next.RoundTrip(outReq)
+		if resp != nil {
+			resp.Request = req
+		}
+		tracker.ttfb.Store(int64(time.Since(tracker.start)))
+		// 101 协议升级的响应体是可写的连接, 不做包装
+		if err != nil || resp == nil || resp.Body == nil || resp.StatusCode == http.StatusSwitchingProtocols {
+			if tracker.finish() && c.onTransferStats != nil {
+				c.onTransferStats(req, tracker.snapshot())
+			}
+			return resp, err
+		}
+
+		resp.Body = &trackedBody{
+			countingReadCloser: countingReadCloser{ReadCloser: resp.Body, n: &tracker.received},
+			onDone: func() {
+				if tracker.finish() && c.onTransferStats != nil {
+					c.onTransferStats(req, tracker.snapshot())
+				}
+			},
+		}
+		return resp, nil
+	})
+}
+
+// countingReadCloser 统计读取的字节数
+type countingReadCloser struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n.Add(int64(n))
+	return n, err
+}
+
+// trackedBody 在读到 EOF 或关闭时触发 onDone
+type trackedBody struct {
+	countingReadCloser
+	onDone func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.countingReadCloser.Read(p)
+	if errors.Is(err, io.EOF) {
+		b.onDone()
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	err := b.countingReadCloser.Close()
+	b.onDone()
+	return err
+}
@@ -0,0 +1,54 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRequestBuilderSendReportsTransferStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = io.WriteString(w, strings.Repeat("x", 4096))
+	}))
+	defer server.Close()
+
+	var callbacks atomic.Int32
+	var reported TransferStats
+	client := New(WithOnTransferStats(func(req *http.Request, stats TransferStats) {
+		callbacks.Add(1)
+		reported = stats
+	}))
+
+	resp, err := client.POST(server.URL).SetRawBody([]byte(strings.Repeat("y", 100))).Send()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := io.ReadAll(resp.Body); err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	resp.Body.Close()
+
+	stats := resp.TransferStats()
+	if !stats.Completed {
+		t.Fatal("Completed = false, want true after body is drained")
+	}
+	if stats.BytesSent != 100 || stats.BytesReceived != 4096 {
+		t.Fatalf("bytes sent/received = %d/%d, want 100/4096", stats.BytesSent, stats.BytesReceived)
+	}
+	if stats.TimeToFirstByte <= 0 || stats.Duration < stats.TimeToFirstByte {
+		t.Fatalf("ttfb = %v, duration = %v, want 0 < ttfb <= duration", stats.TimeToFirstByte, stats.Duration)
+	}
+	if stats.Throughput() <= 0 {
+		t.Fatalf("Throughput() = %v, want > 0", stats.Throughput())
+	}
+	if got := callbacks.Load(); got != 1 {
+		t.Fatalf("OnTransferStats calls = %d, want 1", got)
+	}
+	if reported != stats {
+		t.Fatalf("callback stats = %+v, want %+v", reported, stats)
+	}
+}
@@ -15,7 +15,74 @@ import (
 )
 
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
-	var finalRT http.RoundTripper = c.transport
+	base := c.settings() // 本次请求使用同一份配置快照
+	settings := base
+	if p := requestProfile(req.Context()); p != nil {
+		settings = p.apply(settings)
+	}
+	if t, ok := req.Context().Value(requestTimeoutsKey{}).(requestTimeouts); ok {
+		settings = t.apply(settings)
+	}
+	if o, ok := contextOptions(req.Context()); ok {
+		settings = o.apply(settings)
+		req = o.applyHeaders(req)
+	}
+
+	// 调用链在配置变化时预先构建; 配置组等请求级覆盖改变了配置时, 为本次请求单独构建
+	finalRT := base.chain
+	if settings != base || finalRT == nil {
+		finalRT = c.buildChain(settings)
+	}
+
+	labeled := c.metrics.labeled(req.Context())
+	c.metrics.requests.Add(1)
+	if labeled != nil {
+		labeled.requests.Add(1)
+	}
+	log := attemptLogFrom(req.Context())
+	var start time.Time
+	if log != nil {
+		*log = AttemptLog{}
+		start = c.clock.Now()
+	}
+	resp, err := finalRT.RoundTrip(req)
+	if log != nil && len(log.Attempts) == 0 {
+		// 未经过重试层 (未启用重试或命中缓存) 时只有一次尝试
+		log.Attempts = []Attempt{newAttempt(resp, err, c.clock.Now().Sub(start))}
+	}
+	if err != nil {
+		err = c.wrapError(err)
+		c.metrics.errors.Add(1)
+		if labeled != nil {
+			labeled.errors.Add(1)
+		}
+	}
+	return resp, err
+}
+
+// buildChain 按配置快照构建完整的 RoundTripper 调用链, 由内到外依次为
+// 基础 Transport, 连接事件, 连接池/传输/流量统计, Server-Timing, Alt-Svc, 限速, robots.txt, 中间件, 日志, 分段超时, 重试, 超时, 缓存, 审计
+func (c *Client) buildChain(settings *liveConfig) http.RoundTripper {
+	var baseRT http.RoundTripper = RoundTripperFunc(c.baseRoundTrip)
+	if c.connHooks != nil {
+		baseRT = c.connHooksRoundTripper(baseRT)
+	}
+	if c.poolStats.enabled.Load() {
+		baseRT = c.poolStatsRoundTripper(baseRT)
+	}
+	var finalRT http.RoundTripper = c.trafficRoundTripper(c.transferStatsRoundTripper(baseRT))
+	if c.onServerTiming != nil {
+		finalRT = c.serverTimingRoundTripper(finalRT)
+	}
+	if c.altSvc != nil {
+		finalRT = c.altSvcRoundTripper(finalRT)
+	}
+	if settings.rateLimiter != nil {
+		finalRT = c.rateLimitRoundTripper(settings.rateLimiter, finalRT)
+	}
+	if c.robots != nil {
+		finalRT = c.robotsRoundTripper(c.robots, finalRT)
+	}
 
 	// 逆序应用，使得第一个中间件在最外层
 	for i := len(c.middlewares) - 1; i >= 0; i-- {
@@ -26,24 +93,52 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		finalRT = c.logRoundTripper(finalRT)
 	}
 
+	// 请求级分段超时位于重试之内, 每次尝试单独计时
+	finalRT = c.requestTimeoutRoundTripper(finalRT)
+
 	// 只有在配置了重试次数时才应用
-	if c.retryOpts.MaxAttempts > 0 {
-		finalRT = c.retryRoundTripper(finalRT)
+	if settings.retryOpts.MaxAttempts > 0 {
+		finalRT = c.retryRoundTripper(&settings.retryOpts, finalRT)
+	}
+
+	if settings.timeout > 0 {
+		finalRT = c.timeoutRoundTripper(settings.timeout, finalRT)
+	}
+
+	// 缓存命中时不经过超时, 重试与限速
+	if c.cache != nil {
+		finalRT = c.cacheRoundTripper(c.cache, finalRT)
 	}
 
-	return finalRT.RoundTrip(req)
+	if c.audit != nil {
+		finalRT = c.auditRoundTripper(c.audit, finalRT)
+	}
+	return finalRT
 }
 
-// logRoundTripper 是一个内部中间件，用于在请求发送前记录日志
+// baseRoundTrip 选择请求使用的 Transport: 请求级连接覆盖, 按主机分片或默认 Transport
+func (c *Client) baseRoundTrip(req *http.Request) (*http.Response, error) {
+	if o, ok := requestConnectOverride(req); ok {
+		return c.overrides.transportFor(o.key()).RoundTrip(req)
+	}
+	if c.shards != nil {
+		return c.shards.RoundTrip(req)
+	}
+	return c.transport.RoundTrip(req)
+}
+
+// logRoundTripper 是一个内部中间件，在每次尝试完成后按日志级别记录
 func (c *Client) logRoundTripper(next http.RoundTripper) http.RoundTripper {
 	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
-		c.logRequest(req) // 在请求发送前记录
-		return next.RoundTrip(req)
+		start := c.clock.Now()
+		resp, err := next.RoundTrip(req)
+		c.logAttempt(req, resp, err, c.clock.Now().Sub(start))
+		return resp, err
 	})
 }
 
 // retryRoundTripper 是一个内部中间件，用于实现请求的重试逻辑
-func (c *Client) retryRoundTripper(next http.RoundTripper) http.RoundTripper {
+func (c *Client) retryRoundTripper(opts *RetryOptions, next http.RoundTripper) http.RoundTripper {
 	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
 		var bodyReaderFunc func() (io.ReadCloser, error) // 用于缓存和重置 Body
 
@@ -54,28 +149,32 @@ func (c *Client) retryRoundTripper(next http.RoundTripper) http.RoundTripper {
 
 		var lastResp *http.Response
 		var lastErr error
+		var prevDelay time.Duration // 上一次重试的等待时间, 供 decorrelated jitter 使用
+		var buf [4]Attempt
+		attempts := buf[:0] // 尝试记录, 次数较少时不分配内存
+		var backoff time.Duration
 
-		for attempt := 0; attempt <= c.retryOpts.MaxAttempts; attempt++ {
+		for attempt := 0; attempt <= opts.MaxAttempts; attempt++ {
 
 			if attempt > 0 {
 				if bodyReaderFunc == nil {
-					// 如果没有 bodyReaderFunc，意味着原始 Body 不可重读，
-					// 且已在第一次尝试中被消耗，所以无法重试带 Body 的请求
-					// 在这种情况下，我们应该在第一次失败后立即停止
-					// shouldRetry 逻辑应该考虑到这一点
-					// 这里我们直接中断重试
-					break
-				}
-
-				// 从 bodyReaderFunc 创建一个新的 Body
-				newBody, err := bodyReaderFunc()
-				if err != nil {
-					if lastResp != nil {
-						lastResp.Body.Close()
+					// 没有 Body 的幂等请求 (例如 GET) 可以直接重发;
+					// 其他情况下原始 Body 不可重读, 且已在第一次尝试中被消耗,
+					// 非幂等请求重发也可能产生副作用, 这里我们直接中断重试
+					if (req.Body != nil && req.Body != http.NoBody) || !isIdempotentMethod(req.Method) {
+						break
 					}
-					return nil, fmt.Errorf("httpc: failed to get request body for retry attempt %d: %w", attempt, err) // 英文错误
+				} else {
+					// 从 bodyReaderFunc 创建一个新的 Body
+					newBody, err := bodyReaderFunc()
+					if err != nil {
+						if lastResp != nil {
+							lastResp.Body.Close()
+						}
+						return c.finishAttempts(req, attempts, backoff, nil, fmt.Errorf("httpc: failed to get request body for retry attempt %d: %w", attempt, err)) // 英文错误
+					}
+					req.Body = newBody
 				}
-				req.Body = newBody
 			}
 
 			// 检查上下文是否已取消
@@ -85,30 +184,39 @@ func (c *Client) retryRoundTripper(next http.RoundTripper) http.RoundTripper {
 				if lastResp != nil {
 					lastResp.Body.Close()
 				}
-				return nil, c.wrapError(req.Context().Err())
+				return c.finishAttempts(req, attempts, backoff, nil, req.Context().Err())
 			default:
 			}
 
+			if attempt > 0 {
+				c.metrics.retries.Add(1)
+				if m := c.metrics.labeled(req.Context()); m != nil {
+					m.retries.Add(1)
+				}
+			}
 			// 调用链中的下一个 RoundTripper (可能是日志、Padding或其他中间件)
+			start := c.clock.Now()
 			resp, err := next.RoundTrip(req)
 			lastResp, lastErr = resp, err
+			attempts = append(attempts, newAttempt(resp, err, c.clock.Now().Sub(start)))
 
 			// 判断是否需要重试
-			if !c.shouldRetry(resp, err) {
+			if !c.shouldRetry(opts, resp, err) {
 				break // 不需要重试，跳出循环
 			}
 
 			// 如果是最后一次尝试，则不再重试，直接返回结果
-			if attempt >= c.retryOpts.MaxAttempts {
+			if attempt >= opts.MaxAttempts {
 				lastErr = ErrMaxRetriesExceeded
+				if err != nil {
+					lastErr = fmt.Errorf("%w: %w", ErrMaxRetriesExceeded, err)
+				}
 				break
 			}
 
 			// 计算重试延迟
-			delay := c.calculateRetryAfter(resp)
-			if delay <= 0 {
-				delay = c.calculateExponentialBackoff(attempt, c.retryOpts.Jitter)
-			}
+			delay := c.retryDelay(opts, attempt, prevDelay, resp)
+			prevDelay = delay
 
 			// 在重试前，确保关闭当前失败的响应体以复用连接
 			if resp != nil && resp.Body != nil {
@@ -116,25 +224,32 @@ func (c *Client) retryRoundTripper(next http.RoundTripper) http.RoundTripper {
 				resp.Body.Close()
 			}
 
-			// 等待延迟，同时监听上下文取消
+			// 等待延迟，同时监听上下文取消; 记录实际等待的时长
+			waitStart := c.clock.Now()
 			select {
 			case <-req.Context().Done():
-				return nil, c.wrapError(req.Context().Err())
-			case <-time.After(delay):
-				// 继续下一次循环
+				waited := c.clock.Now().Sub(waitStart)
+				attempts[len(attempts)-1].Backoff = waited
+				backoff += waited
+				return c.finishAttempts(req, attempts, backoff, nil, req.Context().Err())
+			case <-c.clock.After(delay):
+				attempts[len(attempts)-1].Backoff = delay
+				backoff += delay
 			}
 		}
 
-		if lastErr != nil {
-			return lastResp, c.wrapError(lastErr)
-		}
-		return lastResp, nil
+		return c.finishAttempts(req, attempts, backoff, lastResp, lastErr)
 	})
 }
 
-// 记录请求日志, 使用 strings.Builder 和 sync.Pool 优化性能
-func (c *Client) logRequest(req *http.Request) {
-	if c.dumpLog == nil {
+// logAttempt 按日志级别记录一次尝试, 使用 strings.Builder 和 sync.Pool 优化性能
+func (c *Client) logAttempt(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	dumpLog, level := c.dumpLog, c.logLevel
+	if dumpLog == nil {
+		return
+	}
+	failed := err != nil || resp == nil || resp.StatusCode >= 500
+	if (level <= LogLevelError && !failed) || (level == LogLevelWarn && !failed && resp.StatusCode < 400) {
 		return
 	}
 
@@ -144,29 +259,43 @@ func (c *Client) logRequest(req *http.Request) {
 		stringsBuilderPool.Put(sb)
 	}()
 
-	sb.WriteString("\n[HTTP Request Log]\n")
-	sb.WriteString("-------------------------------\n")
-	sb.WriteString("Time       : ")
-	sb.WriteString(time.Now().Format("2006-01-02 15:04:05\n"))
-	sb.WriteString("Method     : ")
+	// 摘要行: GET https://example.com/path -> 200 OK (12ms)
 	sb.WriteString(req.Method)
-	sb.WriteByte('\n')
-	sb.WriteString("URL        : ")
-	sb.WriteString(req.URL.String())
-	sb.WriteByte('\n')
-	sb.WriteString("Host       : ")
-	sb.WriteString(req.URL.Host)
-	sb.WriteByte('\n')
-	sb.WriteString("Protocol   : ")
-	sb.WriteString(req.Proto)
-	sb.WriteByte('\n')
-	sb.WriteString("Transport  :\n")
-	getTransportDetails(c.transport, sb)
-	sb.WriteString("Headers    :\n")
-	formatHeaders(req.Header, sb)
-	sb.WriteString("-------------------------------\n")
-
-	c.dumpLog(req.Context(), sb.String())
+	sb.WriteByte(' ')
+	sb.WriteString(req.URL.Redacted())
+	sb.WriteString(" -> ")
+	switch {
+	case err != nil:
+		sb.WriteString("error: ")
+		sb.WriteString(err.Error())
+	case resp != nil:
+		sb.WriteString(resp.Status)
+	}
+	sb.WriteString(" (")
+	sb.WriteString(elapsed.String())
+	sb.WriteString(")\n")
+
+	if level >= LogLevelDebug {
+		sb.WriteString("Request Headers  :\n")
+		formatHeaders(req.Header, sb)
+		if resp != nil {
+			sb.WriteString("Response Headers :\n")
+			formatHeaders(resp.Header, sb)
+		}
+	}
+	if level >= LogLevelTrace {
+		sb.WriteString("Protocol         : ")
+		sb.WriteString(req.Proto)
+		if resp != nil {
+			sb.WriteString(" -> ")
+			sb.WriteString(resp.Proto)
+		}
+		sb.WriteByte('\n')
+		sb.WriteString("Transport        :\n")
+		getTransportDetails(c.transport, sb)
+	}
+
+	dumpLog(req.Context(), strings.TrimSuffix(sb.String(), "\n"))
 }
 
 // 获取 Transport 的详细信息
@@ -222,28 +351,62 @@ func formatHeaders(headers http.Header, sb *strings.Builder) {
 	}
 }
 
-// 解析 Retry-After 头部，仅在状态码为 429 时调用 (保持原函数不变)
-func (c *Client) calculateRetryAfter(resp *http.Response) time.Duration {
-	if resp == nil {
-		return 0
+// retryDelay 计算第 attempt 次重试前的等待时间, prev 为上一次的等待时间
+// 状态码配置了 StatusDelays 时优先使用 Retry-After, 否则按该状态码的策略退避
+func (c *Client) retryDelay(opts *RetryOptions, attempt int, prev time.Duration, resp *http.Response) time.Duration {
+	if resp != nil {
+		if policy, ok := opts.StatusDelays[resp.StatusCode]; ok {
+			if delay, ok := c.retryAfter(opts, resp); ok {
+				return delay
+			}
+			if policy.Fixed {
+				return policy.BaseDelay
+			}
+			maxDelay := policy.MaxDelay
+			if maxDelay <= 0 {
+				maxDelay = opts.MaxDelay
+			}
+			return c.backoff(opts, policy.BaseDelay, maxDelay, attempt, prev, opts.Jitter)
+		}
 	}
-	retryAfter := resp.Header.Get("Retry-After")
-	if retryAfter != "" {
-		if delay, err := parseRetryAfter(retryAfter); err == nil {
+
+	if resp != nil {
+		if delay, ok := c.retryAfter(opts, resp); ok {
 			return delay
 		}
 	}
-	return c.retryOpts.BaseDelay
+	return c.backoff(opts, opts.BaseDelay, opts.MaxDelay, attempt, prev, opts.Jitter)
 }
 
-// 解析 Retry-After 的具体实现 (保持原函数不变)
-func parseRetryAfter(retryAfter string) (time.Duration, error) {
+// retryAfter 解析响应的 Retry-After 并按 MaxRetryAfter 截断, 避免服务端让客户端长时间休眠
+func (c *Client) retryAfter(opts *RetryOptions, resp *http.Response) (time.Duration, bool) {
+	retryAfter := resp.Header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0, false
+	}
+	delay, err := parseRetryAfter(retryAfter, c.clock.Now())
+	if err != nil {
+		return 0, false
+	}
+
+	limit := opts.MaxRetryAfter
+	if limit == 0 {
+		limit = defaultMaxRetryAfter
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	return delay, true
+}
+
+// 解析 Retry-After 的具体实现, HTTP 日期格式相对 now 计算延迟
+func parseRetryAfter(retryAfter string, now time.Time) (time.Duration, error) {
 	if seconds, err := time.ParseDuration(retryAfter + "s"); err == nil {
 		return seconds, nil
 	}
 
 	if retryTime, err := http.ParseTime(retryAfter); err == nil {
-		delay := time.Until(retryTime)
+		delay := retryTime.Sub(now)
 		if delay > 0 {
 			return delay, nil
 		}
@@ -252,52 +415,74 @@ func parseRetryAfter(retryAfter string) (time.Duration, error) {
 	return 0, errors.New("invalid Retry-After value")
 }
 
-// 指数退避计算，启用 jitter 时在 [0.5, 1.5) 区间内随机扰动。
-func (c *Client) calculateExponentialBackoff(attempt int, jitter bool) time.Duration {
-	delay := min(c.retryOpts.BaseDelay*time.Duration(1<<uint(attempt)), c.retryOpts.MaxDelay)
+// backoff 按给定的基础延迟与上限计算指数退避
+// prev 为上一次的等待时间, 仅 JitterDecorrelated 使用; 为 0 时以上一轮的指数退避值近似
+func (c *Client) backoff(opts *RetryOptions, baseDelay, maxDelay time.Duration, attempt int, prev time.Duration, jitter bool) time.Duration {
+	delay := min(baseDelay*time.Duration(1<<uint(attempt)), maxDelay)
+	if !jitter {
+		return delay
+	}
 
-	if jitter {
+	switch opts.JitterStrategy {
+	case JitterFull:
+		// AWS "Full Jitter": sleep = rand[0, min(cap, base*2^attempt))
+		delay = time.Duration(float64(delay) * c.randomFloat64())
+	case JitterDecorrelated:
+		// AWS "Decorrelated Jitter": sleep = min(cap, rand[base, prev*3))
+		if prev <= 0 {
+			prev = baseDelay * time.Duration(1<<uint(max(attempt-1, 0)))
+		}
+		upper := max(prev*3, baseDelay)
+		delay = baseDelay + time.Duration(float64(upper-baseDelay)*c.randomFloat64())
+	default:
 		randomFactor := 0.5 + c.randomFloat64()
 		delay = time.Duration(float64(delay) * randomFactor)
-		if delay > c.retryOpts.MaxDelay {
-			return c.retryOpts.MaxDelay
-		}
-		if delay < 0 {
-			return 0
-		}
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+	if delay < 0 {
+		return 0
 	}
 	return delay
 }
 
-// 错误包装 (保持原函数不变)
+// wrapError 为网络错误附加对应类别的哨兵错误, 超时错误额外包装 ErrRequestTimeout
 func (c *Client) wrapError(err error) error {
-	switch {
-	case errors.Is(err, context.DeadlineExceeded):
-		return fmt.Errorf("%w: %v", ErrRequestTimeout, err)
-	default:
-		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-			return fmt.Errorf("%w: %v", ErrRequestTimeout, err)
-		}
+	if err == nil {
+		return nil
+	}
+	class := Classify(err)
+	sentinel := classSentinel(class, err)
+	if sentinel == nil || errors.Is(err, sentinel) {
 		return err
 	}
+	if netErr, ok := err.(net.Error); errors.Is(err, context.DeadlineExceeded) || (ok && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrRequestTimeout, err)
+	}
+	return &classifiedError{err: err, sentinel: sentinel}
+}
+
+// isIdempotentMethod 判断请求方法是否幂等 (RFC 9110 9.2.2)
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
 }
 
-// 重试条件判断 (保持原函数不变)
-func (c *Client) shouldRetry(resp *http.Response, err error) bool {
+// 重试条件判断: 错误按类别判断, 响应按状态码判断
+func (c *Client) shouldRetry(opts *RetryOptions, resp *http.Response, err error) bool {
 	if err != nil {
-		return isNetworkError(err)
+		return retryOnError(opts, err)
 	}
 
-	for _, status := range c.retryOpts.RetryStatuses {
+	for _, status := range opts.RetryStatuses {
 		if resp != nil && resp.StatusCode == status { // 增加 resp != nil 判断
 			return true
 		}
 	}
 	return false
 }
-
-// 辅助函数 (保持原函数不变)
-func isNetworkError(err error) bool {
-	var netErr net.Error
-	return errors.As(err, &netErr)
-}
@@ -0,0 +1,101 @@
+package httpc
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// defaultMaxTransportShards 是未指定上限时保留的最大分片数
+const defaultMaxTransportShards = 64
+
+// WithTransportShards 为每个主机 (或主机分组) 维护独立的 http.Transport 分片
+// 避免单个缓慢或超大的上游占满共享的 MaxIdleConns 预算或阻塞其他主机的请求.
+// group 将 req.URL.Host 映射为分片键, 为 nil 时每个主机一个分片;
+// 分片数超过 maxShards (<=0 时为 64) 时淘汰最久未使用的分片并关闭其空闲连接.
+// 分片在首次使用时从客户端 Transport 克隆, 因此会继承其他 Option 的配置
+func WithTransportShards(maxShards int, group func(host string) string) Option {
+	return func(c *Client) {
+		if maxShards <= 0 {
+			maxShards = defaultMaxTransportShards
+		}
+		c.shards = newTransportShards(maxShards, group, func(string) *http.Transport {
+			return c.transport.Clone()
+		})
+	}
+}
+
+// newTransportShards 创建分片集合, newTransport 为分片键创建新的 Transport
+func newTransportShards(maxShards int, group func(host string) string, newTransport func(key string) *http.Transport) *transportShards {
+	return &transportShards{
+		maxShards:    maxShards,
+		group:        group,
+		newTransport: newTransport,
+		lru:          list.New(),
+		entries:      make(map[string]*list.Element),
+	}
+}
+
+// transportShards 是一个按分片键路由的 RoundTripper, 使用 LRU 限制分片数量
+type transportShards struct {
+	maxShards    int
+	group        func(host string) string
+	newTransport func(key string) *http.Transport
+
+	mu      sync.Mutex
+	lru     *list.List               // 元素为 *transportShard, 队首为最近使用
+	entries map[string]*list.Element // 分片键 -> LRU 元素
+}
+
+type transportShard struct {
+	key       string
+	transport *http.Transport
+}
+
+// RoundTrip 实现了 http.RoundTripper 接口
+func (s *transportShards) RoundTrip(req *http.Request) (*http.Response, error) {
+	return s.transportFor(req.URL.Host).RoundTrip(req)
+}
+
+// transportFor 返回分片键对应的 Transport, 不存在时创建并按需淘汰
+func (s *transportShards) transportFor(host string) *http.Transport {
+	key := host
+	if s.group != nil {
+		key = s.group(host)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.lru.MoveToFront(elem)
+		return elem.Value.(*transportShard).transport
+	}
+
+	shard := &transportShard{key: key, transport: s.newTransport(key)}
+	s.entries[key] = s.lru.PushFront(shard)
+	for s.lru.Len() > s.maxShards {
+		oldest := s.lru.Back()
+		evicted := s.lru.Remove(oldest).(*transportShard)
+		delete(s.entries, evicted.key)
+		// 进行中的请求不受影响, 仅关闭空闲连接
+		evicted.transport.CloseIdleConnections()
+	}
+	return shard.transport
+}
+
+// CloseIdleConnections 关闭所有分片的空闲连接
+func (s *transportShards) CloseIdleConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		elem.Value.(*transportShard).transport.CloseIdleConnections()
+	}
+}
+
+// len 返回当前分片数量
+func (s *transportShards) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
@@ -0,0 +1,56 @@
+package httpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithTransportShardsSeparatesHostsAndEvicts(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	first := httptest.NewServer(handler)
+	defer first.Close()
+	second := httptest.NewServer(handler)
+	defer second.Close()
+
+	client := New(WithTransportShards(1, nil))
+	for _, target := range []string{first.URL, second.URL} {
+		if _, err := client.GET(target).Text(); err != nil {
+			t.Fatalf("Text(%s) error = %v", target, err)
+		}
+	}
+	if got := client.shards.len(); got != 1 {
+		t.Fatalf("shards = %d, want 1 after LRU eviction", got)
+	}
+
+	host := strings.TrimPrefix(second.URL, "http://")
+	shard := client.shards.transportFor(host)
+	if shard == client.transport {
+		t.Fatal("shard transport is the shared transport, want a clone")
+	}
+	if shard != client.shards.transportFor(host) {
+		t.Fatal("transportFor() returned a different transport for the same host")
+	}
+}
+
+func TestWithTransportShardsGroupsHosts(t *testing.T) {
+	client := New(WithTransportShards(0, func(host string) string {
+		if strings.HasSuffix(host, ".internal") {
+			return "internal"
+		}
+		return host
+	}))
+
+	a := client.shards.transportFor("a.internal")
+	b := client.shards.transportFor("b.internal")
+	c := client.shards.transportFor("example.com")
+	if a != b {
+		t.Fatal("hosts in the same group got different transports")
+	}
+	if a == c {
+		t.Fatal("hosts in different groups share a transport")
+	}
+}
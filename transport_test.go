@@ -0,0 +1,145 @@
+package httpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubMiddleware 直接返回空响应, 不发出网络请求
+func stubMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: http.NoBody, Request: req}, nil
+	})
+}
+
+func passMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return next.RoundTrip(req)
+	})
+}
+
+func TestChainRebuiltOnChange(t *testing.T) {
+	client := New(WithMiddleware(stubMiddleware))
+	chain := client.settings().chain
+	if chain == nil {
+		t.Fatal("chain = nil after New")
+	}
+
+	var logs int
+	client.SetDumpLogFunc(func(ctx context.Context, log string) { logs++ })
+	if client.settings().chain == nil {
+		t.Fatal("chain = nil after SetDumpLogFunc")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if logs != 1 {
+		t.Fatalf("logs = %d, want 1", logs)
+	}
+
+	before := client.settings()
+	client.SetTimeout(time.Second)
+	if after := client.settings(); after == before || after.chain == nil {
+		t.Fatal("SetTimeout did not publish a rebuilt chain")
+	}
+}
+
+func benchmarkClientDo(b *testing.B, client *Client, ctx context.Context) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		resp, err := client.Do(req)
+		if err != nil {
+			b.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func newBenchmarkClient() *Client {
+	return New(
+		WithMiddleware(passMiddleware, passMiddleware, passMiddleware, stubMiddleware),
+		WithDumpLogFunc(func(ctx context.Context, log string) {}),
+		WithRateLimit(RateLimit{RequestsPerSecond: 1e9, Burst: 1 << 30}),
+		WithTimeout(time.Minute),
+	)
+}
+
+// BenchmarkClientDo 使用预先构建的调用链
+func BenchmarkClientDo(b *testing.B) {
+	benchmarkClientDo(b, newBenchmarkClient(), context.Background())
+}
+
+// BenchmarkClientDoPerRequestChain 通过请求级覆盖强制每次请求重新构建调用链, 作为对照
+func BenchmarkClientDoPerRequestChain(b *testing.B) {
+	benchmarkClientDo(b, newBenchmarkClient(), ContextWithOptions(context.Background(), NoRetry()))
+}
+
+func TestLogLevels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		w.Header().Set("X-Reply", "yes")
+		w.WriteHeader(code)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		level LogLevel
+		logs  int    // 200, 404, 500 三个请求产生的日志条数
+		want  string // 每条日志都包含的内容
+		skip  string // 不应出现的内容
+	}{
+		{LogLevelError, 1, "500 Internal Server Error", "X-Reply"},
+		{LogLevelWarn, 2, " -> ", "X-Reply"},
+		{LogLevelInfo, 3, " -> ", "X-Reply"},
+		{LogLevelDebug, 3, "X-Reply: yes", "Transport"},
+		{LogLevelTrace, 3, "MaxIdleConns", ""},
+	}
+	for _, tt := range tests {
+		var logs []string
+		client := New(
+			WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+			WithLogLevel(tt.level),
+			WithDumpLogFunc(func(ctx context.Context, log string) { logs = append(logs, log) }),
+		)
+		for _, code := range []string{"200", "404", "500"} {
+			if _, err := client.GET(srv.URL + "/" + code).Bytes(); err != nil && code == "200" {
+				t.Fatalf("%v: Bytes() error = %v", tt.level, err)
+			}
+		}
+		if len(logs) != tt.logs {
+			t.Fatalf("%v: logs = %d, want %d: %q", tt.level, len(logs), tt.logs, logs)
+		}
+		for _, log := range logs {
+			if !strings.Contains(log, tt.want) || (tt.skip != "" && strings.Contains(log, tt.skip)) {
+				t.Fatalf("%v: log = %q, want %q without %q", tt.level, log, tt.want, tt.skip)
+			}
+		}
+	}
+}
+
+func TestLogLevelErrorIncludesTransportError(t *testing.T) {
+	var logs []string
+	client := New(
+		WithRetryOptions(RetryOptions{MaxAttempts: 0}),
+		WithLogLevel(LogLevelError),
+		WithDumpLogFunc(func(ctx context.Context, log string) { logs = append(logs, log) }),
+	)
+	if _, err := client.GET("http://" + closedAddr(t) + "/x").Bytes(); err == nil {
+		t.Fatal("request to closed port succeeded")
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "-> error: ") {
+		t.Fatalf("logs = %q, want one error line", logs)
+	}
+}
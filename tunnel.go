@@ -0,0 +1,153 @@
+package httpc
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrNoProxy 表示客户端没有为目标地址配置代理, Tunnel 无法建立隧道
+var ErrNoProxy = errors.New("httpc: no proxy configured")
+
+// Tunnel 通过客户端配置的代理建立到 addr ("host:port") 的原始 TCP 连接, 用于经由同一代理运行非 HTTP 协议.
+// HTTP/HTTPS 代理发送 CONNECT 请求 (代理 URL 中的用户信息作为 Proxy-Authorization), SOCKS5 代理直接拨号;
+// 代理由 https://addr 的请求选择, 因此环境变量代理使用 HTTPS_PROXY 与 NO_PROXY. 没有代理时返回 ErrNoProxy,
+// 代理拒绝时返回 HTTPError. ctx 只作用于隧道建立过程, 返回的连接由调用方关闭
+func (c *Client) Tunnel(ctx context.Context, addr string) (net.Conn, error) {
+	if dial := c.settings().proxyDial; dial != nil {
+		conn, err := dial(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrProxy, err)
+		}
+		return conn, nil
+	}
+
+	target := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Scheme: "https", Host: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	proxyURL, err := c.transport.Proxy(target.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoProxy, addr)
+	}
+
+	conn, err := c.directDial(ctx, "tcp", proxyAddr(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProxy, err)
+	}
+	// ctx 取消时中断正在进行的握手
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+	conn, err = c.connectTunnel(ctx, conn, proxyURL, addr)
+	if !stop() {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrProxy, err)
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// connectTunnel 在到代理的连接上完成 (可选的) TLS 握手与 CONNECT 请求, 失败时关闭连接
+func (c *Client) connectTunnel(ctx context.Context, conn net.Conn, proxyURL *url.URL, addr string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if proxyURL.Scheme == "https" {
+		var config *tls.Config
+		if c.transport.TLSClientConfig != nil {
+			config = c.transport.TLSClientConfig.Clone()
+		} else {
+			config = &tls.Config{}
+		}
+		config.ServerName = proxyURL.Hostname()
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: c.transport.ProxyConnectHeader.Clone(),
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if c.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if user := proxyURL.User; user != nil {
+		password, _ := user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := c.errorResponse(resp)
+		conn.Close()
+		return nil, err
+	}
+	if reader.Buffered() > 0 {
+		// 代理在响应之后立即转发的数据
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// proxyAddr 返回代理 URL 的 "host:port", 缺省端口按 scheme 选择
+func proxyAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// bufferedConn 在读取连接之前先返回已缓冲的数据
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
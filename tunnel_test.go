@@ -0,0 +1,111 @@
+package httpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newEchoServer 启动一个按行回显的 TCP 服务端
+func newEchoServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// newConnectProxy 启动一个要求 Basic 认证的 CONNECT 代理
+func newConnectProxy(t *testing.T, authorization string) *httptest.Server {
+	t.Helper()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Proxy-Authorization") != authorization {
+			w.Header().Set("Proxy-Authenticate", `Basic realm="proxy"`)
+			http.Error(w, "proxy auth required", http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			defer upstream.Close()
+			_, _ = io.Copy(upstream, conn)
+		}()
+		defer conn.Close()
+		_, _ = io.Copy(conn, upstream)
+	}))
+	t.Cleanup(proxy.Close)
+	return proxy
+}
+
+func TestTunnel(t *testing.T) {
+	target := newEchoServer(t)
+	// "user:secret" 的 Basic 认证
+	proxy := newConnectProxy(t, "Basic dXNlcjpzZWNyZXQ=")
+
+	proxyURL := "http://user:secret@" + proxy.Listener.Addr().String()
+	conn, err := New(WithHTTPProxy(proxyURL)).Tunnel(context.Background(), target)
+	if err != nil {
+		t.Fatalf("Tunnel() error = %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString() error = %v", err)
+	}
+	if line != "ping\n" {
+		t.Fatalf("echo = %q, want %q", line, "ping\n")
+	}
+}
+
+func TestTunnelErrors(t *testing.T) {
+	target := newEchoServer(t)
+	proxy := newConnectProxy(t, "Basic dXNlcjpzZWNyZXQ=")
+
+	_, err := New(WithHTTPProxy("http://"+proxy.Listener.Addr().String())).Tunnel(context.Background(), target)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusProxyAuthRequired {
+		t.Fatalf("Tunnel() error = %v, want 407 HTTPError", err)
+	}
+
+	client := New(WithTransport(&http.Transport{Proxy: func(*http.Request) (*url.URL, error) { return nil, nil }}))
+	if _, err := client.Tunnel(context.Background(), target); !errors.Is(err, ErrNoProxy) {
+		t.Fatalf("Tunnel() error = %v, want %v", err, ErrNoProxy)
+	}
+}
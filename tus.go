@@ -0,0 +1,217 @@
+package httpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// tus 协议相关常量
+const (
+	tusVersion          = "1.0.0"
+	defaultTusChunkSize = 4 << 20 // 4MB
+	defaultTusResumes   = 3
+)
+
+// ErrTusProtocol 表示服务端响应不符合 tus 协议
+var ErrTusProtocol = errors.New("httpc: tus protocol error")
+
+// TusOptions tus 上传配置
+type TusOptions struct {
+	ChunkSize  int64                       // 每个 PATCH 请求的最大字节数, 默认 4MB
+	MaxResumes int                         // PATCH 失败后通过 HEAD 恢复偏移量的最大次数, 默认 3
+	Header     http.Header                 // 附加到每个请求的 Header (例如鉴权)
+	OnProgress func(uploaded, total int64) // 进度回调, 每个分块完成后调用
+}
+
+// TusUploader 是基于 httpc 的 tus.io 可恢复上传客户端
+// 所有请求都经过 Client.Do, 因此共享客户端的重试策略与中间件链
+type TusUploader struct {
+	client   *Client
+	endpoint string
+	opts     TusOptions
+}
+
+// NewTusUploader 创建指向 tus 创建端点 (creation endpoint) 的上传器
+func (c *Client) NewTusUploader(endpoint string, opts TusOptions) *TusUploader {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultTusChunkSize
+	}
+	if opts.MaxResumes <= 0 {
+		opts.MaxResumes = defaultTusResumes
+	}
+	return &TusUploader{client: c, endpoint: endpoint, opts: opts}
+}
+
+// newRequest 创建带 Tus-Resumable 与附加 Header 的请求构建器
+func (u *TusUploader) newRequest(ctx context.Context, method, urlStr string) *RequestBuilder {
+	rb := u.client.NewRequestBuilder(method, urlStr).WithContext(ctx)
+	for k, v := range u.opts.Header {
+		for _, val := range v {
+			rb.AddHeader(k, val)
+		}
+	}
+	rb.SetHeader("Tus-Resumable", tusVersion)
+	return rb
+}
+
+// Create 创建上传资源, 返回上传 URL (Location)
+func (u *TusUploader) Create(ctx context.Context, size int64, metadata map[string]string) (string, error) {
+	rb := u.newRequest(ctx, http.MethodPost, u.endpoint).
+		SetHeader("Upload-Length", strconv.FormatInt(size, 10))
+	if len(metadata) > 0 {
+		rb.SetHeader("Upload-Metadata", encodeTusMetadata(metadata))
+	}
+
+	resp, err := rb.Execute()
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return "", u.client.errorResponse(resp)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("%w: create returned status %d", ErrTusProtocol, resp.StatusCode)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("%w: create response missing Location", ErrTusProtocol)
+	}
+	base, err := url.Parse(u.endpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s, error: %v", ErrInvalidURL, u.endpoint, err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid Location %q", ErrTusProtocol, location)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// Offset 通过 HEAD 请求查询服务端已接收的字节数
+func (u *TusUploader) Offset(ctx context.Context, location string) (int64, error) {
+	resp, err := u.newRequest(ctx, http.MethodHead, location).Execute()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, u.client.errorResponse(resp)
+	}
+	return parseTusOffset(resp)
+}
+
+// Upload 从服务端当前偏移量开始上传 r 中的剩余数据
+// PATCH 失败 (在客户端重试耗尽之后) 时会重新 HEAD 查询偏移量并继续, 最多 MaxResumes 次
+func (u *TusUploader) Upload(ctx context.Context, location string, r io.ReadSeeker, size int64) error {
+	offset, err := u.Offset(ctx, location)
+	if err != nil {
+		return err
+	}
+
+	chunk := make([]byte, min(u.opts.ChunkSize, max(size, 1)))
+	resumes := 0
+	for offset < size {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return fmt.Errorf("httpc: tus seek to offset %d: %w", offset, err)
+		}
+		n, err := io.ReadFull(r, chunk[:min(int64(len(chunk)), size-offset)])
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("httpc: tus read chunk at offset %d: %w", offset, err)
+		}
+
+		next, patchErr := u.patch(ctx, location, offset, chunk[:n])
+		if patchErr != nil {
+			if ctx.Err() != nil || resumes >= u.opts.MaxResumes {
+				return patchErr
+			}
+			resumes++
+			if offset, err = u.Offset(ctx, location); err != nil {
+				return errors.Join(patchErr, err)
+			}
+			continue
+		}
+		offset = next
+		if u.opts.OnProgress != nil {
+			u.opts.OnProgress(offset, size)
+		}
+	}
+	return nil
+}
+
+// UploadFile 创建上传资源并上传整个文件, 文件名写入 "filename" 元数据
+func (u *TusUploader) UploadFile(ctx context.Context, filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	location, err := u.Create(ctx, info.Size(), map[string]string{"filename": filepath.Base(filePath)})
+	if err != nil {
+		return "", err
+	}
+	return location, u.Upload(ctx, location, file, info.Size())
+}
+
+// patch 发送单个分块, 返回服务端确认的新偏移量
+func (u *TusUploader) patch(ctx context.Context, location string, offset int64, chunk []byte) (int64, error) {
+	resp, err := u.newRequest(ctx, http.MethodPatch, location).
+		SetHeader("Content-Type", "application/offset+octet-stream").
+		SetHeader("Upload-Offset", strconv.FormatInt(offset, 10)).
+		SetRawBody(chunk).
+		Execute()
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, u.client.errorResponse(resp)
+	}
+	next, err := parseTusOffset(resp)
+	if err != nil {
+		return 0, err
+	}
+	if next <= offset {
+		return 0, fmt.Errorf("%w: offset did not advance from %d", ErrTusProtocol, offset)
+	}
+	return next, nil
+}
+
+func parseTusOffset(resp *http.Response) (int64, error) {
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: invalid Upload-Offset %q", ErrTusProtocol, resp.Header.Get("Upload-Offset"))
+	}
+	return offset, nil
+}
+
+// encodeTusMetadata 按 tus 规范编码 Upload-Metadata: "key base64(value)" 以逗号分隔
+func encodeTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(metadata[k])))
+	}
+	return strings.Join(pairs, ",")
+}
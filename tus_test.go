@@ -0,0 +1,93 @@
+package httpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTusServer 是一个内存中的最小 tus 服务端, 第二个 PATCH 只接收一半数据后返回 500
+type fakeTusServer struct {
+	mu       sync.Mutex
+	data     []byte
+	length   int64
+	metadata string
+	patches  int
+}
+
+func (s *fakeTusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.length, _ = strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		s.metadata = r.Header.Get("Upload-Metadata")
+		w.Header().Set("Location", "/files/1")
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+	case http.MethodPatch:
+		offset, _ := strconv.Atoi(r.Header.Get("Upload-Offset"))
+		if offset != len(s.data) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		s.patches++
+		if s.patches == 2 {
+			s.data = append(s.data, body[:len(body)/2]...)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		s.data = append(s.data, body...)
+		w.Header().Set("Upload-Offset", strconv.Itoa(len(s.data)))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestTusUploaderResumesAfterInterruptedPatch(t *testing.T) {
+	tusServer := &fakeTusServer{}
+	server := httptest.NewServer(tusServer)
+	defer server.Close()
+
+	payload := []byte(strings.Repeat("0123456789", 10))
+	var progress []int64
+	uploader := New(WithRetryOptions(RetryOptions{})).NewTusUploader(server.URL+"/files/", TusOptions{
+		ChunkSize:  30,
+		OnProgress: func(uploaded, total int64) { progress = append(progress, uploaded) },
+	})
+
+	ctx := context.Background()
+	location, err := uploader.Create(ctx, int64(len(payload)), map[string]string{"filename": "a.txt"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if location != server.URL+"/files/1" {
+		t.Fatalf("location = %q, want resolved absolute URL", location)
+	}
+	if want := "filename " + base64.StdEncoding.EncodeToString([]byte("a.txt")); tusServer.metadata != want {
+		t.Fatalf("Upload-Metadata = %q, want %q", tusServer.metadata, want)
+	}
+
+	if err := uploader.Upload(ctx, location, bytes.NewReader(payload), int64(len(payload))); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if !bytes.Equal(tusServer.data, payload) {
+		t.Fatalf("server data = %q, want %q", tusServer.data, payload)
+	}
+	if last := progress[len(progress)-1]; last != int64(len(payload)) {
+		t.Fatalf("last progress = %d, want %d", last, len(payload))
+	}
+}
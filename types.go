@@ -7,9 +7,13 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/go-json-experiment/json"
 )
 
 // 默认配置常量
@@ -38,36 +42,99 @@ func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 // 它接收一个 http.RoundTripper (代表下一个处理器) 并返回一个新的 http.RoundTripper
 type MiddlewareFunc func(next http.RoundTripper) http.RoundTripper
 
-var bufferPool = sync.Pool{
-	New: func() any {
-		return bytes.NewBuffer(make([]byte, 0, defaultBufferSize))
-	},
-}
-
 var stringsBuilderPool = sync.Pool{
 	New: func() any {
 		return &strings.Builder{}
 	},
 }
 
+// XMLCharsetReader 将声明了非 UTF-8 编码的 XML 输入转换为 UTF-8
+// 签名与 xml.Decoder.CharsetReader 一致
+type XMLCharsetReader func(charset string, input io.Reader) (io.Reader, error)
+
 // DumpLogFunc 定义日志记录函数
 type DumpLogFunc func(ctx context.Context, log string)
 
+// LogLevel 是日志的详细程度, 每一级包含前一级的全部内容
+type LogLevel int
+
+const (
+	LogLevelError LogLevel = iota // 只记录失败的请求 (网络错误与 5xx)
+	LogLevelWarn                  // 另外记录 4xx 响应
+	LogLevelInfo                  // 每次尝试一行摘要: 方法, URL, 状态, 耗时 (默认)
+	LogLevelDebug                 // 另外记录请求头与响应头
+	LogLevelTrace                 // 另外记录协议与 Transport 详情
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelError:
+		return "error"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelTrace:
+		return "trace"
+	default:
+		return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
 // Client 主客户端结构
 type Client struct {
 	client        *http.Client
 	transport     *http.Transport
-	retryOpts     RetryOptions
 	randomFloat64 func() float64
 	bufferPool    BufferPool
 	userAgent     string
 	dumpLog       DumpLogFunc      // 日志记录函数
+	logLevel      LogLevel         // 日志详细程度
 	maxIdleConns  int              // 最大空闲连接数
 	bufferSize    int              // 缓冲池 buffer 大小
 	maxBufferPool int              // 最大缓冲池数量
-	timeout       time.Duration    // 默认请求超时时间 (可选)
 	middlewares   []MiddlewareFunc // 中间件链
 	dialer        *net.Dialer      // dialer实例
+	clock         Clock            // 重试, 退避与限速使用的时间源
+
+	dialContext dialFunc      // WithDialContext 设置的基础拨号函数
+	resolver    *customDialer // WithDNSResolver/WithResolver 设置的自定义解析拨号层
+
+	proxyProtocol *proxyProtocol // WithProxyProtocol 设置的 PROXY 协议头 (可选)
+
+	jsonMarshalOpts   []json.Options // JSON 编码选项
+	jsonUnmarshalOpts []json.Options // JSON 解码选项
+	jsonEngine        JSONEngine     // JSON 编解码实现, New 结束时为 nil 则使用 go-json-experiment
+
+	xmlCharsetReader XMLCharsetReader // XML 非 UTF-8 字符集转换器
+
+	decoders       map[string]Decoder // 按媒体类型注册的解码器
+	decodeFallback []string           // DecodeAuto 回退解码顺序
+
+	onTransferStats TransferStatsFunc // 传输统计回调
+	onServerTiming  ServerTimingFunc  // Server-Timing 回调
+	poolStats       *poolStats        // 按主机聚合的连接池统计
+	expvarName      string            // 发布的 expvar 名称, 由 expvarMu 保护
+	connHooks       *ConnectionHooks  // 连接生命周期回调 (可选)
+	metrics         clientMetrics     // 请求, 重试, 错误等累计计数器
+	audit           *auditor          // 审计记录 (可选)
+	cache           *httpCache        // HTTP 响应缓存 (可选)
+	robots          *robotsChecker    // robots.txt 检查 (可选)
+	shards          *transportShards  // 按主机分片的 Transport (可选)
+	overrides       *transportShards  // 按请求级连接覆盖 (ConnectTo, SetServerName) 分片的 Transport
+	serverName      string            // WithServerName 设置的 TLS SNI
+	altSvc          *altSvcCache      // Alt-Svc 替代服务缓存 (可选)
+
+	queryArrayStyle QueryArrayStyle // 默认的多值参数编码风格
+	baseURL         string          // WithBaseURL 设置的基础地址
+
+	profiles   map[string]*profile // DefineProfile 定义的命名配置组
+	profilesMu sync.RWMutex
+
+	live   atomic.Pointer[liveConfig] // 可运行时替换的配置 (重试, 限速, 超时, 代理)
+	liveMu sync.Mutex                 // 串行化 live 的写入
 }
 
 // RetryOptions 重试配置
@@ -77,34 +144,40 @@ type RetryOptions struct {
 	MaxDelay      time.Duration
 	RetryStatuses []int
 	Jitter        bool // 是否启用 Jitter 抖动
-}
 
-// BufferPool 缓冲池接口
-type BufferPool interface {
-	Get() *bytes.Buffer
-	Put(*bytes.Buffer)
-}
+	// JitterStrategy 抖动算法, 仅在 Jitter 为 true 时生效
+	JitterStrategy JitterStrategy
 
-// 默认缓冲池实现
-type defaultPool struct {
-	bufferSize int
-}
+	// RetryErrors 按错误类别覆盖是否重试, 未列出的类别使用默认策略:
+	// DNS 失败, 连接被拒绝, 连接重置, 超时及其他网络错误重试, TLS 错误不重试
+	RetryErrors map[ErrorClass]bool
 
-func newDefaultPool(bufferSize int) *defaultPool {
-	return &defaultPool{bufferSize: bufferSize}
+	// StatusDelays 按状态码覆盖退避策略, 例如 429 使用较长的固定等待, 502/503 使用快速指数退避
+	StatusDelays map[int]RetryDelay
+	// MaxRetryAfter 是服务端 Retry-After 的上限, 超过时截断; 0 使用默认值 (5 分钟), 负数表示不限制
+	MaxRetryAfter time.Duration
 }
 
-func (p *defaultPool) Get() *bytes.Buffer {
-	buf := bufferPool.Get().(*bytes.Buffer)
-	buf.Reset()
-	return buf
+// JitterStrategy 退避抖动算法
+type JitterStrategy int
+
+const (
+	JitterProportional JitterStrategy = iota // 在指数退避值的 [0.5, 1.5) 倍之间随机 (默认)
+	JitterFull                               // 在 [0, 指数退避值) 之间均匀随机
+	JitterDecorrelated                       // 在 [BaseDelay, 上一次等待时间 * 3) 之间随机
+)
+
+// RetryDelay 单个状态码的退避策略
+type RetryDelay struct {
+	BaseDelay time.Duration // 基础延迟
+	MaxDelay  time.Duration // 最大延迟, 0 时使用 RetryOptions.MaxDelay
+	Fixed     bool          // 为 true 时每次固定等待 BaseDelay, 否则指数退避
 }
 
-func (p *defaultPool) Put(buf *bytes.Buffer) {
-	if buf.Cap() > p.bufferSize*2 { // 防止内存泄漏，基于配置的 bufferSize
-		return
-	}
-	bufferPool.Put(buf)
+// BufferPool 缓冲池接口
+type BufferPool interface {
+	Get() *bytes.Buffer
+	Put(*bytes.Buffer)
 }
 
 // Option 配置选项类型
@@ -112,10 +185,10 @@ type Option func(*Client)
 
 // ProtocolsConfig 协议版本配置结构体
 type ProtocolsConfig struct {
-	Http1           bool // 是否启用 HTTP/1.1
-	Http2           bool // 是否启用 HTTP/2
-	Http2_Cleartext bool // 是否启用 H2C
-	ForceH2C        bool // 是否强制启用 H2C
+	Http1           bool `json:"http1" toml:"http1"`                     // 是否启用 HTTP/1.1
+	Http2           bool `json:"http2" toml:"http2"`                     // 是否启用 HTTP/2
+	Http2_Cleartext bool `json:"http2_cleartext" toml:"http2_cleartext"` // 是否启用 H2C
+	ForceH2C        bool `json:"force_h2c" toml:"force_h2c"`             // 是否强制启用 H2C
 }
 
 // RequestBuilder 用于构建请求的结构体
@@ -128,4 +201,18 @@ type RequestBuilder struct {
 	body             io.Reader
 	context          context.Context
 	noDefaultHeaders bool
+	accept           []string                      // Accept 声明的媒体类型, 作为 DecodeAuto 的回退顺序
+	contentLength    int64                         // 已知的请求体长度 (0 表示由标准库推断)
+	getBody          func() (io.ReadCloser, error) // 用于重试时重新生成请求体
+	openBody         func() (io.ReadCloser, error) // 构建请求时才打开的一次性请求体, 不用于重试
+	rawQuery         *string                       // SetRawQuery 设置的原样查询串, 替换 URL 中的查询串
+	rawQueryParams   []string                      // AddRawQueryParam 追加的原样 "k=v" 片段
+	queryArrayStyle  QueryArrayStyle               // 多值参数与 map 参数的编码风格
+	queryMaps        []queryMapParam               // SetQueryMap 设置的 map 参数, 按设置顺序输出
+	queryArrays      map[string]bool               // SetQueryValues 设置的数组参数
+	host             string                        // SetHostHeader 覆盖的 Host 头
+	connectOverride  connectOverride               // ConnectTo 与 SetServerName 的连接覆盖
+	timeouts         requestTimeouts               // 请求级的分段超时
+	profile          string                        // WithProfile 指定的命名配置组
+	schema           *JSONSchema                   // ValidateResponse 设置的响应体 Schema
 }
@@ -0,0 +1,204 @@
+package httpc
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/iox"
+)
+
+// sniffLen 是 http.DetectContentType 最多检查的字节数
+const sniffLen = 512
+
+// SetFileBody 以流式方式将文件作为请求体
+// 自动设置 Content-Length, 并按扩展名 (或内容嗅探) 设置 Content-Type.
+// 文件在发送请求时才打开, 请求重试时重新打开; 空文件不发送请求体
+func (rb *RequestBuilder) SetFileBody(filePath string) (*RequestBuilder, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("open file body error: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file body error: %w", err)
+	}
+
+	contentType, err := detectFileContentType(file)
+	if err != nil {
+		return nil, fmt.Errorf("detect file content type error: %w", err)
+	}
+
+	if info.Size() == 0 {
+		rb.setBody(http.NoBody)
+	} else {
+		rb.setBody(nil)
+		rb.contentLength = info.Size()
+		rb.getBody = func() (io.ReadCloser, error) {
+			return os.Open(filePath)
+		}
+	}
+	rb.header.Set("Content-Type", contentType)
+	return rb, nil
+}
+
+// detectFileContentType 按扩展名推断 Content-Type, 无法推断时嗅探文件头部
+// 嗅探后文件偏移会被重置到起始位置
+func detectFileContentType(file *os.File) (string, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(file.Name())); contentType != "" {
+		return contentType, nil
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// MultipartBuilder 用于构建 multipart/form-data 请求体
+type MultipartBuilder struct {
+	parts []multipartPart
+	err   error
+}
+
+type multipartPart struct {
+	fieldName string
+	fileName  string
+	value     string
+	reader    io.Reader
+	filePath  string
+}
+
+// NewMultipartBuilder 创建 MultipartBuilder 实例
+func NewMultipartBuilder() *MultipartBuilder {
+	return &MultipartBuilder{}
+}
+
+// AddField 添加普通表单字段
+func (mb *MultipartBuilder) AddField(name, value string) *MultipartBuilder {
+	mb.parts = append(mb.parts, multipartPart{fieldName: name, value: value})
+	return mb
+}
+
+// AddFile 添加文件字段, 内容从 r 中读取 (只能被读取一次, 因此包含此字段的请求不可重试)
+func (mb *MultipartBuilder) AddFile(fieldName, fileName string, r io.Reader) *MultipartBuilder {
+	mb.parts = append(mb.parts, multipartPart{fieldName: fieldName, fileName: fileName, reader: r})
+	return mb
+}
+
+// AddFileField 添加磁盘文件字段, 文件在发送时才被打开, Content-Type 自动检测
+func (mb *MultipartBuilder) AddFileField(fieldName, filePath string) *MultipartBuilder {
+	if _, err := os.Stat(filePath); err != nil && mb.err == nil {
+		mb.err = fmt.Errorf("multipart file field %q: %w", fieldName, err)
+	}
+	mb.parts = append(mb.parts, multipartPart{
+		fieldName: fieldName,
+		fileName:  filepath.Base(filePath),
+		filePath:  filePath,
+	})
+	return mb
+}
+
+// replayable 报告请求体能否被重新生成 (用于重试)
+func (mb *MultipartBuilder) replayable() bool {
+	for _, part := range mb.parts {
+		if part.reader != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// writeTo 将所有字段按顺序写入 multipart writer
+func (mb *MultipartBuilder) writeTo(mw *multipart.Writer) error {
+	for _, part := range mb.parts {
+		switch {
+		case part.filePath != "":
+			if err := writeMultipartFile(mw, part); err != nil {
+				return err
+			}
+		case part.reader != nil:
+			w, err := mw.CreatePart(multipartFileHeader(part.fieldName, part.fileName, "application/octet-stream"))
+			if err != nil {
+				return err
+			}
+			if _, err := iox.Copy(w, part.reader); err != nil {
+				return err
+			}
+		default:
+			if err := mw.WriteField(part.fieldName, part.value); err != nil {
+				return err
+			}
+		}
+	}
+	return mw.Close()
+}
+
+func writeMultipartFile(mw *multipart.Writer, part multipartPart) error {
+	file, err := os.Open(part.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	contentType, err := detectFileContentType(file)
+	if err != nil {
+		return err
+	}
+	w, err := mw.CreatePart(multipartFileHeader(part.fieldName, part.fileName, contentType))
+	if err != nil {
+		return err
+	}
+	_, err = iox.Copy(w, file)
+	return err
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func multipartFileHeader(fieldName, fileName, contentType string) textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(fieldName), quoteEscaper.Replace(fileName)))
+	h.Set("Content-Type", contentType)
+	return h
+}
+
+// SetMultipartBody 设置 multipart/form-data Body, 以流式方式边编码边发送.
+// 文件在构建请求时才打开; 不含 io.Reader 字段时重试可以重放请求体
+func (rb *RequestBuilder) SetMultipartBody(mb *MultipartBuilder) (*RequestBuilder, error) {
+	if mb.err != nil {
+		return nil, mb.err
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	// 构建请求时才启动编码协程并打开文件, 未执行的 RequestBuilder 不占用资源
+	newBody := func() (io.ReadCloser, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			mw := multipart.NewWriter(pw)
+			_ = mw.SetBoundary(boundary)
+			pw.CloseWithError(mb.writeTo(mw))
+		}()
+		return pr, nil
+	}
+
+	rb.setBody(nil)
+	if mb.replayable() {
+		rb.getBody = newBody
+	} else {
+		rb.openBody = newBody
+	}
+	rb.header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+	return rb, nil
+}
@@ -0,0 +1,202 @@
+package httpc
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestBuilderSetFileBodySetsLengthAndContentType(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "payload.json")
+	if err := os.WriteFile(jsonPath, []byte(`{"a":1}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	pngPath := filepath.Join(dir, "image")
+	if err := os.WriteFile(pngPath, []byte("\x89PNG\r\n\x1a\n0000"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+	}{
+		{path: jsonPath, contentType: "application/json", body: `{"a":1}`},
+		{path: pngPath, contentType: "image/png", body: "\x89PNG\r\n\x1a\n0000"},
+	}
+	for _, tt := range tests {
+		builder, err := New().PUT("https://example.com/upload").SetFileBody(tt.path)
+		if err != nil {
+			t.Fatalf("SetFileBody(%q) error = %v", tt.path, err)
+		}
+		req, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		if got := req.Header.Get("Content-Type"); got != tt.contentType {
+			t.Fatalf("Content-Type = %q, want %q", got, tt.contentType)
+		}
+		if req.ContentLength != int64(len(tt.body)) {
+			t.Fatalf("ContentLength = %d, want %d", req.ContentLength, len(tt.body))
+		}
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil || string(body) != tt.body {
+			t.Fatalf("body = %q, %v; want %q", body, err, tt.body)
+		}
+		if req.GetBody == nil {
+			t.Fatal("GetBody = nil, want replayable file body")
+		}
+	}
+}
+
+func TestRequestBuilderSetMultipartBodyWithFileField(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(filePath, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+			return
+		}
+		if got := r.FormValue("title"); got != "demo" {
+			t.Errorf("title = %q, want demo", got)
+		}
+		file, header, err := r.FormFile("attachment")
+		if err != nil {
+			t.Errorf("FormFile() error = %v", err)
+			return
+		}
+		defer file.Close()
+		data, _ := io.ReadAll(file)
+		if header.Filename != "notes.txt" || string(data) != "hello" {
+			t.Errorf("file = %q (%q), want notes.txt (hello)", header.Filename, data)
+		}
+		if got := header.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+			t.Errorf("part Content-Type = %q, want text/plain", got)
+		}
+	}))
+	defer server.Close()
+
+	form := NewMultipartBuilder().
+		AddField("title", "demo").
+		AddFileField("attachment", filePath)
+	builder, err := New().POST(server.URL).SetMultipartBody(form)
+	if err != nil {
+		t.Fatalf("SetMultipartBody() error = %v", err)
+	}
+	resp, err := builder.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := New().POST(server.URL).SetMultipartBody(NewMultipartBuilder().AddFileField("f", filepath.Join(dir, "missing"))); err == nil {
+		t.Fatal("SetMultipartBody() error = nil, want missing file error")
+	}
+}
+
+func TestRequestBuilderSetFileBodyOpensLazily(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(filePath, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	builder, err := New().PUT("https://example.com/upload").SetFileBody(filePath)
+	if err != nil {
+		t.Fatalf("SetFileBody() error = %v", err)
+	}
+
+	// 在 SetFileBody 之后删除文件: 文件未被提前打开, 构建请求时才报错
+	if err := os.Remove(filePath); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := builder.Build(); err == nil {
+		t.Fatal("Build() error = nil, want error for removed file")
+	}
+}
+
+func TestRequestBuilderSetMultipartBodyOpensLazily(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(filePath, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	builder, err := New().POST("https://example.com/upload").SetMultipartBody(NewMultipartBuilder().AddFileField("f", filePath))
+	if err != nil {
+		t.Fatalf("SetMultipartBody() error = %v", err)
+	}
+	if builder.body != nil {
+		t.Fatal("SetMultipartBody() opened the body before Build")
+	}
+
+	// 构建时读取最新的文件内容, 每次构建都得到完整的请求体
+	if err := os.WriteFile(filePath, []byte("v2"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	for range 2 {
+		req, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if !strings.Contains(string(data), "v2") || req.GetBody == nil {
+			t.Fatalf("body = %q, GetBody set = %v, want replayable body with v2", data, req.GetBody != nil)
+		}
+	}
+
+	readerForm := NewMultipartBuilder().AddFile("r", "r.txt", strings.NewReader("once"))
+	builder, err = New().POST("https://example.com/upload").SetMultipartBody(readerForm)
+	if err != nil {
+		t.Fatalf("SetMultipartBody() error = %v", err)
+	}
+	req, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	req.Body.Close()
+	if req.GetBody != nil {
+		t.Fatal("GetBody set for a body with io.Reader fields")
+	}
+}
+
+func TestRequestBuilderSetFileBodyEmptyFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(filePath, nil, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 0 || len(r.TransferEncoding) != 0 {
+			t.Errorf("ContentLength = %d, TransferEncoding = %v, want 0 and none", r.ContentLength, r.TransferEncoding)
+		}
+	}))
+	defer server.Close()
+
+	builder, err := New().PUT(server.URL).SetFileBody(filePath)
+	if err != nil {
+		t.Fatalf("SetFileBody() error = %v", err)
+	}
+	req, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if req.Body != http.NoBody || req.ContentLength != 0 {
+		t.Fatalf("Body = %v, ContentLength = %d, want http.NoBody and 0", req.Body, req.ContentLength)
+	}
+	resp, err := builder.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	resp.Body.Close()
+}